@@ -7,6 +7,7 @@ import (
 	"sync"
 
 	"github.com/Masterminds/semver/v3"
+	"github.com/lkpsg/ccscanner/internal/identity"
 	"github.com/your-org/ccscanner/pkg/models"
 )
 
@@ -103,10 +104,13 @@ func (a *DependencyAnalyzer) Analyze(deps []*models.Dependency) (*AnalysisResult
 // buildDependencyGraph 构建依赖关系图
 func (a *DependencyAnalyzer) buildDependencyGraph() error {
 	for _, dep := range a.dependencies {
+		// 按规范ID建立节点, 同一个库的不同名称(如 ZLIB、z、zlib)合并为一个节点
+		key := dependencyKey(dep)
+
 		// 添加节点
 		a.graph.mu.Lock()
-		if _, exists := a.graph.nodes[dep.Name]; !exists {
-			a.graph.nodes[dep.Name] = &Node{
+		if _, exists := a.graph.nodes[key]; !exists {
+			a.graph.nodes[key] = &Node{
 				Name:     dep.Name,
 				Versions: []string{dep.Version},
 				Type:     dep.Type,
@@ -116,17 +120,17 @@ func (a *DependencyAnalyzer) buildDependencyGraph() error {
 			}
 		} else {
 			// 合并版本信息
-			versions := a.graph.nodes[dep.Name].Versions
+			versions := a.graph.nodes[key].Versions
 			if !contains(versions, dep.Version) {
 				versions = append(versions, dep.Version)
 			}
-			a.graph.nodes[dep.Name].Versions = versions
+			a.graph.nodes[key].Versions = versions
 		}
 
 		// 添加边
 		if dep.Dependencies != nil {
 			for _, childDep := range dep.Dependencies {
-				a.graph.edges[dep.Name] = append(a.graph.edges[dep.Name], childDep.Name)
+				a.graph.edges[key] = append(a.graph.edges[key], childKey(dep, childDep))
 			}
 		}
 		a.graph.mu.Unlock()
@@ -193,20 +197,20 @@ func (a *DependencyAnalyzer) detectVersionConflicts() []*VersionConflict {
 
 	// 收集所有版本要求
 	for _, dep := range a.dependencies {
-		if _, exists := versionMap[dep.Name]; !exists {
-			versionMap[dep.Name] = make(map[string][]string)
+		key := dependencyKey(dep)
+		if _, exists := versionMap[key]; !exists {
+			versionMap[key] = make(map[string][]string)
 		}
-		versionMap[dep.Name][dep.Version] = append(versionMap[dep.Name][dep.Version], "root")
+		versionMap[key][dep.Version] = append(versionMap[key][dep.Version], "root")
 
 		if dep.Dependencies != nil {
 			for _, childDep := range dep.Dependencies {
-				if _, exists := versionMap[childDep.Name]; !exists {
-					versionMap[childDep.Name] = make(map[string][]string)
+				// 子依赖只列出名称, 按未指定版本记录引入方
+				child := childKey(dep, childDep)
+				if _, exists := versionMap[child]; !exists {
+					versionMap[child] = make(map[string][]string)
 				}
-				versionMap[childDep.Name][childDep.Version] = append(
-					versionMap[childDep.Name][childDep.Version],
-					dep.Name,
-				)
+				versionMap[child][""] = append(versionMap[child][""], dep.Name)
 			}
 		}
 	}
@@ -360,17 +364,34 @@ func normalizeVersion(version string) string {
 	return strings.Join(parts, ".")
 }
 
-// countDirectDependencies 计算直接依赖数量
+// countDirectDependencies 计算直接依赖数量, 以扫描时根据依赖图得出的直接/传递关系为准
 func countDirectDependencies(deps []*models.Dependency) int {
 	count := 0
 	for _, dep := range deps {
-		if dep.Scope != "indirect" {
+		if dep.IsDirect() {
 			count++
 		}
 	}
 	return count
 }
 
+// dependencyKey 返回依赖的节点键, 与合并结果一致使用 Dependency.Key, 未填充规范ID时先通过身份数据库解析
+func dependencyKey(dep *models.Dependency) string {
+	if dep.CanonicalID != "" {
+		return dep.Key()
+	}
+	resolved := *dep
+	resolved.CanonicalID = identity.Default().Resolve(&resolved)
+	return resolved.Key()
+}
+
+// childKey 返回子依赖名称对应的节点键, 子依赖与父依赖属于同一生态
+func childKey(parent *models.Dependency, name string) string {
+	child := models.Dependency{Name: name}
+	child.CanonicalID = identity.Default().ResolveName(name, identity.Ecosystem(parent))
+	return dependencyKey(&child)
+}
+
 // contains 检查切片是否包含指定值
 func contains(slice []string, item string) bool {
 	for _, s := range slice {
@@ -29,9 +29,10 @@ func TestDependencyAnalyzer_Analyze(t *testing.T) {
 			},
 		},
 		{
-			Name:    "B",
-			Version: "2.0.0",
-			Type:    "indirect",
+			Name:         "B",
+			Version:      "2.0.0",
+			Type:         "indirect",
+			Relationship: models.RelationshipTransitive,
 			Dependencies: []*models.Dependency{
 				{
 					Name:    "D",
@@ -41,9 +42,10 @@ func TestDependencyAnalyzer_Analyze(t *testing.T) {
 			},
 		},
 		{
-			Name:    "C",
-			Version: "1.0.0",
-			Type:    "indirect",
+			Name:         "C",
+			Version:      "1.0.0",
+			Type:         "indirect",
+			Relationship: models.RelationshipTransitive,
 			Dependencies: []*models.Dependency{
 				{
 					Name:    "D",
@@ -53,14 +55,16 @@ func TestDependencyAnalyzer_Analyze(t *testing.T) {
 			},
 		},
 		{
-			Name:    "D",
-			Version: "1.0.0",
-			Type:    "indirect",
+			Name:         "D",
+			Version:      "1.0.0",
+			Type:         "indirect",
+			Relationship: models.RelationshipTransitive,
 		},
 		{
-			Name:    "D",
-			Version: "2.0.0",
-			Type:    "indirect",
+			Name:         "D",
+			Version:      "2.0.0",
+			Type:         "indirect",
+			Relationship: models.RelationshipTransitive,
 		},
 	}
 
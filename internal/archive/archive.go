@@ -0,0 +1,340 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/ulikunitz/xz"
+)
+
+// Limits 读取压缩包时的资源限制, 防止解压炸弹, 为0的字段使用DefaultLimits中的值
+type Limits struct {
+	MaxFileSize  int64 // 单个文件解压后的最大字节数, 超过的文件被跳过
+	MaxTotalSize int64 // 解压后读取的总字节数(含嵌套压缩包), 超过时读取失败
+	MaxEntries   int   // 条目总数(含嵌套压缩包), 超过时读取失败
+	MaxNesting   int   // 展开嵌套压缩包的最大层数, 为负数时不展开
+}
+
+// DefaultLimits 默认资源限制
+var DefaultLimits = Limits{
+	MaxFileSize:  32 << 20,
+	MaxTotalSize: 1 << 30,
+	MaxEntries:   100000,
+	MaxNesting:   2,
+}
+
+// ErrLimitExceeded 压缩包超出总大小或条目数限制
+var ErrLimitExceeded = errors.New("archive limit exceeded")
+
+// 支持的压缩包格式
+const (
+	formatTar   = "tar"
+	formatTarGz = "tar.gz"
+	formatTarXz = "tar.xz"
+	formatZip   = "zip"
+)
+
+// archiveSuffixes 文件名后缀 → 压缩包格式
+var archiveSuffixes = []struct {
+	suffix string
+	format string
+}{
+	{".tar.gz", formatTarGz},
+	{".tgz", formatTarGz},
+	{".tar.xz", formatTarXz},
+	{".txz", formatTarXz},
+	{".tar", formatTar},
+	{".zip", formatZip},
+}
+
+// IsArchive 根据文件名判断是否为支持的压缩包(tar、tar.gz、tar.xz、zip)
+func IsArchive(name string) bool {
+	return formatOf(name) != ""
+}
+
+// formatOf 根据文件名后缀返回压缩包格式, 不支持时返回空字符串
+func formatOf(name string) string {
+	lower := strings.ToLower(name)
+	for _, s := range archiveSuffixes {
+		if strings.HasSuffix(lower, s.suffix) {
+			return s.format
+		}
+	}
+	return ""
+}
+
+// Open 读取压缩包, 将其中的普通文件载入内存构成只读文件系统, 不解压到磁盘
+//
+// 绝对路径、包含..的路径以及符号链接、硬链接、设备文件等条目被跳过并记录在 FS.Skipped 中,
+// 超过 MaxFileSize 的文件同样被跳过; 解压总量或条目数超过限制时返回 ErrLimitExceeded。
+// MaxNesting 层以内的嵌套压缩包被展开为与其同名的目录。
+func Open(name string, limits Limits) (*FS, error) {
+	format := formatOf(name)
+	if format == "" {
+		return nil, fmt.Errorf("unsupported archive format: %s", name)
+	}
+
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	fsys := newFS(name)
+	r := &reader{limits: limits.withDefaults()}
+	if err := r.read(fsys, format, file, info.Size(), 0); err != nil {
+		return nil, fmt.Errorf("failed to read archive %s: %w", name, err)
+	}
+	return fsys, nil
+}
+
+// withDefaults 用默认值补全未设置的限制
+func (l Limits) withDefaults() Limits {
+	if l.MaxFileSize <= 0 {
+		l.MaxFileSize = DefaultLimits.MaxFileSize
+	}
+	if l.MaxTotalSize <= 0 {
+		l.MaxTotalSize = DefaultLimits.MaxTotalSize
+	}
+	if l.MaxEntries <= 0 {
+		l.MaxEntries = DefaultLimits.MaxEntries
+	}
+	if l.MaxNesting == 0 {
+		l.MaxNesting = DefaultLimits.MaxNesting
+	}
+	return l
+}
+
+// reader 读取一个压缩包及其嵌套压缩包, 所有层级共享同一份资源限制
+type reader struct {
+	limits  Limits
+	total   int64 // 已解压读取的字节数
+	entries int   // 已读取的条目数
+}
+
+// read 读取format格式的压缩包, 条目添加到fsys中, depth为嵌套层数
+func (r *reader) read(fsys *FS, format string, ra io.ReaderAt, size int64, depth int) error {
+	if format == formatZip {
+		return r.readZip(fsys, ra, size, depth)
+	}
+
+	var src io.Reader = io.NewSectionReader(ra, 0, size)
+	switch format {
+	case formatTarGz:
+		gz, err := gzip.NewReader(src)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		src = gz
+	case formatTarXz:
+		xzr, err := xz.NewReader(src)
+		if err != nil {
+			return err
+		}
+		src = xzr
+	}
+	// 统计解压后的字节数, 跳过的条目同样计入
+	return r.readTar(fsys, &countingReader{r: src, reader: r}, depth)
+}
+
+// readTar 读取tar条目
+func (r *reader) readTar(fsys *FS, src io.Reader, depth int) error {
+	tr := tar.NewReader(src)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		// 设置GODEBUG=tarinsecurepath=0时不安全的路径返回ErrInsecurePath, 由cleanName统一处理
+		if err != nil && !errors.Is(err, tar.ErrInsecurePath) {
+			return err
+		}
+		if err := r.countEntry(); err != nil {
+			return err
+		}
+
+		name, ok := cleanName(hdr.Name)
+		switch {
+		case !ok:
+			fsys.skip(hdr.Name, "unsafe path")
+		case name == ".":
+			// 根目录
+		case hdr.Typeflag == tar.TypeDir:
+			fsys.mkdir(name, hdr.ModTime)
+		case hdr.Typeflag != tar.TypeReg:
+			fsys.skip(name, fmt.Sprintf("unsupported entry type %q", hdr.Typeflag))
+		case hdr.Size > r.limits.MaxFileSize:
+			fsys.skip(name, "file too large")
+		default:
+			data, err := io.ReadAll(tr)
+			if err != nil {
+				return err
+			}
+			if err := r.addFile(fsys, name, data, hdr.FileInfo().Mode(), hdr.ModTime, depth); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// readZip 读取zip条目, 条目数据逐个解压并计入总量
+func (r *reader) readZip(fsys *FS, ra io.ReaderAt, size int64, depth int) error {
+	zr, err := zip.NewReader(ra, size)
+	if err != nil && !errors.Is(err, zip.ErrInsecurePath) {
+		return err
+	}
+	for _, f := range zr.File {
+		if err := r.countEntry(); err != nil {
+			return err
+		}
+
+		name, ok := cleanName(f.Name)
+		mode := f.Mode()
+		switch {
+		case !ok:
+			fsys.skip(f.Name, "unsafe path")
+		case name == ".":
+			// 根目录
+		case mode.IsDir():
+			fsys.mkdir(name, f.Modified)
+		case !mode.IsRegular():
+			fsys.skip(name, fmt.Sprintf("unsupported file mode %s", mode))
+		case f.UncompressedSize64 > uint64(r.limits.MaxFileSize):
+			fsys.skip(name, "file too large")
+		default:
+			data, err := r.readZipFile(f)
+			if err != nil {
+				return err
+			}
+			if data == nil {
+				// 头部记录的大小不可信, 以实际解压的字节数为准
+				fsys.skip(name, "file too large")
+				continue
+			}
+			if err := r.addFile(fsys, name, data, mode, f.Modified, depth); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// readZipFile 解压zip条目, 超过单文件大小限制时返回nil
+func (r *reader) readZipFile(f *zip.File) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	src := &countingReader{r: io.LimitReader(rc, r.limits.MaxFileSize+1), reader: r}
+	data, err := io.ReadAll(src)
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > r.limits.MaxFileSize {
+		return nil, nil
+	}
+	if data == nil {
+		data = []byte{}
+	}
+	return data, nil
+}
+
+// addFile 添加普通文件, 限制层数以内的嵌套压缩包展开为目录
+func (r *reader) addFile(fsys *FS, name string, data []byte, mode fs.FileMode, modTime time.Time, depth int) error {
+	format := formatOf(name)
+	if format == "" || depth >= r.limits.MaxNesting {
+		fsys.addFile(name, data, mode, modTime)
+		return nil
+	}
+
+	nested := newFS(name)
+	if err := r.read(nested, format, bytes.NewReader(data), int64(len(data)), depth+1); err != nil {
+		if errors.Is(err, ErrLimitExceeded) {
+			return err
+		}
+		// 损坏的嵌套压缩包按普通文件保留
+		fsys.skip(name, fmt.Sprintf("invalid nested archive: %v", err))
+		fsys.addFile(name, data, mode, modTime)
+		return nil
+	}
+	fsys.mount(name, nested, modTime)
+	return nil
+}
+
+// countEntry 条目计数, 超过限制时返回错误
+func (r *reader) countEntry() error {
+	r.entries++
+	if r.entries > r.limits.MaxEntries {
+		return fmt.Errorf("%w: more than %d entries", ErrLimitExceeded, r.limits.MaxEntries)
+	}
+	return nil
+}
+
+// countingReader 统计解压后读取的字节数, 超过总量限制时返回错误
+type countingReader struct {
+	r      io.Reader
+	reader *reader
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.reader.total += int64(n)
+	if c.reader.total > c.reader.limits.MaxTotalSize {
+		return n, fmt.Errorf("%w: more than %d bytes decompressed", ErrLimitExceeded, c.reader.limits.MaxTotalSize)
+	}
+	return n, err
+}
+
+// cleanName 规范化条目路径(以/分隔、无前导./), 绝对路径和包含..的路径返回false
+func cleanName(name string) (string, bool) {
+	// Windows 工具生成的zip可能使用\分隔
+	name = strings.ReplaceAll(name, `\`, "/")
+	if strings.HasPrefix(name, "/") || (len(name) >= 2 && name[1] == ':') {
+		return "", false
+	}
+	for _, elem := range strings.Split(name, "/") {
+		if elem == ".." {
+			return "", false
+		}
+	}
+	name = path.Clean(name)
+	if name != "." && !fs.ValidPath(name) {
+		return "", false
+	}
+	return name, true
+}
+
+/*
+使用示例:
+
+fsys, err := archive.Open("/drops/vendor-src.tar.gz", archive.DefaultLimits)
+if err != nil {
+	log.Fatal(err)
+}
+for _, s := range fsys.Skipped {
+	fmt.Println("skipped", s.Name, s.Reason)
+}
+data, err := fs.ReadFile(fsys, "vendor-src/vcpkg.json")
+if err != nil {
+	log.Fatal(err)
+}
+fmt.Println(fsys.DisplayPath("vendor-src/vcpkg.json"), len(data))
+*/
@@ -0,0 +1,265 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ulikunitz/xz"
+)
+
+// entry 测试压缩包中的条目
+type entry struct {
+	name     string
+	body     string
+	typeflag byte // tar条目类型, 默认为普通文件
+	mode     fs.FileMode
+}
+
+func buildTar(t *testing.T, entries []entry) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, e := range entries {
+		hdr := &tar.Header{Name: e.name, Mode: 0o644, Size: int64(len(e.body)), Typeflag: e.typeflag, ModTime: time.Unix(1700000000, 0)}
+		switch e.typeflag {
+		case 0:
+			hdr.Typeflag = tar.TypeReg
+		case tar.TypeSymlink, tar.TypeLink:
+			hdr.Linkname, hdr.Size = e.body, 0
+		case tar.TypeDir:
+			hdr.Mode, hdr.Size = 0o755, 0
+		}
+		require.NoError(t, tw.WriteHeader(hdr))
+		if hdr.Size > 0 {
+			_, err := tw.Write([]byte(e.body))
+			require.NoError(t, err)
+		}
+	}
+	require.NoError(t, tw.Close())
+	return buf.Bytes()
+}
+
+func buildZip(t *testing.T, entries []entry) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, e := range entries {
+		hdr := &zip.FileHeader{Name: e.name, Method: zip.Deflate}
+		mode := e.mode
+		if mode == 0 {
+			mode = 0o644
+		}
+		hdr.SetMode(mode)
+		w, err := zw.CreateHeader(hdr)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(e.body))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+func gzipData(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	_, err := gw.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, gw.Close())
+	return buf.Bytes()
+}
+
+func xzData(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	xw, err := xz.NewWriter(&buf)
+	require.NoError(t, err)
+	_, err = xw.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, xw.Close())
+	return buf.Bytes()
+}
+
+func writeArchive(t *testing.T, name string, data []byte) string {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(file, data, 0o644))
+	return file
+}
+
+func skippedNames(fsys *FS) []string {
+	var names []string
+	for _, s := range fsys.Skipped {
+		names = append(names, s.Name)
+	}
+	return names
+}
+
+func TestIsArchive(t *testing.T) {
+	for _, name := range []string{"a.tar", "a.tar.gz", "A.TGZ", "a.tar.xz", "a.txz", "dir/a.zip"} {
+		assert.True(t, IsArchive(name), name)
+	}
+	for _, name := range []string{"a.gz", "a.xz", "a.tar.bz2", "zip", "vcpkg.json"} {
+		assert.False(t, IsArchive(name), name)
+	}
+}
+
+func TestOpen(t *testing.T) {
+	entries := []entry{
+		{name: "./pkg/", typeflag: tar.TypeDir},
+		{name: "./pkg/vcpkg.json", body: `{"name": "app"}`},
+		{name: "pkg/src/conanfile.txt", body: "[requires]\nzlib/1.3\n"},
+		{name: "README", body: ""},
+	}
+	tarData := buildTar(t, entries)
+	tests := map[string][]byte{
+		"src.tar":    tarData,
+		"src.tar.gz": gzipData(t, tarData),
+		"src.tgz":    gzipData(t, tarData),
+		"src.tar.xz": xzData(t, tarData),
+		"src.zip": buildZip(t, []entry{
+			{name: "pkg/", mode: fs.ModeDir | 0o755},
+			{name: "pkg/vcpkg.json", body: `{"name": "app"}`},
+			{name: `pkg\src\conanfile.txt`, body: "[requires]\nzlib/1.3\n"},
+			{name: "README", body: ""},
+		}),
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			file := writeArchive(t, name, data)
+			fsys, err := Open(file, DefaultLimits)
+			require.NoError(t, err)
+			assert.Equal(t, file, fsys.Name())
+			assert.Empty(t, fsys.Skipped)
+
+			content, err := fs.ReadFile(fsys, "pkg/vcpkg.json")
+			require.NoError(t, err)
+			assert.Equal(t, `{"name": "app"}`, string(content))
+			content, err = fs.ReadFile(fsys, "pkg/src/conanfile.txt")
+			require.NoError(t, err)
+			assert.Equal(t, "[requires]\nzlib/1.3\n", string(content))
+		})
+	}
+
+	_, err := Open(writeArchive(t, "src.rar", []byte("x")), DefaultLimits)
+	assert.ErrorContains(t, err, "unsupported archive format")
+	_, err = Open(writeArchive(t, "broken.tar.gz", []byte("not gzip")), DefaultLimits)
+	assert.Error(t, err)
+}
+
+func TestOpen_UnsafeEntries(t *testing.T) {
+	tarFile := writeArchive(t, "evil.tar", buildTar(t, []entry{
+		{name: "../escape.txt", body: "x"},
+		{name: "/etc/cron.d/evil", body: "x"},
+		{name: "a/../../escape.txt", body: "x"},
+		{name: "link", body: "/etc/passwd", typeflag: tar.TypeSymlink},
+		{name: "hard", body: "safe.txt", typeflag: tar.TypeLink},
+		{name: "fifo", typeflag: tar.TypeFifo},
+		{name: "safe.txt", body: "ok"},
+	}))
+	fsys, err := Open(tarFile, DefaultLimits)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"../escape.txt", "/etc/cron.d/evil", "a/../../escape.txt", "link", "hard", "fifo"}, skippedNames(fsys))
+
+	entries, err := fs.ReadDir(fsys, ".")
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "safe.txt", entries[0].Name())
+
+	zipFile := writeArchive(t, "evil.zip", buildZip(t, []entry{
+		{name: `..\escape.txt`, body: "x"},
+		{name: "C:/Windows/evil.dll", body: "x"},
+		{name: "link", body: "/etc/passwd", mode: fs.ModeSymlink | 0o777},
+		{name: "safe.txt", body: "ok"},
+	}))
+	fsys, err = Open(zipFile, DefaultLimits)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{`..\escape.txt`, "C:/Windows/evil.dll", "link"}, skippedNames(fsys))
+	_, err = fs.Stat(fsys, "safe.txt")
+	assert.NoError(t, err)
+}
+
+func TestOpen_Limits(t *testing.T) {
+	big := string(bytes.Repeat([]byte("0"), 64<<10))
+	tarData := buildTar(t, []entry{
+		{name: "big.bin", body: big},
+		{name: "small.txt", body: "ok"},
+	})
+
+	// 超过单文件大小的文件被跳过
+	limits := Limits{MaxFileSize: 1 << 10}
+	for name, data := range map[string][]byte{
+		"bomb.tar.gz": gzipData(t, tarData),
+		"bomb.zip":    buildZip(t, []entry{{name: "big.bin", body: big}, {name: "small.txt", body: "ok"}}),
+	} {
+		fsys, err := Open(writeArchive(t, name, data), limits)
+		require.NoError(t, err, name)
+		assert.Equal(t, []Skipped{{Name: "big.bin", Reason: "file too large"}}, fsys.Skipped, name)
+		_, err = fs.Stat(fsys, "small.txt")
+		assert.NoError(t, err, name)
+	}
+
+	// 解压总量超过限制时读取失败, 跳过的条目同样计入
+	_, err := Open(writeArchive(t, "bomb.tar.gz", gzipData(t, tarData)), Limits{MaxFileSize: 1 << 10, MaxTotalSize: 32 << 10})
+	assert.True(t, errors.Is(err, ErrLimitExceeded), "%v", err)
+
+	_, err = Open(writeArchive(t, "many.tar", tarData), Limits{MaxEntries: 1})
+	assert.True(t, errors.Is(err, ErrLimitExceeded), "%v", err)
+}
+
+func TestOpen_Nested(t *testing.T) {
+	inner := buildTar(t, []entry{{name: "conanfile.txt", body: "[requires]\nzlib/1.3\n"}})
+	sub := buildZip(t, []entry{
+		{name: "vcpkg.json", body: `{"name": "sub"}`},
+		{name: "inner.tar", body: string(inner)},
+		{name: "../escape.txt", body: "x"},
+	})
+	outer := gzipData(t, buildTar(t, []entry{
+		{name: "deps/sub.zip", body: string(sub)},
+		{name: "deps/bad.zip", body: "not a zip"},
+	}))
+	file := writeArchive(t, "vendor.tar.gz", outer)
+
+	fsys, err := Open(file, DefaultLimits)
+	require.NoError(t, err)
+	info, err := fs.Stat(fsys, "deps/sub.zip")
+	require.NoError(t, err)
+	assert.True(t, info.IsDir())
+	content, err := fs.ReadFile(fsys, "deps/sub.zip/inner.tar/conanfile.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "[requires]\nzlib/1.3\n", string(content))
+
+	// 损坏的嵌套压缩包按普通文件保留
+	content, err = fs.ReadFile(fsys, "deps/bad.zip")
+	require.NoError(t, err)
+	assert.Equal(t, "not a zip", string(content))
+	assert.ElementsMatch(t, []string{"deps/sub.zip/../escape.txt", "deps/bad.zip"}, skippedNames(fsys))
+
+	assert.Equal(t, file+"!/deps/sub.zip!/inner.tar!/conanfile.txt", fsys.DisplayPath("deps/sub.zip/inner.tar/conanfile.txt"))
+	assert.Equal(t, file+"!/deps/sub.zip!/vcpkg.json", fsys.DisplayPath("deps/sub.zip/vcpkg.json"))
+	assert.Equal(t, file+"!/deps/bad.zip", fsys.DisplayPath("deps/bad.zip"))
+
+	// 超过嵌套层数的压缩包不展开
+	fsys, err = Open(file, Limits{MaxNesting: 1})
+	require.NoError(t, err)
+	info, err = fs.Stat(fsys, "deps/sub.zip/inner.tar")
+	require.NoError(t, err)
+	assert.False(t, info.IsDir())
+
+	fsys, err = Open(file, Limits{MaxNesting: -1})
+	require.NoError(t, err)
+	content, err = fs.ReadFile(fsys, "deps/sub.zip")
+	require.NoError(t, err)
+	assert.Equal(t, sub, content)
+}
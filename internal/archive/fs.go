@@ -0,0 +1,307 @@
+package archive
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// errIsDir 对目录执行文件操作
+var errIsDir = errors.New("is a directory")
+
+// FS 压缩包内容构成的内存只读文件系统, 实现 fs.ReadDirFS、fs.ReadFileFS 和 fs.StatFS
+type FS struct {
+	Skipped []Skipped // 读取时被跳过的条目
+
+	name   string   // 压缩包路径
+	root   *node    // 根目录
+	mounts []string // 展开为目录的嵌套压缩包路径, 按路径排序
+}
+
+// Skipped 读取时被跳过的条目
+type Skipped struct {
+	Name   string `json:"name"`   // 条目在压缩包中的原始路径(嵌套压缩包中的条目以其挂载目录为前缀)
+	Reason string `json:"reason"` // 跳过原因
+}
+
+// node 文件或目录
+type node struct {
+	info     fileInfo
+	data     []byte
+	children map[string]*node // 目录的子条目
+}
+
+// newFS 创建只有根目录的文件系统
+func newFS(name string) *FS {
+	return &FS{name: name, root: newDir(".", time.Time{})}
+}
+
+// newDir 创建目录节点
+func newDir(name string, modTime time.Time) *node {
+	return &node{
+		info:     fileInfo{name: name, mode: fs.ModeDir | 0o555, modTime: modTime},
+		children: make(map[string]*node),
+	}
+}
+
+// Name 返回压缩包路径
+func (f *FS) Name() string {
+	return f.name
+}
+
+// DisplayPath 返回条目在压缩包中的位置, 嵌套压缩包之间以!/分隔
+// 如 deps/sub.zip/vcpkg.json 表示为 vendor.tar.gz!/deps/sub.zip!/vcpkg.json, 根目录表示为压缩包路径
+func (f *FS) DisplayPath(name string) string {
+	if name == "." {
+		return f.name
+	}
+	var b strings.Builder
+	b.WriteString(f.name)
+	start := 0
+	for _, mount := range f.mounts {
+		if strings.HasPrefix(name, mount+"/") {
+			b.WriteString("!/")
+			b.WriteString(name[start:len(mount)])
+			start = len(mount) + 1
+		}
+	}
+	b.WriteString("!/")
+	b.WriteString(name[start:])
+	return b.String()
+}
+
+// Open 实现 fs.FS
+func (f *FS) Open(name string) (fs.File, error) {
+	n, err := f.find("open", name)
+	if err != nil {
+		return nil, err
+	}
+	if n.info.IsDir() {
+		return &openDir{path: name, node: n, entries: n.entries()}, nil
+	}
+	return &openFile{Reader: bytes.NewReader(n.data), node: n}, nil
+}
+
+// ReadFile 实现 fs.ReadFileFS
+func (f *FS) ReadFile(name string) ([]byte, error) {
+	n, err := f.find("read", name)
+	if err != nil {
+		return nil, err
+	}
+	if n.info.IsDir() {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: errIsDir}
+	}
+	return append([]byte(nil), n.data...), nil
+}
+
+// Stat 实现 fs.StatFS
+func (f *FS) Stat(name string) (fs.FileInfo, error) {
+	n, err := f.find("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	return n.info, nil
+}
+
+// ReadDir 实现 fs.ReadDirFS, 条目按名称排序
+func (f *FS) ReadDir(name string) ([]fs.DirEntry, error) {
+	n, err := f.find("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+	if !n.info.IsDir() {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: errors.New("not a directory")}
+	}
+	return n.entries(), nil
+}
+
+// find 查找路径对应的节点
+func (f *FS) find(op, name string) (*node, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	n := f.root
+	if name == "." {
+		return n, nil
+	}
+	for _, elem := range strings.Split(name, "/") {
+		if n.children == nil {
+			return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+		}
+		if n = n.children[elem]; n == nil {
+			return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+		}
+	}
+	return n, nil
+}
+
+// mkdirAll 创建目录及其上级目录, 路径上存在同名文件时返回nil
+func (f *FS) mkdirAll(name string) *node {
+	n := f.root
+	if name == "." {
+		return n
+	}
+	for _, elem := range strings.Split(name, "/") {
+		child := n.children[elem]
+		if child == nil {
+			child = newDir(elem, time.Time{})
+			n.children[elem] = child
+		}
+		if !child.info.IsDir() {
+			return nil
+		}
+		n = child
+	}
+	return n
+}
+
+// mkdir 添加目录条目
+func (f *FS) mkdir(name string, modTime time.Time) {
+	dir := f.mkdirAll(name)
+	if dir == nil {
+		f.skip(name, "conflicts with a file")
+		return
+	}
+	dir.info.modTime = modTime
+}
+
+// addFile 添加普通文件, 同名文件以后出现的条目为准
+func (f *FS) addFile(name string, data []byte, mode fs.FileMode, modTime time.Time) {
+	f.add(name, &node{
+		info: fileInfo{name: path.Base(name), size: int64(len(data)), mode: mode.Perm(), modTime: modTime},
+		data: data,
+	})
+}
+
+// mount 将嵌套压缩包的内容挂载为name目录
+func (f *FS) mount(name string, nested *FS, modTime time.Time) {
+	root := nested.root
+	root.info.name = path.Base(name)
+	root.info.modTime = modTime
+	if !f.add(name, root) {
+		return
+	}
+
+	f.mounts = append(f.mounts, name)
+	for _, mount := range nested.mounts {
+		f.mounts = append(f.mounts, name+"/"+mount)
+	}
+	sort.Strings(f.mounts)
+	for _, s := range nested.Skipped {
+		f.skip(name+"/"+s.Name, s.Reason)
+	}
+}
+
+// add 将节点添加到name处, 与已有目录或上级路径中的文件冲突时跳过
+func (f *FS) add(name string, n *node) bool {
+	parent := f.mkdirAll(path.Dir(name))
+	if parent == nil {
+		f.skip(name, "conflicts with a file")
+		return false
+	}
+	base := path.Base(name)
+	if existing := parent.children[base]; existing != nil && existing.info.IsDir() {
+		f.skip(name, "conflicts with a directory")
+		return false
+	}
+	parent.children[base] = n
+	return true
+}
+
+// skip 记录被跳过的条目
+func (f *FS) skip(name, reason string) {
+	f.Skipped = append(f.Skipped, Skipped{Name: name, Reason: reason})
+}
+
+// entries 返回目录中按名称排序的条目
+func (n *node) entries() []fs.DirEntry {
+	names := make([]string, 0, len(n.children))
+	for name := range n.children {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	entries := make([]fs.DirEntry, 0, len(names))
+	for _, name := range names {
+		entries = append(entries, n.children[name].info)
+	}
+	return entries
+}
+
+// fileInfo 条目信息, 同时实现 fs.FileInfo 和 fs.DirEntry
+type fileInfo struct {
+	name    string
+	size    int64
+	mode    fs.FileMode
+	modTime time.Time
+}
+
+func (i fileInfo) Name() string               { return i.name }
+func (i fileInfo) Size() int64                { return i.size }
+func (i fileInfo) Mode() fs.FileMode          { return i.mode }
+func (i fileInfo) ModTime() time.Time         { return i.modTime }
+func (i fileInfo) IsDir() bool                { return i.mode.IsDir() }
+func (i fileInfo) Sys() interface{}           { return nil }
+func (i fileInfo) Type() fs.FileMode          { return i.mode.Type() }
+func (i fileInfo) Info() (fs.FileInfo, error) { return i, nil }
+
+// openFile 打开的文件
+type openFile struct {
+	*bytes.Reader
+	node *node
+}
+
+func (f *openFile) Stat() (fs.FileInfo, error) { return f.node.info, nil }
+func (f *openFile) Close() error               { return nil }
+
+// openDir 打开的目录
+type openDir struct {
+	path    string
+	node    *node
+	entries []fs.DirEntry
+	offset  int
+}
+
+func (d *openDir) Stat() (fs.FileInfo, error) { return d.node.info, nil }
+func (d *openDir) Close() error               { return nil }
+
+func (d *openDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.path, Err: errIsDir}
+}
+
+// ReadDir 实现 fs.ReadDirFile
+func (d *openDir) ReadDir(count int) ([]fs.DirEntry, error) {
+	rest := d.entries[d.offset:]
+	if count <= 0 {
+		d.offset = len(d.entries)
+		return rest, nil
+	}
+	if len(rest) == 0 {
+		return nil, io.EOF
+	}
+	if count > len(rest) {
+		count = len(rest)
+	}
+	d.offset += count
+	return rest[:count], nil
+}
+
+/*
+使用示例:
+
+fsys, err := archive.Open("vendor.tar.gz", archive.Limits{MaxNesting: 1})
+if err != nil {
+	log.Fatal(err)
+}
+fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+	if err == nil && !d.IsDir() {
+		fmt.Println(fsys.DisplayPath(name)) // vendor.tar.gz!/deps/sub.zip!/vcpkg.json
+	}
+	return err
+})
+*/
@@ -0,0 +1,64 @@
+package archive
+
+import (
+	"errors"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestFS() *FS {
+	fsys := newFS("vendor.tar.gz")
+	fsys.addFile("src/vcpkg.json", []byte(`{"name": "app"}`), 0o644, time.Time{})
+	fsys.mkdir("src/empty", time.Time{})
+	fsys.addFile("README", []byte("readme"), 0o644, time.Time{})
+
+	nested := newFS("deps/sub.zip")
+	nested.addFile("conanfile.txt", []byte("[requires]\nzlib/1.3\n"), 0o644, time.Time{})
+	nested.skip("../escape.txt", "unsafe path")
+	fsys.mount("deps/sub.zip", nested, time.Time{})
+	return fsys
+}
+
+func TestFS(t *testing.T) {
+	fsys := newTestFS()
+	require.NoError(t, fstest.TestFS(fsys, "src/vcpkg.json", "src/empty", "README", "deps/sub.zip/conanfile.txt"))
+
+	_, err := fsys.Open("../etc/passwd")
+	assert.True(t, errors.Is(err, fs.ErrInvalid))
+	_, err = fsys.Open("src/missing.txt")
+	assert.True(t, errors.Is(err, fs.ErrNotExist))
+	_, err = fsys.Open("README/x")
+	assert.True(t, errors.Is(err, fs.ErrNotExist))
+	_, err = fsys.ReadFile("src")
+	assert.Error(t, err)
+
+	assert.Equal(t, []Skipped{{Name: "deps/sub.zip/../escape.txt", Reason: "unsafe path"}}, fsys.Skipped)
+}
+
+func TestFS_Conflicts(t *testing.T) {
+	fsys := newTestFS()
+	fsys.addFile("README/x", []byte("x"), 0o644, time.Time{})
+	fsys.addFile("src", []byte("x"), 0o644, time.Time{})
+	fsys.mkdir("README", time.Time{})
+
+	assert.Equal(t, []string{"deps/sub.zip/../escape.txt", "README/x", "src", "README"}, skippedNames(fsys))
+
+	// 同名文件以后出现的条目为准
+	fsys.addFile("README", []byte("updated"), 0o644, time.Time{})
+	content, err := fs.ReadFile(fsys, "README")
+	require.NoError(t, err)
+	assert.Equal(t, "updated", string(content))
+}
+
+func TestFS_DisplayPath(t *testing.T) {
+	fsys := newTestFS()
+	assert.Equal(t, "vendor.tar.gz!/src/vcpkg.json", fsys.DisplayPath("src/vcpkg.json"))
+	assert.Equal(t, "vendor.tar.gz!/deps/sub.zip!/conanfile.txt", fsys.DisplayPath("deps/sub.zip/conanfile.txt"))
+	assert.Equal(t, "vendor.tar.gz!/deps/sub.zip", fsys.DisplayPath("deps/sub.zip"))
+	assert.Equal(t, "vendor.tar.gz", fsys.DisplayPath("."))
+}
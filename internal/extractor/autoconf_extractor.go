@@ -3,7 +3,6 @@ package extractor
 import (
 	"bufio"
 	"fmt"
-	"os"
 	"regexp"
 	"strings"
 
@@ -27,7 +26,7 @@ func NewAutoconfExtractor(path string) *AutoconfExtractor {
 // Extract 提取Autoconf依赖
 func (e *AutoconfExtractor) Extract() ([]models.Dependency, error) {
 	// 读取configure.ac或configure.in文件
-	file, err := os.Open(e.FilePath)
+	file, err := e.open(e.FilePath)
 	if err != nil {
 		return nil, NewExtractorError(AutoconfExtractorType, e.FilePath, err.Error())
 	}
@@ -182,6 +181,7 @@ func (e *AutoconfExtractor) Extract() ([]models.Dependency, error) {
 		return nil, NewExtractorError(AutoconfExtractorType, e.FilePath, err.Error())
 	}
 
+	e.annotate(AutoconfExtractorType, deps)
 	return deps, nil
 }
 
@@ -58,10 +58,9 @@ func (e *BazelExtractor) Extract(projectPath string, filePath string) ([]models.
 		// 提取 http_archive 依赖
 		if matches := httpArchiveRegex.FindStringSubmatch(line); len(matches) > 1 {
 			dep := models.Dependency{
-				Name:     matches[1],
-				Type:     "bazel_http_archive",
-				FilePath: filePath,
-				Line:     lineNum,
+				Name:       matches[1],
+				Type:       "bazel_http_archive",
+				Provenance: lineProvenance(BazelExtractorType, filePath, lineNum, line, nil, "bazel.http_archive"),
 			}
 			dependencies = append(dependencies, dep)
 		}
@@ -69,10 +68,9 @@ func (e *BazelExtractor) Extract(projectPath string, filePath string) ([]models.
 		// 提取 git_repository 依赖
 		if matches := gitRepositoryRegex.FindStringSubmatch(line); len(matches) > 1 {
 			dep := models.Dependency{
-				Name:     matches[1],
-				Type:     "bazel_git_repository",
-				FilePath: filePath,
-				Line:     lineNum,
+				Name:       matches[1],
+				Type:       "bazel_git_repository",
+				Provenance: lineProvenance(BazelExtractorType, filePath, lineNum, line, nil, "bazel.git_repository"),
 			}
 			dependencies = append(dependencies, dep)
 		}
@@ -80,10 +78,9 @@ func (e *BazelExtractor) Extract(projectPath string, filePath string) ([]models.
 		// 提取 local_repository 依赖
 		if matches := localRepositoryRegex.FindStringSubmatch(line); len(matches) > 1 {
 			dep := models.Dependency{
-				Name:     matches[1],
-				Type:     "bazel_local_repository",
-				FilePath: filePath,
-				Line:     lineNum,
+				Name:       matches[1],
+				Type:       "bazel_local_repository",
+				Provenance: lineProvenance(BazelExtractorType, filePath, lineNum, line, nil, "bazel.local_repository"),
 			}
 			dependencies = append(dependencies, dep)
 		}
@@ -91,10 +88,9 @@ func (e *BazelExtractor) Extract(projectPath string, filePath string) ([]models.
 		// 提取 maven_jar 依赖
 		if matches := mavenJarRegex.FindStringSubmatch(line); len(matches) > 1 {
 			dep := models.Dependency{
-				Name:     matches[1],
-				Type:     "bazel_maven_jar",
-				FilePath: filePath,
-				Line:     lineNum,
+				Name:       matches[1],
+				Type:       "bazel_maven_jar",
+				Provenance: lineProvenance(BazelExtractorType, filePath, lineNum, line, nil, "bazel.maven_jar"),
 			}
 			dependencies = append(dependencies, dep)
 		}
@@ -104,6 +100,8 @@ func (e *BazelExtractor) Extract(projectPath string, filePath string) ([]models.
 		return nil, fmt.Errorf("error scanning file %s: %v", filePath, err)
 	}
 
+	setConfidence(BazelExtractorType, dependencies)
+	setScope(BazelExtractorType, dependencies)
 	return dependencies, nil
 }
 
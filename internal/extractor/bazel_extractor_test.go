@@ -102,7 +102,7 @@ maven_jar(
 		expectedType, ok := expectedDeps[dep.Name]
 		assert.True(t, ok, "Unexpected dependency: %s", dep.Name)
 		assert.Equal(t, expectedType, dep.Type)
-		assert.Equal(t, testFile, dep.FilePath)
+		assert.Equal(t, testFile, dep.Provenance.File)
 	}
 }
 
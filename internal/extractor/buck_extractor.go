@@ -82,10 +82,9 @@ func (e *BuckExtractor) Extract(projectPath string, filePath string) ([]models.D
 			if deps := extractDepsFromLine(line); len(deps) > 0 {
 				for _, dep := range deps {
 					dependencies = append(dependencies, models.Dependency{
-						Name:     dep,
-						Type:     "buck_dependency",
-						FilePath: filePath,
-						Line:     lineNum,
+						Name:       dep,
+						Type:       "buck_dependency",
+						Provenance: lineProvenance(BuckExtractorType, filePath, lineNum, line, nil, "buck.dependency"),
 						Parent:   currentTarget,
 					})
 				}
@@ -98,10 +97,9 @@ func (e *BuckExtractor) Extract(projectPath string, filePath string) ([]models.D
 			if deps := extractDepsFromLine(line); len(deps) > 0 {
 				for _, dep := range deps {
 					dependencies = append(dependencies, models.Dependency{
-						Name:     dep,
-						Type:     "buck_dependency",
-						FilePath: filePath,
-						Line:     lineNum,
+						Name:       dep,
+						Type:       "buck_dependency",
+						Provenance: lineProvenance(BuckExtractorType, filePath, lineNum, line, nil, "buck.dependency"),
 						Parent:   currentTarget,
 					})
 				}
@@ -118,6 +116,8 @@ func (e *BuckExtractor) Extract(projectPath string, filePath string) ([]models.D
 		return nil, fmt.Errorf("error scanning file %s: %v", filePath, err)
 	}
 
+	setConfidence(BuckExtractorType, dependencies)
+	setScope(BuckExtractorType, dependencies)
 	return dependencies, nil
 }
 
@@ -108,7 +108,7 @@ remote_file(
 		_, ok := expectedDeps[dep.Name]
 		assert.True(t, ok, "Unexpected dependency: %s", dep.Name)
 		assert.Equal(t, "buck_dependency", dep.Type)
-		assert.Equal(t, testFile, dep.FilePath)
+		assert.Equal(t, testFile, dep.Provenance.File)
 		depsFound[dep.Name] = true
 	}
 
@@ -154,7 +154,7 @@ cpp_library(
 		_, ok := expectedDeps[dep.Name]
 		assert.True(t, ok, "Unexpected dependency: %s", dep.Name)
 		assert.Equal(t, "buck_dependency", dep.Type)
-		assert.Equal(t, testFile, dep.FilePath)
+		assert.Equal(t, testFile, dep.Provenance.File)
 		assert.NotEmpty(t, dep.Parent)
 	}
 }
@@ -20,6 +20,7 @@ type CargoManifest struct {
 	Package  CargoPackage         `json:"package"`
 	Dependencies map[string]CargoDependency `json:"dependencies"`
 	DevDependencies map[string]CargoDependency `json:"dev-dependencies"`
+	BuildDependencies map[string]CargoDependency `json:"build-dependencies"`
 }
 
 type CargoPackage struct {
@@ -33,6 +34,7 @@ type CargoDependency struct {
 	Branch   string `json:"branch,omitempty"`
 	Rev      string `json:"rev,omitempty"`
 	Features []string `json:"features,omitempty"`
+	Optional bool     `json:"optional,omitempty"`
 }
 
 // NewCargoExtractor 创建一个新的Cargo提取器实例
@@ -72,59 +74,21 @@ func (e *CargoExtractor) Extract(path string) ([]*models.Dependency, error) {
 
 	// 处理正常依赖
 	for name, dep := range manifest.Dependencies {
-		dependency := &models.Dependency{
-			Name:    name,
-			Version: dep.Version,
-			Type:    "cargo",
+		scope := models.ScopeRuntime
+		if dep.Optional {
+			scope = models.ScopeOptional
 		}
-
-		// 处理Git依赖
-		if dep.Git != "" {
-			dependency.Source = dep.Git
-			if dep.Branch != "" {
-				dependency.Version = fmt.Sprintf("branch=%s", dep.Branch)
-			} else if dep.Rev != "" {
-				dependency.Version = fmt.Sprintf("rev=%s", dep.Rev)
-			}
-		}
-
-		// 添加特性信息
-		if len(dep.Features) > 0 {
-			dependency.Metadata = map[string]interface{}{
-				"features": dep.Features,
-			}
-		}
-
-		dependencies = append(dependencies, dependency)
+		dependencies = append(dependencies, newCargoDependency(name, dep, scope))
 	}
 
 	// 处理开发依赖
 	for name, dep := range manifest.DevDependencies {
-		dependency := &models.Dependency{
-			Name:    name,
-			Version: dep.Version,
-			Type:    "cargo",
-			Scope:   "dev",
-		}
-
-		// 处理Git依赖
-		if dep.Git != "" {
-			dependency.Source = dep.Git
-			if dep.Branch != "" {
-				dependency.Version = fmt.Sprintf("branch=%s", dep.Branch)
-			} else if dep.Rev != "" {
-				dependency.Version = fmt.Sprintf("rev=%s", dep.Rev)
-			}
-		}
-
-		// 添加特性信息
-		if len(dep.Features) > 0 {
-			dependency.Metadata = map[string]interface{}{
-				"features": dep.Features,
-			}
-		}
+		dependencies = append(dependencies, newCargoDependency(name, dep, models.ScopeDev))
+	}
 
-		dependencies = append(dependencies, dependency)
+	// 处理构建脚本(build.rs)依赖
+	for name, dep := range manifest.BuildDependencies {
+		dependencies = append(dependencies, newCargoDependency(name, dep, models.ScopeBuild))
 	}
 
 	// 尝试从Cargo.lock获取更精确的版本信息
@@ -156,5 +120,42 @@ func (e *CargoExtractor) Extract(path string) ([]*models.Dependency, error) {
 		}
 	}
 
+	locator := newLocator(nil)
+	for _, dep := range dependencies {
+		dep.PURL = cargoPackageURL(dep.Name, dep.Version)
+		locator.fill(CargoExtractorType, manifestPath, dep)
+		scoreConfidence(CargoExtractorType, dep)
+		classifyScope(CargoExtractorType, dep)
+	}
+
 	return dependencies, nil
-} 
\ No newline at end of file
+}
+
+// newCargoDependency 将Cargo.toml中的依赖声明转换为依赖模型
+func newCargoDependency(name string, dep CargoDependency, scope string) *models.Dependency {
+	dependency := &models.Dependency{
+		Name:    name,
+		Version: dep.Version,
+		Type:    "cargo",
+		Scope:   scope,
+	}
+
+	// 处理Git依赖
+	if dep.Git != "" {
+		dependency.Source = dep.Git
+		if dep.Branch != "" {
+			dependency.Version = fmt.Sprintf("branch=%s", dep.Branch)
+		} else if dep.Rev != "" {
+			dependency.Version = fmt.Sprintf("rev=%s", dep.Rev)
+		}
+	}
+
+	// 添加特性信息
+	if len(dep.Features) > 0 {
+		dependency.Metadata = map[string]interface{}{
+			"features": dep.Features,
+		}
+	}
+
+	return dependency
+}
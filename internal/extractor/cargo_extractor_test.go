@@ -86,6 +86,7 @@ func TestCargoExtractor_Extract(t *testing.T) {
 			Version: "1.0.152",
 			Type:    "cargo",
 			Source:  "registry+https://github.com/rust-lang/crates.io-index",
+			Scope:   models.ScopeRuntime,
 			Metadata: map[string]interface{}{
 				"features": []string{"derive"},
 			},
@@ -95,6 +96,7 @@ func TestCargoExtractor_Extract(t *testing.T) {
 			Version: "1.25.0",
 			Type:    "cargo",
 			Source:  "registry+https://github.com/rust-lang/crates.io-index",
+			Scope:   models.ScopeRuntime,
 			Metadata: map[string]interface{}{
 				"features": []string{"full"},
 			},
@@ -104,6 +106,7 @@ func TestCargoExtractor_Extract(t *testing.T) {
 			Version: "branch=main",
 			Type:    "cargo",
 			Source:  "https://github.com/user/repo",
+			Scope:   models.ScopeRuntime,
 		},
 		{
 			Name:    "mockall",
@@ -0,0 +1,565 @@
+package extractor
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/fs"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/lkpsg/ccscanner/pkg/models"
+)
+
+// CMakeCacheExtractor CMakeCache.txt 与 CMake File API 提取器
+//
+// find_package 只说明请求了哪些包, 构建目录中的缓存和 File API 应答记录了实际找到的包、
+// 版本和磁盘位置。
+type CMakeCacheExtractor struct {
+	BaseExtractor
+	config ExtractorConfig
+}
+
+// NewCMakeCacheExtractor 创建 CMakeCache.txt 提取器
+func NewCMakeCacheExtractor(path string) *CMakeCacheExtractor {
+	return &CMakeCacheExtractor{
+		BaseExtractor: NewBaseExtractor(path),
+		config:        DefaultConfig,
+	}
+}
+
+// CMakeCacheEntry CMake缓存条目
+type CMakeCacheEntry struct {
+	Name  string `json:"name"`  // 变量名
+	Type  string `json:"type"`  // 类型(PATH、FILEPATH、STRING、INTERNAL等)
+	Value string `json:"value"` // 值
+}
+
+// cmakePackage 解析出的已找到的包
+type cmakePackage struct {
+	name          string
+	dir           string // <Pkg>_DIR
+	configFile    string // <Pkg>Config.cmake
+	version       string
+	versionSource string
+	libraries     map[string]bool
+	includeDirs   map[string]bool
+	targets       map[string]bool
+}
+
+// cacheLineRe CMakeCache.txt 中的 KEY:TYPE=VALUE
+var cacheLineRe = regexp.MustCompile(`^"?([^":=]+)"?:([A-Z]+)=(.*)$`)
+
+// packageDetailsVersionRe FIND_PACKAGE_MESSAGE_DETAILS_<Pkg> 中的版本, 如 [v1.2.11()]
+var packageDetailsVersionRe = regexp.MustCompile(`\]\[v([^\]\(]+)\(`)
+
+// packageVersionRe <Pkg>ConfigVersion.cmake 中的 set(PACKAGE_VERSION "x.y.z")
+var packageVersionRe = regexp.MustCompile(`(?i)set\s*\(\s*PACKAGE_VERSION\s+"?([^")\s]+)"?\s*\)`)
+
+// cmakeCacheSuffixes 与包相关的缓存变量后缀, 长后缀在前
+var cmakeCacheSuffixes = []string{
+	"_LIBRARY_RELEASE", "_LIBRARY_DEBUG", "_LIBRARIES", "_LIBRARY",
+	"_INCLUDE_DIRS", "_INCLUDE_DIR", "_LIBRARY_DIRS", "_LIBRARY_DIR",
+	"_VERSION_STRING", "_VERSION",
+	"_DIR",
+}
+
+// cmakeIgnoredPrefixes 非第三方包的缓存变量前缀
+var cmakeIgnoredPrefixes = []string{"CMAKE", "_CMAKE", "CPACK", "FETCHCONTENT", "CTEST"}
+
+// Extract 读取 CMakeCache.txt 和 File API 应答, 提取已解析的包
+func (e *CMakeCacheExtractor) Extract() ([]models.Dependency, error) {
+	entries, err := readCMakeCache(e.fsys, e.FilePath)
+	if err != nil {
+		return nil, NewExtractorError(CMakeCacheExtractorType, e.FilePath, err.Error())
+	}
+
+	buildDir := filepath.Dir(e.FilePath)
+	reply := readCMakeFileAPI(e.fsys, buildDir)
+	if reply != nil {
+		entries = append(entries, reply.cache...)
+	}
+
+	// <Pkg>_DIR 只有在确实找到了包时才采用, 项目自己的 INSTALL_LIB_DIR 等路径变量同样以 _DIR 结尾
+	packages := collectCMakePackages(entries, func(name, dir string) bool {
+		if reply != nil && reply.hasConfig(name, dir) {
+			return true
+		}
+		return e.fsys == nil && cmakeConfigInDir(dir, name)
+	})
+	if reply != nil {
+		reply.apply(packages)
+	}
+
+	// 与 CMakeExtractor 报告的 find_package 依赖关联
+	// 缓存中的路径是构建机器上的绝对路径, 从虚拟文件系统读取时不再查找
+	requested := make(map[string]models.Dependency)
+	for _, entry := range entries {
+		if e.fsys != nil || entry.Name != "CMAKE_HOME_DIRECTORY" {
+			continue
+		}
+		listFile := filepath.Join(entry.Value, "CMakeLists.txt")
+		if deps, err := NewCMakeExtractor(listFile).Extract(); err == nil {
+			for _, dep := range deps {
+				if dep.Type == "package" {
+					requested[strings.ToLower(dep.Name)] = dep
+				}
+			}
+		}
+		break
+	}
+
+	names := make([]string, 0, len(packages))
+	for key := range packages {
+		names = append(names, key)
+	}
+	sort.Strings(names)
+
+	deps := make([]models.Dependency, 0, len(names))
+	for _, key := range names {
+		pkg := packages[key]
+		if pkg.dir == "" && pkg.configFile == "" && len(pkg.libraries) == 0 && len(pkg.includeDirs) == 0 {
+			continue
+		}
+		if pkg.version == "" && pkg.dir != "" && e.fsys == nil {
+			pkg.version = configVersionFromDir(pkg.dir)
+			if pkg.version != "" {
+				pkg.versionSource = "config-version"
+			}
+		}
+
+		name := pkg.name
+		metadata := map[string]interface{}{
+			"resolved": true,
+		}
+		if req, ok := requested[key]; ok {
+			name = req.Name
+			metadata["find_package"] = req.ConfigFile
+		}
+
+		location := pkg.dir
+		if location == "" && pkg.configFile != "" {
+			location = filepath.Dir(pkg.configFile)
+		}
+		if location != "" {
+			metadata["location"] = location
+		}
+		if pkg.configFile != "" {
+			metadata["config_file"] = pkg.configFile
+		}
+		if len(pkg.libraries) > 0 {
+			metadata["libraries"] = sortedKeys(pkg.libraries)
+		}
+		if len(pkg.includeDirs) > 0 {
+			metadata["include_dirs"] = sortedKeys(pkg.includeDirs)
+		}
+		if len(pkg.targets) > 0 {
+			metadata["targets"] = sortedKeys(pkg.targets)
+		}
+		if pkg.versionSource != "" {
+			metadata["version_source"] = pkg.versionSource
+		}
+
+		dep := models.NewDependency(name)
+		dep.Version = pkg.version
+		dep.Type = "package"
+		dep.BuildSystem = "cmake"
+		dep.DetectedBy = "CMakeCacheExtractor"
+		dep.ConfigFile = e.FilePath
+		dep.ConfigFileType = "CMakeCache.txt"
+		dep.Metadata = metadata
+		deps = append(deps, *dep)
+	}
+
+	e.annotate(CMakeCacheExtractorType, deps)
+	return deps, nil
+}
+
+// readCMakeCache 解析 CMakeCache.txt
+func readCMakeCache(fsys fs.FS, path string) ([]CMakeCacheEntry, error) {
+	file, err := openFS(fsys, path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []CMakeCacheEntry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "//") {
+			continue
+		}
+		if m := cacheLineRe.FindStringSubmatch(line); m != nil {
+			entries = append(entries, CMakeCacheEntry{Name: m[1], Type: m[2], Value: m[3]})
+		}
+	}
+	return entries, scanner.Err()
+}
+
+// collectCMakePackages 从缓存条目中按包名归类, 键为小写包名
+// <Pkg>_DIR 需要由 FIND_PACKAGE_MESSAGE_DETAILS_<Pkg> 或 hasConfig(目录中的包配置文件)确认
+func collectCMakePackages(entries []CMakeCacheEntry, hasConfig func(name, dir string) bool) map[string]*cmakePackage {
+	packages := make(map[string]*cmakePackage)
+	packageOf := func(name string) *cmakePackage {
+		key := strings.ToLower(name)
+		pkg, ok := packages[key]
+		if !ok {
+			pkg = &cmakePackage{
+				name:        name,
+				libraries:   make(map[string]bool),
+				includeDirs: make(map[string]bool),
+				targets:     make(map[string]bool),
+			}
+			packages[key] = pkg
+		}
+		return pkg
+	}
+
+	// 第一遍: FIND_PACKAGE_MESSAGE_DETAILS、<Pkg>_DIR、<Pkg>_INCLUDE_DIR 等确定包名
+	found := make(map[string]bool)
+	var dirs []CMakeCacheEntry
+	for _, entry := range entries {
+		if name := strings.TrimPrefix(entry.Name, "FIND_PACKAGE_MESSAGE_DETAILS_"); name != entry.Name {
+			found[strings.ToLower(name)] = true
+			pkg := packageOf(name)
+			pkg.name = name // find_package 的原始大小写
+			if m := packageDetailsVersionRe.FindStringSubmatch(entry.Value); m != nil {
+				pkg.version = m[1]
+				pkg.versionSource = "find_package"
+			}
+			continue
+		}
+		prefix, suffix := splitCMakeCacheName(entry.Name)
+		if prefix == "" || entry.Type == "INTERNAL" || cmakeValueNotFound(entry.Value) {
+			continue
+		}
+		switch suffix {
+		case "_DIR":
+			dirs = append(dirs, CMakeCacheEntry{Name: prefix, Value: entry.Value})
+		case "_INCLUDE_DIR", "_INCLUDE_DIRS":
+			pkg := packageOf(prefix)
+			for _, dir := range strings.Split(entry.Value, ";") {
+				if dir != "" {
+					pkg.includeDirs[dir] = true
+				}
+			}
+		case "_VERSION", "_VERSION_STRING":
+			pkg := packageOf(prefix)
+			if pkg.version == "" {
+				pkg.version = entry.Value
+				pkg.versionSource = "cache"
+			}
+		}
+	}
+
+	for _, dir := range dirs {
+		if found[strings.ToLower(dir.Name)] || hasConfig(dir.Name, dir.Value) {
+			packageOf(dir.Name).dir = dir.Value
+		}
+	}
+
+	// 第二遍: *_LIBRARY 归入最长匹配的已知包名(OPENSSL_CRYPTO_LIBRARY → OPENSSL)
+	for _, entry := range entries {
+		prefix, suffix := splitCMakeCacheName(entry.Name)
+		if prefix == "" || entry.Type == "INTERNAL" || cmakeValueNotFound(entry.Value) {
+			continue
+		}
+		switch suffix {
+		case "_LIBRARY", "_LIBRARIES", "_LIBRARY_RELEASE", "_LIBRARY_DEBUG":
+		default:
+			continue
+		}
+		owner := longestPackagePrefix(prefix, packages)
+		if owner == nil {
+			owner = packageOf(prefix)
+		}
+		for _, lib := range strings.Split(entry.Value, ";") {
+			if lib != "" && lib != "optimized" && lib != "debug" && lib != "general" {
+				owner.libraries[lib] = true
+			}
+		}
+	}
+
+	return packages
+}
+
+// splitCMakeCacheName 将缓存变量名拆分为包名前缀和后缀, 非包相关变量返回空
+func splitCMakeCacheName(name string) (string, string) {
+	if strings.HasSuffix(name, "_BINARY_DIR") || strings.HasSuffix(name, "_SOURCE_DIR") {
+		return "", ""
+	}
+	for _, prefix := range cmakeIgnoredPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return "", ""
+		}
+	}
+	for _, suffix := range cmakeCacheSuffixes {
+		if strings.HasSuffix(name, suffix) && len(name) > len(suffix) {
+			return strings.TrimSuffix(name, suffix), suffix
+		}
+	}
+	return "", ""
+}
+
+// longestPackagePrefix 查找名称以其为前缀的最长已知包
+func longestPackagePrefix(prefix string, packages map[string]*cmakePackage) *cmakePackage {
+	lower := strings.ToLower(prefix)
+	var best *cmakePackage
+	bestLen := 0
+	for key, pkg := range packages {
+		if (lower == key || strings.HasPrefix(lower, key+"_")) && len(key) > bestLen {
+			best = pkg
+			bestLen = len(key)
+		}
+	}
+	return best
+}
+
+// cmakeValueNotFound 判断缓存值是否表示未找到
+func cmakeValueNotFound(value string) bool {
+	return value == "" || strings.HasSuffix(value, "-NOTFOUND") || value == "NOTFOUND"
+}
+
+// cmakeConfigInDir 判断目录中是否存在包的配置文件 <Pkg>Config.cmake 或 <pkg>-config.cmake
+func cmakeConfigInDir(dir, name string) bool {
+	for _, base := range []string{name + "Config.cmake", name + "-config.cmake", strings.ToLower(name) + "-config.cmake"} {
+		if info, err := statFS(nil, filepath.Join(dir, base)); err == nil && !info.IsDir() {
+			return true
+		}
+	}
+	return false
+}
+
+// configVersionFromDir 读取包配置目录中的 <Pkg>ConfigVersion.cmake 获取版本
+func configVersionFromDir(dir string) string {
+	var files []string
+	for _, pattern := range []string{"*ConfigVersion.cmake", "*-config-version.cmake"} {
+		matches, _ := filepath.Glob(filepath.Join(dir, pattern))
+		files = append(files, matches...)
+	}
+	for _, file := range files {
+		content, err := readFilePrefix(nil, file, maxFingerprintBytes)
+		if err != nil {
+			continue
+		}
+		if m := packageVersionRe.FindStringSubmatch(content); m != nil {
+			return m[1]
+		}
+	}
+	return ""
+}
+
+// cmakeFileAPIReply CMake File API 应答中需要的内容
+type cmakeFileAPIReply struct {
+	fsys       fs.FS
+	cache      []CMakeCacheEntry
+	configs    []string                   // cmakeFiles 中的外部包配置文件
+	targetLibs map[string]map[string]bool // 目标名 → 链接的库
+}
+
+// cmakeFileAPIIndex File API 索引文件
+type cmakeFileAPIIndex struct {
+	Objects []struct {
+		Kind     string `json:"kind"`
+		JSONFile string `json:"jsonFile"`
+	} `json:"objects"`
+}
+
+// readCMakeFileAPI 读取构建目录中最新的 File API 应答, 不存在时返回nil
+func readCMakeFileAPI(fsys fs.FS, buildDir string) *cmakeFileAPIReply {
+	replyDir := filepath.Join(buildDir, ".cmake", "api", "v1", "reply")
+	indexes, _ := globFS(fsys, filepath.Join(replyDir, "index-*.json"))
+	if len(indexes) == 0 {
+		return nil
+	}
+	sort.Strings(indexes)
+
+	var index cmakeFileAPIIndex
+	if err := readJSONFile(fsys, indexes[len(indexes)-1], &index); err != nil {
+		return nil
+	}
+
+	reply := &cmakeFileAPIReply{fsys: fsys, targetLibs: make(map[string]map[string]bool)}
+	for _, object := range index.Objects {
+		file := filepath.Join(replyDir, object.JSONFile)
+		switch object.Kind {
+		case "cache":
+			var cache struct {
+				Entries []CMakeCacheEntry `json:"entries"`
+			}
+			if readJSONFile(fsys, file, &cache) == nil {
+				reply.cache = append(reply.cache, cache.Entries...)
+			}
+		case "cmakeFiles":
+			var files struct {
+				Inputs []struct {
+					Path       string `json:"path"`
+					IsExternal bool   `json:"isExternal"`
+					IsCMake    bool   `json:"isCMake"`
+				} `json:"inputs"`
+			}
+			if readJSONFile(fsys, file, &files) == nil {
+				for _, input := range files.Inputs {
+					if input.IsExternal && !input.IsCMake && cmakeConfigPackageName(input.Path) != "" {
+						reply.configs = append(reply.configs, input.Path)
+					}
+				}
+			}
+		case "codemodel":
+			reply.readCodemodel(replyDir, file)
+		}
+	}
+	return reply
+}
+
+// hasConfig 判断 cmakeFiles 中是否加载了dir目录中名为name的包配置文件
+func (r *cmakeFileAPIReply) hasConfig(name, dir string) bool {
+	for _, config := range r.configs {
+		if filepath.Dir(config) == filepath.Clean(dir) && strings.EqualFold(cmakeConfigPackageName(config), name) {
+			return true
+		}
+	}
+	return false
+}
+
+// readCodemodel 读取 codemodel 中各目标的链接库
+func (r *cmakeFileAPIReply) readCodemodel(replyDir, file string) {
+	var codemodel struct {
+		Configurations []struct {
+			Targets []struct {
+				Name     string `json:"name"`
+				JSONFile string `json:"jsonFile"`
+			} `json:"targets"`
+		} `json:"configurations"`
+	}
+	if readJSONFile(r.fsys, file, &codemodel) != nil {
+		return
+	}
+
+	for _, config := range codemodel.Configurations {
+		for _, target := range config.Targets {
+			var detail struct {
+				Link struct {
+					CommandFragments []struct {
+						Fragment string `json:"fragment"`
+						Role     string `json:"role"`
+					} `json:"commandFragments"`
+				} `json:"link"`
+			}
+			if readJSONFile(r.fsys, filepath.Join(replyDir, target.JSONFile), &detail) != nil {
+				continue
+			}
+			for _, fragment := range detail.Link.CommandFragments {
+				if fragment.Role != "libraries" {
+					continue
+				}
+				if r.targetLibs[target.Name] == nil {
+					r.targetLibs[target.Name] = make(map[string]bool)
+				}
+				r.targetLibs[target.Name][strings.Trim(fragment.Fragment, `"`)] = true
+			}
+		}
+	}
+}
+
+// apply 将 File API 中的配置文件和目标链接信息合并到包中
+func (r *cmakeFileAPIReply) apply(packages map[string]*cmakePackage) {
+	for _, config := range r.configs {
+		key := strings.ToLower(cmakeConfigPackageName(config))
+		pkg, ok := packages[key]
+		if !ok {
+			continue
+		}
+		pkg.configFile = config
+		if pkg.dir == "" {
+			pkg.dir = filepath.Dir(config)
+		}
+	}
+
+	for target, libs := range r.targetLibs {
+		for _, pkg := range packages {
+			for lib := range libs {
+				if pkg.linksLibrary(lib) {
+					pkg.targets[target] = true
+					break
+				}
+			}
+		}
+	}
+}
+
+// linksLibrary 判断链接片段是否属于该包
+func (p *cmakePackage) linksLibrary(fragment string) bool {
+	for lib := range p.libraries {
+		if fragment == lib || filepath.Base(fragment) == filepath.Base(lib) {
+			return true
+		}
+	}
+	if p.dir != "" {
+		// 导入目标的库通常位于 <prefix>/lib, 配置目录为 <prefix>/lib/cmake/<Pkg>
+		prefix := filepath.Dir(filepath.Dir(p.dir))
+		if filepath.Base(prefix) == "lib" || filepath.Base(prefix) == "lib64" {
+			return filepath.Dir(fragment) == prefix
+		}
+	}
+	return false
+}
+
+// cmakeConfigPackageName 从 <Pkg>Config.cmake / <pkg>-config.cmake 中取包名
+func cmakeConfigPackageName(path string) string {
+	base := filepath.Base(path)
+	switch {
+	case strings.HasSuffix(base, "ConfigVersion.cmake") || strings.HasSuffix(base, "-config-version.cmake"):
+		return ""
+	case strings.HasSuffix(base, "Config.cmake"):
+		return strings.TrimSuffix(base, "Config.cmake")
+	case strings.HasSuffix(base, "-config.cmake"):
+		return strings.TrimSuffix(base, "-config.cmake")
+	}
+	return ""
+}
+
+// readJSONFile 读取并解析JSON文件
+func readJSONFile(fsys fs.FS, path string, v interface{}) error {
+	data, err := readFileFS(fsys, path)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// CMakeCacheExtractorFactory CMakeCache.txt 提取器工厂
+type CMakeCacheExtractorFactory struct{}
+
+// CreateExtractor 创建 CMakeCache.txt 提取器
+func (f *CMakeCacheExtractorFactory) CreateExtractor(path string) Extractor {
+	return NewCMakeCacheExtractor(path)
+}
+
+func init() {
+	// 注册 CMakeCache.txt 提取器
+	RegisterExtractor(CMakeCacheExtractorType, &CMakeCacheExtractorFactory{})
+}
+
+/*
+使用示例:
+
+1. 创建提取器(参数为构建目录中的CMakeCache.txt):
+extractor := NewCMakeCacheExtractor("/path/to/build/CMakeCache.txt")
+
+2. 提取依赖(构建目录中存在 .cmake/api/v1/reply 时同时读取 File API 应答):
+deps, err := extractor.Extract()
+if err != nil {
+    log.Printf("Failed to read CMake cache: %v\n", err)
+    return
+}
+
+3. 处理依赖信息:
+for _, dep := range deps {
+    fmt.Printf("%s %s at %v (requested in %v)\n",
+        dep.Name, dep.Version, dep.Metadata["location"], dep.Metadata["find_package"])
+}
+*/
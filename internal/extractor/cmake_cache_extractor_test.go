@@ -0,0 +1,128 @@
+package extractor
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCMakeCacheExtractor_Extract(t *testing.T) {
+	root := t.TempDir()
+	src := filepath.Join(root, "src")
+	build := filepath.Join(root, "build")
+	prefix := filepath.Join(root, "prefix")
+	fmtDir := filepath.Join(prefix, "lib", "cmake", "fmt")
+
+	writeTree(t, root, map[string]string{
+		"src/CMakeLists.txt":                            "project(demo)\nfind_package(ZLIB REQUIRED)\nfind_package(fmt CONFIG)\nfind_package(OpenSSL)\n",
+		"prefix/lib/cmake/fmt/fmt-config.cmake":         "",
+		"prefix/lib/cmake/fmt/fmt-config-version.cmake": "set(PACKAGE_VERSION \"10.1.1\")\n",
+		"build/CMakeCache.txt": `# This is the CMakeCache file.
+//Path to a file.
+ZLIB_INCLUDE_DIR:PATH=/usr/include
+ZLIB_LIBRARY_RELEASE:FILEPATH=/usr/lib/x86_64-linux-gnu/libz.so
+//The directory containing a CMake configuration file for fmt.
+fmt_DIR:PATH=` + fmtDir + `
+OPENSSL_INCLUDE_DIR:PATH=OPENSSL_INCLUDE_DIR-NOTFOUND
+OPENSSL_CRYPTO_LIBRARY:FILEPATH=OPENSSL_CRYPTO_LIBRARY-NOTFOUND
+demo_BINARY_DIR:STATIC=` + build + `
+demo_SOURCE_DIR:STATIC=` + src + `
+CMAKE_BUILD_TYPE:STRING=Release
+CMAKE_HOME_DIRECTORY:INTERNAL=` + src + `
+FIND_PACKAGE_MESSAGE_DETAILS_ZLIB:INTERNAL=[/usr/lib/x86_64-linux-gnu/libz.so][/usr/include][v1.2.13()]
+`,
+		"build/.cmake/api/v1/reply/index-2024-01-01T00-00-00-0000.json": `{
+  "objects": [
+    {"kind": "codemodel", "version": {"major": 2, "minor": 6}, "jsonFile": "codemodel-v2-1.json"},
+    {"kind": "cmakeFiles", "version": {"major": 1, "minor": 0}, "jsonFile": "cmakeFiles-v1-1.json"}
+  ]
+}`,
+		"build/.cmake/api/v1/reply/codemodel-v2-1.json": `{
+  "configurations": [{"name": "Release", "targets": [{"name": "demo", "jsonFile": "target-demo-1.json"}]}]
+}`,
+		"build/.cmake/api/v1/reply/target-demo-1.json": `{
+  "name": "demo",
+  "link": {"commandFragments": [
+    {"fragment": "-O3", "role": "flags"},
+    {"fragment": "/usr/lib/x86_64-linux-gnu/libz.so", "role": "libraries"},
+    {"fragment": "` + filepath.Join(prefix, "lib", "libfmt.a") + `", "role": "libraries"}
+  ]}
+}`,
+		"build/.cmake/api/v1/reply/cmakeFiles-v1-1.json": `{
+  "inputs": [
+    {"path": "CMakeLists.txt"},
+    {"path": "/usr/share/cmake/Modules/FindZLIB.cmake", "isExternal": true, "isCMake": true},
+    {"path": "` + filepath.Join(fmtDir, "fmt-config.cmake") + `", "isExternal": true},
+    {"path": "` + filepath.Join(fmtDir, "fmt-config-version.cmake") + `", "isExternal": true}
+  ]
+}`,
+	})
+
+	deps, err := NewCMakeCacheExtractor(filepath.Join(build, "CMakeCache.txt")).Extract()
+	require.NoError(t, err)
+	require.Len(t, deps, 2)
+
+	fmtDep := deps[0]
+	assert.Equal(t, "fmt", fmtDep.Name)
+	assert.Equal(t, "10.1.1", fmtDep.Version)
+	assert.Equal(t, fmtDir, fmtDep.Metadata["location"])
+	assert.Equal(t, filepath.Join(fmtDir, "fmt-config.cmake"), fmtDep.Metadata["config_file"])
+	assert.Equal(t, []string{"demo"}, fmtDep.Metadata["targets"])
+	assert.Equal(t, filepath.Join(src, "CMakeLists.txt"), fmtDep.Metadata["find_package"])
+
+	zlib := deps[1]
+	assert.Equal(t, "ZLIB", zlib.Name)
+	assert.Equal(t, "1.2.13", zlib.Version)
+	assert.Equal(t, "find_package", zlib.Metadata["version_source"])
+	assert.Equal(t, []string{"/usr/lib/x86_64-linux-gnu/libz.so"}, zlib.Metadata["libraries"])
+	assert.Equal(t, []string{"/usr/include"}, zlib.Metadata["include_dirs"])
+	assert.Equal(t, []string{"demo"}, zlib.Metadata["targets"])
+	assert.Equal(t, "package", zlib.Type)
+}
+
+func TestCMakeCacheExtractor_UnconfirmedDirs(t *testing.T) {
+	root := t.TempDir()
+	spdlogDir := filepath.Join(root, "prefix", "lib", "cmake", "spdlog")
+	writeTree(t, root, map[string]string{
+		"prefix/lib/cmake/spdlog/spdlogConfig.cmake": "",
+		"build/CMakeCache.txt": `//Installation directory for libraries
+INSTALL_LIB_DIR:PATH=/usr/local/lib
+//Installation directory for headers
+INSTALL_INC_DIR:PATH=/usr/local/include
+Stale_DIR:PATH=` + filepath.Join(root, "prefix", "lib", "cmake", "stale") + `
+spdlog_DIR:PATH=` + spdlogDir + `
+GTest_DIR:PATH=/opt/gtest/lib/cmake/GTest
+FIND_PACKAGE_MESSAGE_DETAILS_GTest:INTERNAL=[/opt/gtest/lib/cmake/GTest/GTestConfig.cmake][v1.14.0()]
+`,
+	})
+
+	deps, err := NewCMakeCacheExtractor(filepath.Join(root, "build", "CMakeCache.txt")).Extract()
+	require.NoError(t, err)
+
+	// 项目自己的路径变量和没有包配置文件的目录不是依赖
+	locations := make(map[string]interface{})
+	for _, dep := range deps {
+		locations[dep.Name] = dep.Metadata["location"]
+	}
+	assert.Equal(t, map[string]interface{}{
+		"GTest":  "/opt/gtest/lib/cmake/GTest",
+		"spdlog": spdlogDir,
+	}, locations)
+}
+
+func TestSplitCMakeCacheName(t *testing.T) {
+	tests := map[string][2]string{
+		"ZLIB_LIBRARY_RELEASE":   {"ZLIB", "_LIBRARY_RELEASE"},
+		"OPENSSL_CRYPTO_LIBRARY": {"OPENSSL_CRYPTO", "_LIBRARY"},
+		"Boost_INCLUDE_DIR":      {"Boost", "_INCLUDE_DIR"},
+		"fmt_DIR":                {"fmt", "_DIR"},
+		"demo_SOURCE_DIR":        {"", ""},
+		"CMAKE_INSTALL_DIR":      {"", ""},
+	}
+	for name, want := range tests {
+		prefix, suffix := splitCMakeCacheName(name)
+		assert.Equal(t, want, [2]string{prefix, suffix}, name)
+	}
+}
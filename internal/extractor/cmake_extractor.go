@@ -3,7 +3,6 @@ package extractor
 import (
 	"bufio"
 	"fmt"
-	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
@@ -28,7 +27,7 @@ func NewCMakeExtractor(path string) *CMakeExtractor {
 // Extract 提取CMake依赖
 func (e *CMakeExtractor) Extract() ([]models.Dependency, error) {
 	// 读取CMake文件
-	file, err := os.Open(e.FilePath)
+	file, err := e.open(e.FilePath)
 	if err != nil {
 		return nil, NewExtractorError(CMakeExtractorType, e.FilePath, err.Error())
 	}
@@ -77,6 +76,7 @@ func (e *CMakeExtractor) Extract() ([]models.Dependency, error) {
 			dep.DetectedBy = "CMakeExtractor"
 			dep.ConfigFile = e.FilePath
 			dep.ConfigFileType = "CMakeLists.txt"
+			dep.Provenance = lineProvenance(CMakeExtractorType, e.FilePath, lineNum, line, findPackageRe.FindStringIndex(line), "cmake.find_package")
 			deps = append(deps, *dep)
 		}
 
@@ -88,13 +88,16 @@ func (e *CMakeExtractor) Extract() ([]models.Dependency, error) {
 			dep.DetectedBy = "CMakeExtractor"
 			dep.ConfigFile = e.FilePath
 			dep.ConfigFileType = "CMakeLists.txt"
+			dep.Provenance = lineProvenance(CMakeExtractorType, e.FilePath, lineNum, line, findLibraryRe.FindStringIndex(line), "cmake.find_library")
 			deps = append(deps, *dep)
 		}
 
 		// 提取target_link_libraries
-		if matches := targetLinkRe.FindStringSubmatch(line); len(matches) > 1 {
-			libs := strings.Fields(matches[1])
-			for _, lib := range libs {
+		if loc := targetLinkRe.FindStringSubmatchIndex(line); loc != nil {
+			offset := loc[2]
+			for _, lib := range strings.Fields(line[loc[2]:loc[3]]) {
+				start := offset + strings.Index(line[offset:], lib)
+				offset = start + len(lib)
 				// 忽略变量引用
 				if strings.HasPrefix(lib, "${") {
 					continue
@@ -105,6 +108,7 @@ func (e *CMakeExtractor) Extract() ([]models.Dependency, error) {
 				dep.DetectedBy = "CMakeExtractor"
 				dep.ConfigFile = e.FilePath
 				dep.ConfigFileType = "CMakeLists.txt"
+				dep.Provenance = lineProvenance(CMakeExtractorType, e.FilePath, lineNum, line, []int{start, offset}, "cmake.target_link_libraries")
 				deps = append(deps, *dep)
 			}
 		}
@@ -117,6 +121,7 @@ func (e *CMakeExtractor) Extract() ([]models.Dependency, error) {
 			dep.DetectedBy = "CMakeExtractor"
 			dep.ConfigFile = e.FilePath
 			dep.ConfigFileType = "CMakeLists.txt"
+			dep.Provenance = lineProvenance(CMakeExtractorType, e.FilePath, lineNum, line, includeRe.FindStringIndex(line), "cmake.include")
 			deps = append(deps, *dep)
 		}
 
@@ -128,6 +133,7 @@ func (e *CMakeExtractor) Extract() ([]models.Dependency, error) {
 			dep.DetectedBy = "CMakeExtractor"
 			dep.ConfigFile = e.FilePath
 			dep.ConfigFileType = "CMakeLists.txt"
+			dep.Provenance = lineProvenance(CMakeExtractorType, e.FilePath, lineNum, line, requireRe.FindStringIndex(line), "cmake.require")
 			deps = append(deps, *dep)
 		}
 	}
@@ -144,6 +150,7 @@ func (e *CMakeExtractor) Extract() ([]models.Dependency, error) {
 		}
 	}
 
+	e.annotate(CMakeExtractorType, deps)
 	return deps, nil
 }
 
@@ -152,7 +159,7 @@ func (e *CMakeExtractor) extractIncludedFiles(deps *[]models.Dependency) error {
 	dir := filepath.Dir(e.FilePath)
 	includeRe := regexp.MustCompile(`(?i)include\s*\(\s*([^)]+)\)`)
 
-	file, err := os.Open(e.FilePath)
+	file, err := e.open(e.FilePath)
 	if err != nil {
 		return err
 	}
@@ -177,13 +184,14 @@ func (e *CMakeExtractor) extractIncludedFiles(deps *[]models.Dependency) error {
 			}
 
 			// 检查文件是否存在
-			if _, err := os.Stat(fullPath); os.IsNotExist(err) {
+			if _, err := e.stat(fullPath); err != nil {
 				continue
 			}
 
 			// 创建新的提取器处理包含的文件
 			includeExtractor := NewCMakeExtractor(fullPath)
 			includeExtractor.config = e.config
+			includeExtractor.fsys = e.fsys
 
 			// 提取依赖
 			includeDeps, err := includeExtractor.Extract()
@@ -0,0 +1,589 @@
+package extractor
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/lkpsg/ccscanner/pkg/models"
+)
+
+// CompileCommand 编译数据库中的一条记录
+type CompileCommand struct {
+	Directory string   `json:"directory"`           // 编译时的工作目录
+	File      string   `json:"file"`                // 翻译单元源文件
+	Command   string   `json:"command,omitempty"`   // 完整命令行
+	Arguments []string `json:"arguments,omitempty"` // 已拆分的命令行参数
+	Output    string   `json:"output,omitempty"`    // 输出文件
+}
+
+// CompileCommandsExtractor compile_commands.json 提取器
+type CompileCommandsExtractor struct {
+	BaseExtractor
+	config ExtractorConfig
+}
+
+// NewCompileCommandsExtractor 创建 compile_commands.json 提取器
+func NewCompileCommandsExtractor(path string) *CompileCommandsExtractor {
+	return &CompileCommandsExtractor{
+		BaseExtractor: NewBaseExtractor(path),
+		config:        DefaultConfig,
+	}
+}
+
+// compileFlags 单个翻译单元的编译参数
+type compileFlags struct {
+	includeRoots []string // -I/-isystem/-iquote/-idirafter 目录(绝对路径)
+	defines      []string // -D 宏(不含值)
+	linkLibs     []string // -l 库名或直接链接的库文件
+	linkFlags    []string // 原始链接参数
+}
+
+// compileUsage 某个依赖的使用情况
+type compileUsage struct {
+	name    string
+	version string
+	units   map[string]bool
+	roots   map[string]bool
+	libs    map[string]bool
+	flags   map[string]bool
+	macros  map[string]bool
+}
+
+// linkLibraryNames 常见 -l 库名到库名称的映射
+var linkLibraryNames = map[string]string{
+	"z":           "zlib",
+	"ssl":         "openssl",
+	"crypto":      "openssl",
+	"curl":        "libcurl",
+	"png":         "libpng",
+	"png16":       "libpng",
+	"jpeg":        "libjpeg",
+	"turbojpeg":   "libjpeg-turbo",
+	"tiff":        "libtiff",
+	"sqlite3":     "sqlite",
+	"xml2":        "libxml2",
+	"expat":       "expat",
+	"bz2":         "bzip2",
+	"lzma":        "xz",
+	"lz4":         "lz4",
+	"zstd":        "zstd",
+	"archive":     "libarchive",
+	"yaml":        "libyaml",
+	"yaml-cpp":    "yaml-cpp",
+	"fmt":         "fmt",
+	"spdlog":      "spdlog",
+	"glog":        "glog",
+	"gflags":      "gflags",
+	"gtest":       "googletest",
+	"gtest_main":  "googletest",
+	"gmock":       "googletest",
+	"gmock_main":  "googletest",
+	"benchmark":   "benchmark",
+	"protobuf":    "protobuf",
+	"grpc":        "grpc",
+	"grpc++":      "grpc",
+	"event":       "libevent",
+	"uv":          "libuv",
+	"zmq":         "zeromq",
+	"hiredis":     "hiredis",
+	"sodium":      "libsodium",
+	"ffi":         "libffi",
+	"pcre":        "pcre",
+	"pcre2-8":     "pcre2",
+	"gmp":         "gmp",
+	"mpfr":        "mpfr",
+	"readline":    "readline",
+	"ncurses":     "ncurses",
+	"ncursesw":    "ncurses",
+	"uuid":        "libuuid",
+	"usb-1.0":     "libusb",
+	"ssh2":        "libssh2",
+	"git2":        "libgit2",
+	"pq":          "libpq",
+	"mysqlclient": "mysql",
+	"tbb":         "tbb",
+	"pthread":     "pthread",
+	"GL":          "opengl",
+	"glfw":        "glfw",
+	"SDL2":        "sdl2",
+	"freetype":    "freetype",
+	"harfbuzz":    "harfbuzz",
+	"cairo":       "cairo",
+	"glib-2.0":    "glib",
+	"lua":         "lua",
+	"cudart":      "cuda",
+}
+
+// runtimeLinkLibraries 编译器运行时和C库, 不作为依赖报告
+var runtimeLinkLibraries = map[string]bool{
+	"c": true, "m": true, "dl": true, "rt": true, "util": true, "atomic": true,
+	"stdc++": true, "c++": true, "c++abi": true, "gcc": true, "gcc_s": true, "supc++": true,
+}
+
+// featureMacroRe 形如 HAVE_ZLIB、USE_OPENSSL、WITH_LIBCURL 的特性宏
+var featureMacroRe = regexp.MustCompile(`^(?:HAVE|USE|WITH|ENABLE)_(?:LIB)?([A-Za-z0-9]+?)(?:_H)?$`)
+
+// packageDirRe 带版本号的包目录名(如 openssl-1.1.1k、boost_1_82_0、python3.11)
+var packageDirRe = regexp.MustCompile(`^([A-Za-z][A-Za-z0-9+]*?(?:[-_][A-Za-z][A-Za-z0-9+]*)*?)(?:[-_]v?(\d+(?:[._]\d+)*[a-z]?)|(\d+\.\d+(?:\.\d+)*))$`)
+
+// nixStoreRe Nix store 路径(32位哈希前缀)
+var nixStoreRe = regexp.MustCompile(`^[0-9a-z]{32}-(.+)$`)
+
+// Extract 解析编译数据库并提取依赖
+func (e *CompileCommandsExtractor) Extract() ([]models.Dependency, error) {
+	data, err := e.readFile(e.FilePath)
+	if err != nil {
+		return nil, NewExtractorError(CompileCommandsExtractorType, e.FilePath, err.Error())
+	}
+
+	var commands []CompileCommand
+	if err := json.Unmarshal(data, &commands); err != nil {
+		return nil, NewExtractorError(CompileCommandsExtractorType, e.FilePath, "invalid compilation database: "+err.Error())
+	}
+
+	buildDir, _ := filepath.Abs(filepath.Dir(e.FilePath))
+	sourceRoot := compileSourceRoot(buildDir, commands)
+
+	usages := make(map[string]*compileUsage)
+	usageOf := func(name, version string) *compileUsage {
+		usage, ok := usages[name]
+		if !ok {
+			usage = &compileUsage{
+				name:   name,
+				units:  make(map[string]bool),
+				roots:  make(map[string]bool),
+				libs:   make(map[string]bool),
+				flags:  make(map[string]bool),
+				macros: make(map[string]bool),
+			}
+			usages[name] = usage
+		}
+		if usage.version == "" {
+			usage.version = version
+		}
+		return usage
+	}
+
+	for _, cmd := range commands {
+		args := cmd.Arguments
+		if len(args) == 0 {
+			args = splitCommandLine(cmd.Command)
+		}
+		if len(args) == 0 {
+			continue
+		}
+
+		dir := cmd.Directory
+		if dir == "" {
+			dir = buildDir
+		}
+		unit := absPath(dir, cmd.File)
+		if rel, err := filepath.Rel(sourceRoot, unit); err == nil && !strings.HasPrefix(rel, "..") {
+			unit = filepath.ToSlash(rel)
+		}
+
+		flags := parseCompileFlags(args[1:], dir)
+		var used []*compileUsage
+
+		for _, root := range flags.includeRoots {
+			if isWithinDir(root, sourceRoot) || isWithinDir(root, buildDir) {
+				continue
+			}
+			name, version := libraryFromIncludeRoot(root)
+			if name == "" {
+				continue
+			}
+			usage := usageOf(name, version)
+			usage.roots[root] = true
+			usage.flags["-I"+root] = true
+			used = append(used, usage)
+		}
+
+		for _, lib := range flags.linkLibs {
+			name := linkLibraryName(lib)
+			if name == "" {
+				continue
+			}
+			usage := usageOf(name, "")
+			usage.libs[lib] = true
+			used = append(used, usage)
+		}
+
+		for _, usage := range used {
+			usage.units[unit] = true
+			for _, flag := range flags.linkFlags {
+				usage.flags[flag] = true
+			}
+			for _, macro := range flags.defines {
+				if featureMacroMatches(macro, usage.name) {
+					usage.macros[macro] = true
+				}
+			}
+		}
+	}
+
+	names := make([]string, 0, len(usages))
+	for name := range usages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	deps := make([]models.Dependency, 0, len(names))
+	for _, name := range names {
+		usage := usages[name]
+		dep := models.NewDependency(name)
+		dep.Version = usage.version
+		dep.Type = "library"
+		dep.BuildSystem = "compile_commands"
+		dep.BuildFlags = sortedKeys(usage.flags)
+		dep.DetectedBy = "CompileCommandsExtractor"
+		dep.ConfigFile = e.FilePath
+		dep.ConfigFileType = "compile_commands.json"
+		dep.Metadata = map[string]interface{}{
+			"translation_units": sortedKeys(usage.units),
+		}
+		if len(usage.roots) > 0 {
+			dep.Metadata["include_roots"] = sortedKeys(usage.roots)
+		}
+		if len(usage.libs) > 0 {
+			dep.Metadata["link_libraries"] = sortedKeys(usage.libs)
+		}
+		if len(usage.macros) > 0 {
+			dep.Metadata["feature_macros"] = sortedKeys(usage.macros)
+		}
+		deps = append(deps, *dep)
+	}
+
+	e.annotate(CompileCommandsExtractorType, deps)
+	return deps, nil
+}
+
+// parseCompileFlags 解析编译参数中的包含目录、宏定义和链接参数
+func parseCompileFlags(args []string, dir string) compileFlags {
+	var flags compileFlags
+
+	// value 返回形如 -Ifoo 或 -I foo 的参数值
+	value := func(i *int, arg, prefix string) (string, bool) {
+		if !strings.HasPrefix(arg, prefix) {
+			return "", false
+		}
+		if len(arg) > len(prefix) {
+			return strings.TrimPrefix(strings.TrimPrefix(arg, prefix), "="), true
+		}
+		if *i+1 < len(args) {
+			*i++
+			return args[*i], true
+		}
+		return "", false
+	}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		// -isystem 等需要先于 -I 判断
+		matched := false
+		for _, prefix := range []string{"-isystem", "-iquote", "-idirafter", "-I"} {
+			if v, ok := value(&i, arg, prefix); ok {
+				if v != "" {
+					flags.includeRoots = append(flags.includeRoots, absPath(dir, v))
+				}
+				matched = true
+				break
+			}
+		}
+		if matched {
+			continue
+		}
+
+		switch {
+		case arg == "-o" || arg == "-MF" || arg == "-MT" || arg == "-MQ" || arg == "-include" || arg == "-x":
+			i++ // 跳过参数值
+		case strings.HasPrefix(arg, "-D"):
+			if v, ok := value(&i, arg, "-D"); ok && v != "" {
+				flags.defines = append(flags.defines, strings.SplitN(v, "=", 2)[0])
+			}
+		case strings.HasPrefix(arg, "-l"):
+			if v, ok := value(&i, arg, "-l"); ok && v != "" {
+				flags.linkLibs = append(flags.linkLibs, strings.TrimPrefix(v, ":"))
+				flags.linkFlags = append(flags.linkFlags, "-l"+v)
+			}
+		case strings.HasPrefix(arg, "-L"):
+			if v, ok := value(&i, arg, "-L"); ok && v != "" {
+				flags.linkFlags = append(flags.linkFlags, "-L"+absPath(dir, v))
+			}
+		case strings.HasPrefix(arg, "-Wl,"):
+			flags.linkFlags = append(flags.linkFlags, arg)
+		case arg == "-pthread":
+			flags.linkLibs = append(flags.linkLibs, "pthread")
+			flags.linkFlags = append(flags.linkFlags, arg)
+		case arg == "-framework" && i+1 < len(args):
+			i++
+			flags.linkFlags = append(flags.linkFlags, "-framework "+args[i])
+		case !strings.HasPrefix(arg, "-") && isLibraryFile(arg):
+			flags.linkLibs = append(flags.linkLibs, filepath.Base(arg))
+			flags.linkFlags = append(flags.linkFlags, arg)
+		}
+	}
+
+	return flags
+}
+
+// isLibraryFile 判断参数是否为直接链接的库文件
+func isLibraryFile(arg string) bool {
+	base := filepath.Base(arg)
+	switch filepath.Ext(base) {
+	case ".a", ".so", ".dylib", ".lib":
+		return true
+	}
+	return strings.Contains(base, ".so.")
+}
+
+// linkLibraryName 将链接库名(z、libssl.so.1.1、libboost_system.a)映射为库名称
+func linkLibraryName(lib string) string {
+	base := filepath.Base(lib)
+	if isLibraryFile(base) {
+		if i := strings.Index(base, ".so"); i > 0 {
+			base = base[:i]
+		} else {
+			base = strings.TrimSuffix(base, filepath.Ext(base))
+		}
+		base = strings.TrimPrefix(base, "lib")
+	}
+	if runtimeLinkLibraries[base] {
+		return ""
+	}
+	if name, ok := linkLibraryNames[base]; ok {
+		return name
+	}
+	if strings.HasPrefix(base, "boost_") {
+		// 与 #include 推断保持一致: boost_filesystem → boost-filesystem
+		component := strings.TrimPrefix(base, "boost_")
+		if i := strings.IndexAny(component, "-"); i > 0 {
+			component = component[:i]
+		}
+		return "boost-" + component
+	}
+	return base
+}
+
+// libraryFromIncludeRoot 根据源码树外的包含目录推断库名称和版本
+func libraryFromIncludeRoot(root string) (string, string) {
+	segs := strings.Split(filepath.ToSlash(filepath.Clean(root)), "/")
+
+	// Nix store: /nix/store/<hash>-openssl-3.0.12-dev/include
+	for _, seg := range segs {
+		if m := nixStoreRe.FindStringSubmatch(seg); m != nil {
+			pkg := strings.TrimSuffix(m[1], "-dev")
+			return splitPackageDir(pkg)
+		}
+	}
+
+	// Conan 1.x: ~/.conan/data/<name>/<version>/<user>/<channel>/package/<id>/include
+	for i, seg := range segs {
+		if seg == "data" && i+5 < len(segs) && segs[i+5] == "package" {
+			return segs[i+1], segs[i+2]
+		}
+	}
+
+	// Homebrew: /usr/local/Cellar/<name>/<version>/include
+	for i, seg := range segs {
+		if seg == "Cellar" && i+2 < len(segs) {
+			return segs[i+1], segs[i+2]
+		}
+	}
+
+	last := -1
+	for i, seg := range segs {
+		if seg == "include" {
+			last = i
+		}
+	}
+	if last < 0 {
+		return splitPackageDir(segs[len(segs)-1])
+	}
+
+	// 包含目录下的子目录(如 /usr/include/libxml2、vcpkg_installed/x64-linux/include/glib-2.0)
+	if last+1 < len(segs) {
+		return splitPackageDir(segs[last+1])
+	}
+	if last == 0 {
+		return "", ""
+	}
+	parent := segs[last-1]
+	switch {
+	case parent == "" || parent == "usr" || parent == "local" || parent == "opt":
+		return "", ""
+	case last >= 2 && segs[last-2] == "vcpkg_installed":
+		return "", ""
+	}
+	return splitPackageDir(parent)
+}
+
+// splitPackageDir 将包目录名拆分为名称和版本
+func splitPackageDir(dir string) (string, string) {
+	if dir == "" || dir == "include" {
+		return "", ""
+	}
+	m := packageDirRe.FindStringSubmatch(dir)
+	if m == nil {
+		return dir, ""
+	}
+	version := m[2]
+	if version == "" {
+		version = m[3]
+	}
+	return m[1], strings.ReplaceAll(version, "_", ".")
+}
+
+// featureMacroMatches 判断特性宏是否对应该库(HAVE_ZLIB → zlib、USE_OPENSSL → openssl)
+func featureMacroMatches(macro, library string) bool {
+	m := featureMacroRe.FindStringSubmatch(macro)
+	if m == nil {
+		return false
+	}
+	normalize := func(s string) string {
+		s = strings.ToLower(s)
+		s = strings.TrimPrefix(s, "lib")
+		return strings.NewReplacer("-", "", "_", "").Replace(s)
+	}
+	return normalize(m[1]) == normalize(library)
+}
+
+// compileSourceRoot 推断源码根目录: 所有翻译单元与构建目录的公共上级目录
+func compileSourceRoot(buildDir string, commands []CompileCommand) string {
+	var files []string
+	for _, cmd := range commands {
+		dir := cmd.Directory
+		if dir == "" {
+			dir = buildDir
+		}
+		files = append(files, filepath.Dir(absPath(dir, cmd.File)))
+	}
+	if len(files) == 0 {
+		return buildDir
+	}
+
+	root := commonDir(append([]string{buildDir}, files...))
+	if filepath.Dir(root) == root {
+		// 源码树外构建(out-of-tree build), 只使用翻译单元的公共目录
+		root = commonDir(files)
+	}
+	return root
+}
+
+// commonDir 计算多个目录的公共上级目录
+func commonDir(dirs []string) string {
+	common := filepath.Clean(dirs[0])
+	for _, dir := range dirs[1:] {
+		dir = filepath.Clean(dir)
+		for !isWithinDir(dir, common) {
+			parent := filepath.Dir(common)
+			if parent == common {
+				return common
+			}
+			common = parent
+		}
+	}
+	return common
+}
+
+// isWithinDir 判断path是否位于dir之内(含dir本身)
+func isWithinDir(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false
+	}
+	return rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)))
+}
+
+// absPath 将相对路径解析为相对dir的绝对路径
+func absPath(dir, path string) string {
+	if filepath.IsAbs(path) {
+		return filepath.Clean(path)
+	}
+	return filepath.Join(dir, path)
+}
+
+// splitCommandLine 按POSIX shell规则拆分命令行(支持引号和反斜杠转义)
+func splitCommandLine(command string) []string {
+	var args []string
+	var current strings.Builder
+	inArg := false
+	var quote byte
+
+	for i := 0; i < len(command); i++ {
+		c := command[i]
+		switch {
+		case quote == '\'':
+			if c == '\'' {
+				quote = 0
+			} else {
+				current.WriteByte(c)
+			}
+		case quote == '"':
+			if c == '"' {
+				quote = 0
+			} else if c == '\\' && i+1 < len(command) && strings.IndexByte(`"\$`+"`", command[i+1]) >= 0 {
+				i++
+				current.WriteByte(command[i])
+			} else {
+				current.WriteByte(c)
+			}
+		case c == '\'' || c == '"':
+			quote = c
+			inArg = true
+		case c == '\\' && i+1 < len(command):
+			i++
+			current.WriteByte(command[i])
+			inArg = true
+		case c == ' ' || c == '\t' || c == '\n':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteByte(c)
+			inArg = true
+		}
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args
+}
+
+// CompileCommandsExtractorFactory compile_commands.json 提取器工厂
+type CompileCommandsExtractorFactory struct{}
+
+// CreateExtractor 创建 compile_commands.json 提取器
+func (f *CompileCommandsExtractorFactory) CreateExtractor(path string) Extractor {
+	return NewCompileCommandsExtractor(path)
+}
+
+func init() {
+	// 注册 compile_commands.json 提取器
+	RegisterExtractor(CompileCommandsExtractorType, &CompileCommandsExtractorFactory{})
+}
+
+/*
+使用示例:
+
+1. 创建提取器:
+extractor := NewCompileCommandsExtractor("/path/to/build/compile_commands.json")
+
+2. 提取依赖:
+deps, err := extractor.Extract()
+if err != nil {
+    log.Printf("Failed to extract dependencies: %v\n", err)
+    return
+}
+
+3. 处理依赖信息:
+for _, dep := range deps {
+    fmt.Printf("%s %s used by %v\n", dep.Name, dep.Version, dep.Metadata["translation_units"])
+}
+*/
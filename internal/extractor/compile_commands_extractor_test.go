@@ -0,0 +1,98 @@
+package extractor
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompileCommandsExtractor_Extract(t *testing.T) {
+	root := t.TempDir()
+	buildDir := filepath.Join(root, "build")
+	require.NoError(t, os.MkdirAll(buildDir, 0755))
+
+	commands := []CompileCommand{
+		{
+			Directory: buildDir,
+			File:      "../src/net.c",
+			Command: `/usr/bin/cc -DHAVE_OPENSSL -DVERSION="\"1.0\"" -I../include -Igenerated ` +
+				`-isystem /opt/openssl-1.1.1k/include -I /usr/include/libxml2 -o net.o -c ../src/net.c`,
+		},
+		{
+			Directory: buildDir,
+			File:      filepath.Join(root, "src", "main.c"),
+			Arguments: []string{
+				"/usr/bin/cc", "-DUSE_ZLIB=1", "-I/nix/store/0c7c96gikmzv87i7lv3vq5s1cmfjd6zf-zlib-1.3-dev/include",
+				"-I/opt/openssl-1.1.1k/include", "-o", "main.o", "-c", filepath.Join(root, "src", "main.c"),
+				"-L/opt/openssl-1.1.1k/lib", "-lssl", "-lcrypto", "-lm", "-pthread", "/usr/lib/libboost_system.a",
+			},
+		},
+	}
+	data, err := json.Marshal(commands)
+	require.NoError(t, err)
+	file := filepath.Join(buildDir, "compile_commands.json")
+	require.NoError(t, os.WriteFile(file, data, 0644))
+
+	deps, err := NewCompileCommandsExtractor(file).Extract()
+	require.NoError(t, err)
+
+	byName := make(map[string]int)
+	for i, dep := range deps {
+		byName[dep.Name] = i
+		assert.Equal(t, "compile_commands.json", dep.ConfigFileType)
+	}
+	assert.Len(t, deps, 5)
+
+	openssl := deps[byName["openssl"]]
+	assert.Equal(t, "1.1.1k", openssl.Version)
+	assert.Equal(t, []string{"src/main.c", "src/net.c"}, openssl.Metadata["translation_units"])
+	assert.Equal(t, []string{"/opt/openssl-1.1.1k/include"}, openssl.Metadata["include_roots"])
+	assert.Equal(t, []string{"crypto", "ssl"}, openssl.Metadata["link_libraries"])
+	assert.Equal(t, []string{"HAVE_OPENSSL"}, openssl.Metadata["feature_macros"])
+	assert.Contains(t, openssl.BuildFlags, "-lssl")
+	assert.Contains(t, openssl.BuildFlags, "-L/opt/openssl-1.1.1k/lib")
+
+	zlib := deps[byName["zlib"]]
+	assert.Equal(t, "1.3", zlib.Version)
+	assert.Equal(t, []string{"src/main.c"}, zlib.Metadata["translation_units"])
+	assert.Equal(t, []string{"USE_ZLIB"}, zlib.Metadata["feature_macros"])
+
+	libxml := deps[byName["libxml2"]]
+	assert.Equal(t, []string{"src/net.c"}, libxml.Metadata["translation_units"])
+
+	assert.Contains(t, byName, "pthread")
+	assert.Contains(t, byName, "boost-system")
+	assert.NotContains(t, byName, "m")
+}
+
+func TestLibraryFromIncludeRoot(t *testing.T) {
+	tests := []struct {
+		root    string
+		name    string
+		version string
+	}{
+		{"/opt/openssl-1.1.1k/include", "openssl", "1.1.1k"},
+		{"/usr/include/glib-2.0", "glib", "2.0"},
+		{"/usr/include/python3.11", "python", "3.11"},
+		{"/usr/include", "", ""},
+		{"/home/u/.conan/data/fmt/10.1.1/_/_/package/abc123/include", "fmt", "10.1.1"},
+		{"/usr/local/Cellar/boost/1.82.0/include", "boost", "1.82.0"},
+		{"/src/vcpkg_installed/x64-linux/include", "", ""},
+		{"/deps/boost_1_82_0", "boost", "1.82.0"},
+		{"/deps/eigen3", "eigen3", ""},
+	}
+	for _, tt := range tests {
+		name, version := libraryFromIncludeRoot(tt.root)
+		assert.Equal(t, tt.name, name, tt.root)
+		assert.Equal(t, tt.version, version, tt.root)
+	}
+}
+
+func TestSplitCommandLine(t *testing.T) {
+	got := splitCommandLine(`cc -DNAME="\"a b\"" 'single quoted' a\ b  -c x.c`)
+	assert.Equal(t, []string{"cc", `-DNAME="a b"`, "single quoted", "a b", "-c", "x.c"}, got)
+}
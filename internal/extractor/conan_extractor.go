@@ -4,7 +4,6 @@ import (
 	"bufio"
 	"encoding/json"
 	"fmt"
-	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
@@ -26,26 +25,41 @@ func NewConanExtractor(path string) *ConanExtractor {
 	}
 }
 
+// conanRequireScopes conanfile中声明依赖的节(属性)及其对应的依赖范围
+var conanRequireScopes = map[string]string{
+	"requires":       models.ScopeRuntime,
+	"tool_requires":  models.ScopeTool,
+	"build_requires": models.ScopeTool,
+	"test_requires":  models.ScopeTest,
+}
+
 // Extract 提取Conan依赖
 func (e *ConanExtractor) Extract() ([]models.Dependency, error) {
-	deps := make([]models.Dependency, 0)
+	var deps []models.Dependency
+	var err error
 
 	// 根据文件类型选择提取方法
 	switch filepath.Base(e.FilePath) {
 	case "conanfile.txt":
-		return e.extractFromTxt()
+		deps, err = e.extractFromTxt()
 	case "conanfile.py":
-		return e.extractFromPy()
+		deps, err = e.extractFromPy()
 	case "conaninfo.txt":
-		return e.extractFromInfo()
+		deps, err = e.extractFromInfo()
 	default:
 		return nil, NewExtractorError(ConanExtractorType, e.FilePath, "unsupported file type")
 	}
+	if err != nil {
+		return nil, err
+	}
+
+	e.annotate(ConanExtractorType, deps)
+	return deps, nil
 }
 
 // extractFromTxt 从conanfile.txt提取依赖
 func (e *ConanExtractor) extractFromTxt() ([]models.Dependency, error) {
-	file, err := os.Open(e.FilePath)
+	file, err := e.open(e.FilePath)
 	if err != nil {
 		return nil, NewExtractorError(ConanExtractorType, e.FilePath, err.Error())
 	}
@@ -54,7 +68,7 @@ func (e *ConanExtractor) extractFromTxt() ([]models.Dependency, error) {
 	deps := make([]models.Dependency, 0)
 	scanner := bufio.NewScanner(file)
 
-	var inRequiresSection bool
+	var sectionScope string // 当前依赖节对应的依赖范围, 为空表示不在依赖节中
 	requireRe := regexp.MustCompile(`^(\S+)/(\S+)(@\S+)?$`)
 
 	for scanner.Scan() {
@@ -66,16 +80,13 @@ func (e *ConanExtractor) extractFromTxt() ([]models.Dependency, error) {
 		}
 
 		// 检查节标记
-		if line == "[requires]" {
-			inRequiresSection = true
-			continue
-		} else if strings.HasPrefix(line, "[") {
-			inRequiresSection = false
+		if strings.HasPrefix(line, "[") {
+			sectionScope = conanRequireScopes[strings.Trim(line, "[]")]
 			continue
 		}
 
 		// 提取依赖
-		if inRequiresSection {
+		if sectionScope != "" {
 			if matches := requireRe.FindStringSubmatch(line); len(matches) > 1 {
 				name := matches[1]
 				version := matches[2]
@@ -91,6 +102,7 @@ func (e *ConanExtractor) extractFromTxt() ([]models.Dependency, error) {
 				dep.DetectedBy = "ConanExtractor"
 				dep.ConfigFile = e.FilePath
 				dep.ConfigFileType = "conanfile.txt"
+				dep.Scope = sectionScope
 				if channel != "" {
 					dep.Source = channel
 				}
@@ -109,7 +121,7 @@ func (e *ConanExtractor) extractFromTxt() ([]models.Dependency, error) {
 
 // extractFromPy 从conanfile.py提取依赖
 func (e *ConanExtractor) extractFromPy() ([]models.Dependency, error) {
-	file, err := os.Open(e.FilePath)
+	file, err := e.open(e.FilePath)
 	if err != nil {
 		return nil, NewExtractorError(ConanExtractorType, e.FilePath, err.Error())
 	}
@@ -118,8 +130,8 @@ func (e *ConanExtractor) extractFromPy() ([]models.Dependency, error) {
 	deps := make([]models.Dependency, 0)
 	scanner := bufio.NewScanner(file)
 
-	requiresRe := regexp.MustCompile(`requires\s*=\s*["']([^"']+)["']`)
-	requireRe := regexp.MustCompile(`self\.requires\(["']([^"']+)["']\)`)
+	requiresRe := regexp.MustCompile(`\b(requires|tool_requires|build_requires|test_requires)\s*=\s*["']([^"']+)["']`)
+	requireRe := regexp.MustCompile(`self\.(requires|tool_requires|build_requires|test_requires)\(["']([^"']+)["']`)
 
 	for scanner.Scan() {
 		line := strings.TrimSpace(scanner.Text())
@@ -129,9 +141,9 @@ func (e *ConanExtractor) extractFromPy() ([]models.Dependency, error) {
 			continue
 		}
 
-		// 提取requires属性
-		if matches := requiresRe.FindStringSubmatch(line); len(matches) > 1 {
-			reqs := strings.Split(matches[1], ",")
+		// 提取requires/tool_requires等属性
+		if matches := requiresRe.FindStringSubmatch(line); len(matches) > 2 {
+			reqs := strings.Split(matches[2], ",")
 			for _, req := range reqs {
 				req = strings.TrimSpace(req)
 				parts := strings.Split(req, "/")
@@ -143,6 +155,7 @@ func (e *ConanExtractor) extractFromPy() ([]models.Dependency, error) {
 					dep.DetectedBy = "ConanExtractor"
 					dep.ConfigFile = e.FilePath
 					dep.ConfigFileType = "conanfile.py"
+					dep.Scope = conanRequireScopes[matches[1]]
 					if len(parts) > 2 {
 						dep.Source = parts[2]
 					}
@@ -151,9 +164,9 @@ func (e *ConanExtractor) extractFromPy() ([]models.Dependency, error) {
 			}
 		}
 
-		// 提取self.requires()调用
-		if matches := requireRe.FindStringSubmatch(line); len(matches) > 1 {
-			req := strings.TrimSpace(matches[1])
+		// 提取self.requires()/self.tool_requires()等调用
+		if matches := requireRe.FindStringSubmatch(line); len(matches) > 2 {
+			req := strings.TrimSpace(matches[2])
 			parts := strings.Split(req, "/")
 			if len(parts) >= 2 {
 				dep := models.NewDependency(parts[0])
@@ -163,6 +176,7 @@ func (e *ConanExtractor) extractFromPy() ([]models.Dependency, error) {
 				dep.DetectedBy = "ConanExtractor"
 				dep.ConfigFile = e.FilePath
 				dep.ConfigFileType = "conanfile.py"
+				dep.Scope = conanRequireScopes[matches[1]]
 				if len(parts) > 2 {
 					dep.Source = parts[2]
 				}
@@ -180,7 +194,7 @@ func (e *ConanExtractor) extractFromPy() ([]models.Dependency, error) {
 
 // extractFromInfo 从conaninfo.txt提取依赖
 func (e *ConanExtractor) extractFromInfo() ([]models.Dependency, error) {
-	file, err := os.Open(e.FilePath)
+	file, err := e.open(e.FilePath)
 	if err != nil {
 		return nil, NewExtractorError(ConanExtractorType, e.FilePath, err.Error())
 	}
@@ -189,7 +203,7 @@ func (e *ConanExtractor) extractFromInfo() ([]models.Dependency, error) {
 	deps := make([]models.Dependency, 0)
 	scanner := bufio.NewScanner(file)
 
-	var inRequiresSection bool
+	var sectionScope string // 当前依赖节对应的依赖范围, 为空表示不在依赖节中
 	requireRe := regexp.MustCompile(`^\s*(\S+)/(\S+)(@\S+)?#\S+$`)
 
 	for scanner.Scan() {
@@ -201,16 +215,13 @@ func (e *ConanExtractor) extractFromInfo() ([]models.Dependency, error) {
 		}
 
 		// 检查节标记
-		if line == "[requires]" {
-			inRequiresSection = true
-			continue
-		} else if strings.HasPrefix(line, "[") {
-			inRequiresSection = false
+		if strings.HasPrefix(line, "[") {
+			sectionScope = conanRequireScopes[strings.Trim(line, "[]")]
 			continue
 		}
 
 		// 提取依赖
-		if inRequiresSection {
+		if sectionScope != "" {
 			if matches := requireRe.FindStringSubmatch(line); len(matches) > 1 {
 				name := matches[1]
 				version := matches[2]
@@ -226,6 +237,7 @@ func (e *ConanExtractor) extractFromInfo() ([]models.Dependency, error) {
 				dep.DetectedBy = "ConanExtractor"
 				dep.ConfigFile = e.FilePath
 				dep.ConfigFileType = "conaninfo.txt"
+				dep.Scope = sectionScope
 				if channel != "" {
 					dep.Source = channel
 				}
@@ -283,6 +295,9 @@ boost/1.76.0
 openssl/1.1.1k@conan/stable
 zlib/1.2.11
 
+[tool_requires]
+cmake/3.25.1
+
 [generators]
 cmake
 ```
@@ -298,6 +313,7 @@ class MyLibConan(ConanFile):
     
     def requirements(self):
         self.requires("zlib/1.2.11")
+        self.test_requires("gtest/1.14.0")
 ```
 
 示例conaninfo.txt文件:
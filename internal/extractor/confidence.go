@@ -0,0 +1,123 @@
+package extractor
+
+import (
+	"fmt"
+
+	"github.com/lkpsg/ccscanner/pkg/models"
+)
+
+// confidenceRule 置信度评分规则
+type confidenceRule struct {
+	Score  float64 // 置信度(0-1)
+	Reason string  // 评分依据
+}
+
+// defaultConfidence 未收录的提取器使用的置信度
+var defaultConfidence = confidenceRule{0.5, "declared by an unclassified extractor"}
+
+// extractorConfidence 各提取器的基础置信度
+// 锁定文件记录了实际使用的版本, 清单是显式声明, 构建脚本中的名称不一定对应包, 启发式推断最不可靠
+var extractorConfidence = map[ExtractorType]confidenceRule{
+	SubmoduleExtractorType:       {0.95, "pinned git submodule"},
+	CMakeCacheExtractorType:      {0.9, "resolved by CMake configure (CMakeCache.txt)"},
+	VcpkgExtractorType:           {0.9, "declared in vcpkg manifest"},
+	ConanExtractorType:           {0.9, "declared in conan recipe"},
+	PkgbuildExtractorType:        {0.9, "declared in distribution package recipe"},
+	ControlExtractorType:         {0.85, "declared in debian control file"},
+	PkgConfigExtractorType:       {0.85, "declared in pkg-config metadata"},
+	NixExtractorType:             {0.8, "declared in nix expression"},
+	NPMExtractorType:             {0.85, "declared in package.json"},
+	YarnExtractorType:            {0.85, "declared in package.json"},
+	MavenExtractorType:           {0.85, "declared in pom.xml"},
+	CargoExtractorType:           {0.85, "declared in Cargo.toml"},
+	VersionMacroExtractorType:    {0.85, "version macro found in library header"},
+	CMakeExtractorType:           {0.75, "referenced by CMake build script"},
+	MesonExtractorType:           {0.75, "declared by meson dependency()"},
+	BazelExtractorType:           {0.75, "declared as Bazel external repository"},
+	BuckExtractorType:            {0.65, "referenced by Buck build rule"},
+	AutoconfExtractorType:        {0.7, "checked by configure script"},
+	VendoredExtractorType:        {0.8, "vendored source matched library fingerprint"},
+	SignatureExtractorType:       {0.75, "library version string found in binary"},
+	ElfExtractorType:             {0.7, "required shared library (DT_NEEDED)"},
+	CompileCommandsExtractorType: {0.6, "include path or link flag in compile_commands.json"},
+	GradleExtractorType:          {0.6, "referenced by Gradle native build"},
+	SConsExtractorType:           {0.6, "referenced by SCons build script"},
+	MakeExtractorType:            {0.6, "referenced by Makefile"},
+	NinjaExtractorType:           {0.5, "input of Ninja build rule"},
+	IncludeExtractorType:         {0.3, "inferred from #include directives"},
+}
+
+// typeConfidence 按提取器和依赖类型细分的置信度, 键为 "提取器/依赖类型"
+var typeConfidence = map[string]confidenceRule{
+	"nix/flake-input":          {0.95, "pinned in flake.lock"},
+	"npm/locked":               {0.95, "pinned in package-lock.json"},
+	"npm/transitive":           {0.9, "resolved in package-lock.json"},
+	"yarn/locked":              {0.95, "pinned in yarn.lock"},
+	"yarn/transitive":          {0.9, "resolved in yarn.lock"},
+	"pkgbuild/source":          {0.95, "source archive with checksum in package recipe"},
+	"control/provides":         {0.6, "virtual package listed in Provides"},
+	"control/replaces":         {0.5, "package listed in Replaces"},
+	"cmake/package":            {0.8, "found by CMake find_package()"},
+	"cmake/library":            {0.5, "library name in find_library()/target_link_libraries()"},
+	"cmake/module":             {0.3, "CMake include() of a module, usually not a package"},
+	"make/library":             {0.4, "-l linker flag, library name may not match a package"},
+	"make/include":             {0.3, "included makefile"},
+	"compile-commands/library": {0.5, "-l linker flag in compile_commands.json"},
+	"autoconf/header":          {0.5, "header checked by AC_CHECK_HEADERS"},
+	"autoconf/library":         {0.55, "library checked by AC_CHECK_LIB"},
+	"autoconf/program":         {0.4, "program checked by AC_CHECK_PROG"},
+	"elf/runtime":              {0.75, "runtime library resolved from ELF dynamic section"},
+}
+
+// setConfidence 为提取器输出的依赖评分, 提取器已评分的依赖保持不变
+func setConfidence(typ ExtractorType, deps []models.Dependency) {
+	for i := range deps {
+		scoreConfidence(typ, &deps[i])
+	}
+}
+
+// scoreConfidence 根据提取器、依赖类型和检测证据评分
+func scoreConfidence(typ ExtractorType, dep *models.Dependency) {
+	if dep.Confidence > 0 {
+		return
+	}
+	rule, ok := typeConfidence[string(typ)+"/"+dep.Type]
+	if !ok {
+		rule, ok = extractorConfidence[typ]
+	}
+	if !ok {
+		rule = defaultConfidence
+	}
+
+	switch typ {
+	case ConanExtractorType:
+		if dep.ConfigFileType == "conaninfo.txt" {
+			rule = confidenceRule{0.95, "resolved by conan install (conaninfo.txt)"}
+		}
+	case VendoredExtractorType:
+		// 内容哈希命中说明是未修改的官方发布版本
+		if dep.Metadata["version_source"] == "sha256" {
+			rule = confidenceRule{0.95, "vendored source matched release file hash"}
+		}
+	case IncludeExtractorType:
+		// 被多个源文件包含的头文件更可能来自真实依赖
+		if count, ok := dep.Metadata["include_count"].(int); ok && count >= 5 {
+			rule = confidenceRule{0.45, fmt.Sprintf("inferred from #include directives in %d files", count)}
+		}
+	}
+
+	dep.Confidence = rule.Score
+	dep.ConfidenceReason = rule.Reason
+}
+
+/*
+使用示例:
+
+deps := []models.Dependency{
+	{Name: "ssl", Type: "library"},
+	{Name: "OpenSSL", Type: "package"},
+}
+setConfidence(CMakeExtractorType, deps)
+fmt.Println(deps[0].Confidence, deps[0].ConfidenceReason) // 0.5 library name in find_library()/target_link_libraries()
+fmt.Println(deps[1].Confidence, deps[1].IsLowConfidence()) // 0.8 false
+*/
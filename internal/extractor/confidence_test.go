@@ -0,0 +1,62 @@
+package extractor
+
+import (
+	"testing"
+
+	"github.com/lkpsg/ccscanner/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetConfidence(t *testing.T) {
+	deps := []models.Dependency{
+		{Name: "OpenSSL", Type: "package"},
+		{Name: "ssl", Type: "library"},
+		{Name: "CTest", Type: "module"},
+		{Name: "custom", Type: "requirement", Confidence: 0.65, ConfidenceReason: "set by extractor"},
+	}
+	setConfidence(CMakeExtractorType, deps)
+
+	assert.Equal(t, 0.8, deps[0].Confidence)
+	assert.False(t, deps[0].IsLowConfidence())
+	assert.Equal(t, 0.5, deps[1].Confidence)
+	assert.Equal(t, "CMake include() of a module, usually not a package", deps[2].ConfidenceReason)
+	assert.True(t, deps[2].IsLowConfidence())
+	assert.Equal(t, 0.65, deps[3].Confidence)
+	assert.Equal(t, "set by extractor", deps[3].ConfidenceReason)
+}
+
+func TestSetConfidence_Evidence(t *testing.T) {
+	// -l 链接标志的可信度低于包管理清单
+	makeDeps := []models.Dependency{{Name: "z", Type: "library"}}
+	setConfidence(MakeExtractorType, makeDeps)
+	vcpkgDeps := []models.Dependency{{Name: "zlib", Type: "library"}}
+	setConfidence(VcpkgExtractorType, vcpkgDeps)
+	assert.Less(t, makeDeps[0].Confidence, vcpkgDeps[0].Confidence)
+	linkDeps := []models.Dependency{{Name: "ssl", Type: "library"}}
+	setConfidence(CompileCommandsExtractorType, linkDeps)
+	assert.Equal(t, "-l linker flag in compile_commands.json", linkDeps[0].ConfidenceReason)
+
+	// 被多个源文件包含的头文件置信度更高, 但仍属于低置信度
+	includeDeps := []models.Dependency{
+		{Name: "zlib", Metadata: map[string]interface{}{"include_count": 1}},
+		{Name: "png", Metadata: map[string]interface{}{"include_count": 12}},
+	}
+	setConfidence(IncludeExtractorType, includeDeps)
+	assert.Equal(t, 0.3, includeDeps[0].Confidence)
+	assert.Equal(t, 0.45, includeDeps[1].Confidence)
+	assert.Equal(t, "inferred from #include directives in 12 files", includeDeps[1].ConfidenceReason)
+	assert.True(t, includeDeps[1].IsLowConfidence())
+
+	// 内容哈希命中的内置源码
+	vendored := []models.Dependency{{Name: "zlib", Metadata: map[string]interface{}{"version_source": "sha256"}}}
+	setConfidence(VendoredExtractorType, vendored)
+	assert.Equal(t, 0.95, vendored[0].Confidence)
+
+	conan := []models.Dependency{{Name: "fmt", Type: "library", ConfigFileType: "conaninfo.txt"}}
+	setConfidence(ConanExtractorType, conan)
+	assert.Equal(t, 0.95, conan[0].Confidence)
+
+	unknown := []models.Dependency{{Name: "x"}}
+	setConfidence(ExtractorType("unknown"), unknown)
+	assert.Equal(t, defaultConfidence.Score, unknown[0].Confidence)
+}
@@ -3,7 +3,6 @@ package extractor
 import (
 	"bufio"
 	"fmt"
-	"os"
 	"regexp"
 	"strings"
 
@@ -27,7 +26,7 @@ func NewControlExtractor(path string) *ControlExtractor {
 // Extract 提取Control依赖
 func (e *ControlExtractor) Extract() ([]models.Dependency, error) {
 	// 读取control文件
-	file, err := os.Open(e.FilePath)
+	file, err := e.open(e.FilePath)
 	if err != nil {
 		return nil, NewExtractorError(ControlExtractorType, e.FilePath, err.Error())
 	}
@@ -37,7 +36,7 @@ func (e *ControlExtractor) Extract() ([]models.Dependency, error) {
 	scanner := bufio.NewScanner(file)
 
 	// 正则表达式
-	dependsRe := regexp.MustCompile(`^(?:Build-)?Depends(?:-Indep)?:\s*(.+)`)
+	dependsRe := regexp.MustCompile(`^(Build-)?Depends(?:-Indep|-Arch)?:\s*(.+)`)
 	preDepRe := regexp.MustCompile(`^Pre-Depends:\s*(.+)`)
 	recommendsRe := regexp.MustCompile(`^Recommends:\s*(.+)`)
 	suggestsRe := regexp.MustCompile(`^Suggests:\s*(.+)`)
@@ -49,6 +48,7 @@ func (e *ControlExtractor) Extract() ([]models.Dependency, error) {
 
 	var currentPackage string
 	var continuationLine string
+	var currentScope string // 当前依赖字段对应的依赖范围
 
 	for scanner.Scan() {
 		line := scanner.Text()
@@ -60,7 +60,7 @@ func (e *ControlExtractor) Extract() ([]models.Dependency, error) {
 		} else {
 			if continuationLine != "" {
 				// 处理前一个继续行
-				e.processDependencyLine(continuationLine, currentPackage, &deps)
+				e.processDependencyLine(continuationLine, currentPackage, currentScope, &deps)
 				continuationLine = ""
 			}
 		}
@@ -71,15 +71,19 @@ func (e *ControlExtractor) Extract() ([]models.Dependency, error) {
 			continue
 		}
 
-		// 提取依赖关系
-		if matches := dependsRe.FindStringSubmatch(line); len(matches) > 1 {
-			e.processDependencyLine(matches[1], currentPackage, &deps)
+		// 提取依赖关系, Build-Depends只在构建源码包时需要
+		currentScope = models.ScopeRuntime
+		if matches := dependsRe.FindStringSubmatch(line); len(matches) > 2 {
+			if matches[1] != "" {
+				currentScope = models.ScopeBuild
+			}
+			e.processDependencyLine(matches[2], currentPackage, currentScope, &deps)
 			continue
 		}
 
 		// 提取预依赖
 		if matches := preDepRe.FindStringSubmatch(line); len(matches) > 1 {
-			e.processDependencyLine(matches[1], currentPackage, &deps)
+			e.processDependencyLine(matches[1], currentPackage, currentScope, &deps)
 			continue
 		}
 
@@ -128,18 +132,19 @@ func (e *ControlExtractor) Extract() ([]models.Dependency, error) {
 
 	// 处理最后一个继续行
 	if continuationLine != "" {
-		e.processDependencyLine(continuationLine, currentPackage, &deps)
+		e.processDependencyLine(continuationLine, currentPackage, currentScope, &deps)
 	}
 
 	if err := scanner.Err(); err != nil {
 		return nil, NewExtractorError(ControlExtractorType, e.FilePath, err.Error())
 	}
 
+	e.annotate(ControlExtractorType, deps)
 	return deps, nil
 }
 
 // processDependencyLine 处理依赖行
-func (e *ControlExtractor) processDependencyLine(line, currentPackage string, deps *[]models.Dependency) {
+func (e *ControlExtractor) processDependencyLine(line, currentPackage, scope string, deps *[]models.Dependency) {
 	// 分割依赖项
 	items := strings.Split(line, ",")
 	for _, item := range items {
@@ -167,6 +172,7 @@ func (e *ControlExtractor) processDependencyLine(line, currentPackage string, de
 		dep.ConfigFile = e.FilePath
 		dep.ConfigFileType = "control"
 		dep.Required = true
+		dep.Scope = scope
 
 		if operator != "" && version != "" {
 			dep.Constraints = append(dep.Constraints, models.VersionConstrain{
@@ -0,0 +1,449 @@
+package extractor
+
+import (
+	"debug/elf"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/lkpsg/ccscanner/pkg/models"
+)
+
+// ElfExtractor ELF二进制依赖提取器
+//
+// 分析构建产物(共享库和可执行文件)中的 DT_NEEDED、SONAME、RPATH/RUNPATH
+// 以及带版本的符号需求(如 GLIBC_2.17、OPENSSL_1_1_0), 报告为运行时依赖。
+type ElfExtractor struct {
+	BaseExtractor
+	treeFilter
+	config ExtractorConfig
+}
+
+// NewElfExtractor 创建ELF提取器, path为构建输出目录或单个二进制文件
+func NewElfExtractor(path string) *ElfExtractor {
+	return &ElfExtractor{
+		BaseExtractor: NewBaseExtractor(path),
+		config:        DefaultConfig,
+	}
+}
+
+// ElfInfo 单个ELF文件的动态链接信息
+type ElfInfo struct {
+	Path           string              // 文件路径
+	Type           string              // executable 或 shared-library
+	Soname         string              // DT_SONAME
+	Needed         []string            // DT_NEEDED
+	RPath          []string            // DT_RPATH
+	RunPath        []string            // DT_RUNPATH
+	SymbolVersions map[string][]string // 依赖库 → 需要的符号版本
+}
+
+// elfRuntimeLibraries 系统运行时库的SONAME前缀到库名称的映射
+var elfRuntimeLibraries = map[string]string{
+	"libc":             "glibc",
+	"libm":             "glibc",
+	"libdl":            "glibc",
+	"librt":            "glibc",
+	"libpthread":       "glibc",
+	"libutil":          "glibc",
+	"libresolv":        "glibc",
+	"ld-linux":         "glibc",
+	"ld-linux-x86-64":  "glibc",
+	"ld-linux-aarch64": "glibc",
+	"libstdc++":        "libstdc++",
+	"libgcc_s":         "libgcc",
+	"libatomic":        "libatomic",
+	"libc++":           "libc++",
+	"libc++abi":        "libc++",
+}
+
+// symbolVersionRe 符号版本中的版本号部分(GLIBC_2.17 → 2.17, OPENSSL_1_1_0 → 1.1.0)
+var symbolVersionRe = regexp.MustCompile(`^([A-Za-z][A-Za-z0-9]*?)_(\d+(?:[._]\d+)*[a-z]?)$`)
+
+// Extract 分析二进制文件并提取运行时依赖
+func (e *ElfExtractor) Extract() ([]models.Dependency, error) {
+	root := e.FilePath
+	info, err := e.stat(root)
+	if err != nil {
+		return nil, NewExtractorError(ElfExtractorType, root, err.Error())
+	}
+
+	var binaries []*ElfInfo
+	if !info.IsDir() {
+		elfInfo, err := readElfInfo(e.fsys, root)
+		if err != nil {
+			return nil, NewExtractorError(ElfExtractorType, root, err.Error())
+		}
+		binaries = append(binaries, elfInfo)
+		root = filepath.Dir(root)
+	} else {
+		err = walkFS(e.fsys, root, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return nil
+			}
+			if e.skip(root, path, info) {
+				if info.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if info.IsDir() {
+				if path != root && strings.HasPrefix(info.Name(), ".") {
+					return filepath.SkipDir
+				}
+				if e.config.MaxDepth > 0 && pathDepth(root, path) > e.config.MaxDepth {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !maybeElfFile(info) || !isElfFile(e.fsys, path) {
+				return nil
+			}
+			if elfInfo, err := readElfInfo(e.fsys, path); err == nil {
+				binaries = append(binaries, elfInfo)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, NewExtractorError(ElfExtractorType, root, err.Error())
+		}
+	}
+
+	// 构建输出中自身提供的共享库不作为外部依赖
+	provided := make(map[string]string)
+	for _, bin := range binaries {
+		if bin.Soname != "" {
+			provided[bin.Soname] = bin.Path
+		}
+		provided[filepath.Base(bin.Path)] = bin.Path
+	}
+
+	type runtimeUsage struct {
+		sonames  map[string]bool
+		versions map[string]bool
+		users    map[string]bool
+		paths    map[string]bool
+		resolved map[string]bool
+	}
+	usages := make(map[string]*runtimeUsage)
+
+	for _, bin := range binaries {
+		rel, err := filepath.Rel(root, bin.Path)
+		if err != nil {
+			rel = bin.Path
+		}
+		rel = filepath.ToSlash(rel)
+		searchPaths := expandOrigin(append(append([]string(nil), bin.RPath...), bin.RunPath...), filepath.Dir(bin.Path))
+
+		for _, needed := range bin.Needed {
+			if _, ok := provided[needed]; ok {
+				continue
+			}
+			name := elfLibraryName(needed)
+			usage, ok := usages[name]
+			if !ok {
+				usage = &runtimeUsage{
+					sonames:  make(map[string]bool),
+					versions: make(map[string]bool),
+					users:    make(map[string]bool),
+					paths:    make(map[string]bool),
+					resolved: make(map[string]bool),
+				}
+				usages[name] = usage
+			}
+			usage.sonames[needed] = true
+			usage.users[rel] = true
+			for _, v := range bin.SymbolVersions[needed] {
+				usage.versions[v] = true
+			}
+			for _, dir := range searchPaths {
+				usage.paths[dir] = true
+				// 从虚拟文件系统读取时, 绝对搜索路径指向运行环境而不是被扫描的源码树
+				if e.fsys != nil && filepath.IsAbs(dir) {
+					continue
+				}
+				if candidate := filepath.Join(dir, needed); isRegularFile(e.fsys, candidate) {
+					usage.resolved[candidate] = true
+				}
+			}
+		}
+	}
+
+	names := make([]string, 0, len(usages))
+	for name := range usages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	deps := make([]models.Dependency, 0, len(names))
+	for _, name := range names {
+		usage := usages[name]
+		versions := sortedKeys(usage.versions)
+
+		dep := models.NewDependency(name)
+		dep.Type = "runtime"
+		dep.Required = true
+		dep.DetectedBy = "ElfExtractor"
+		dep.ConfigFile = root
+		dep.ConfigFileType = "elf"
+		dep.Metadata = map[string]interface{}{
+			"needed":      sortedKeys(usage.sonames),
+			"required_by": sortedKeys(usage.users),
+		}
+		if len(versions) > 0 {
+			dep.Metadata["symbol_versions"] = versions
+			if min := minimumSymbolVersion(versions); min != "" {
+				dep.Constraints = append(dep.Constraints, models.VersionConstrain{Operator: ">=", Version: min})
+			}
+		}
+		if len(usage.paths) > 0 {
+			dep.Metadata["search_paths"] = sortedKeys(usage.paths)
+		}
+		if len(usage.resolved) > 0 {
+			dep.Metadata["resolved_paths"] = sortedKeys(usage.resolved)
+		}
+		deps = append(deps, *dep)
+	}
+
+	e.annotate(ElfExtractorType, deps)
+	return deps, nil
+}
+
+// ReadElfInfo 读取ELF文件的动态链接信息
+func ReadElfInfo(path string) (*ElfInfo, error) {
+	return readElfInfo(nil, path)
+}
+
+// readElfInfo 从fsys读取ELF文件的动态链接信息, fsys为nil时读取本地磁盘
+func readElfInfo(fsys fs.FS, path string) (*ElfInfo, error) {
+	file, err := openRandomAccess(fsys, path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	f, err := elf.NewFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &ElfInfo{
+		Path:           path,
+		Type:           "executable",
+		SymbolVersions: make(map[string][]string),
+	}
+	if f.Type == elf.ET_DYN {
+		info.Type = "shared-library"
+	}
+
+	if needed, err := f.DynString(elf.DT_NEEDED); err == nil {
+		info.Needed = needed
+	}
+	if soname, err := f.DynString(elf.DT_SONAME); err == nil && len(soname) > 0 {
+		info.Soname = soname[0]
+	}
+	if rpath, err := f.DynString(elf.DT_RPATH); err == nil {
+		info.RPath = splitSearchPath(rpath)
+	}
+	if runpath, err := f.DynString(elf.DT_RUNPATH); err == nil {
+		info.RunPath = splitSearchPath(runpath)
+	}
+
+	// 位置无关可执行文件(PIE)同样是ET_DYN, 以是否有SONAME和入口解释器区分
+	if info.Type == "shared-library" && info.Soname == "" && hasInterpreter(f) {
+		info.Type = "executable"
+	}
+
+	if symbols, err := f.ImportedSymbols(); err == nil {
+		seen := make(map[string]bool)
+		for _, sym := range symbols {
+			if sym.Version == "" || sym.Library == "" {
+				continue
+			}
+			key := sym.Library + "\x00" + sym.Version
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			info.SymbolVersions[sym.Library] = append(info.SymbolVersions[sym.Library], sym.Version)
+		}
+		for lib := range info.SymbolVersions {
+			sort.Strings(info.SymbolVersions[lib])
+		}
+	}
+
+	return info, nil
+}
+
+// hasInterpreter 判断ELF文件是否有PT_INTERP段
+func hasInterpreter(f *elf.File) bool {
+	for _, prog := range f.Progs {
+		if prog.Type == elf.PT_INTERP {
+			return true
+		}
+	}
+	return false
+}
+
+// splitSearchPath 拆分以冒号分隔的RPATH/RUNPATH
+func splitSearchPath(values []string) []string {
+	var paths []string
+	for _, value := range values {
+		for _, p := range strings.Split(value, ":") {
+			if p != "" {
+				paths = append(paths, p)
+			}
+		}
+	}
+	return paths
+}
+
+// expandOrigin 展开搜索路径中的 $ORIGIN
+func expandOrigin(paths []string, origin string) []string {
+	expanded := make([]string, 0, len(paths))
+	for _, p := range paths {
+		p = strings.ReplaceAll(p, "${ORIGIN}", origin)
+		p = strings.ReplaceAll(p, "$ORIGIN", origin)
+		expanded = append(expanded, filepath.Clean(p))
+	}
+	return expanded
+}
+
+// maybeElfFile 根据文件名和权限快速判断是否可能为ELF文件
+func maybeElfFile(info os.FileInfo) bool {
+	if !info.Mode().IsRegular() || info.Size() < 64 {
+		return false
+	}
+	name := info.Name()
+	return info.Mode()&0111 != 0 || strings.HasSuffix(name, ".so") || strings.Contains(name, ".so.")
+}
+
+// isElfFile 检查文件头是否为ELF魔数
+func isElfFile(fsys fs.FS, path string) bool {
+	file, err := openFS(fsys, path)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	magic := make([]byte, 4)
+	if _, err := file.Read(magic); err != nil {
+		return false
+	}
+	return string(magic) == elf.ELFMAG
+}
+
+// elfLibraryName 将SONAME映射为库名称(libssl.so.1.1 → openssl, libc.so.6 → glibc)
+func elfLibraryName(soname string) string {
+	base := soname
+	if i := strings.Index(base, ".so"); i > 0 {
+		base = base[:i]
+	}
+	if name, ok := elfRuntimeLibraries[base]; ok {
+		return name
+	}
+	if name := linkLibraryName(soname); name != "" {
+		return name
+	}
+	return base
+}
+
+// minimumSymbolVersion 根据符号版本推断最低版本要求, 取最高的版本号
+// (如 GLIBC_2.2.5、GLIBC_2.34 → 2.34)。版本前缀不一致时(如 GLIBCXX 与 CXXABI)无法比较, 返回空。
+func minimumSymbolVersion(versions []string) string {
+	best, family := "", ""
+	for _, v := range versions {
+		m := symbolVersionRe.FindStringSubmatch(v)
+		if m == nil {
+			continue
+		}
+		if family != "" && m[1] != family {
+			return ""
+		}
+		family = m[1]
+		version := strings.ReplaceAll(m[2], "_", ".")
+		if best == "" || compareDottedVersions(version, best) > 0 {
+			best = version
+		}
+	}
+	return best
+}
+
+// compareDottedVersions 比较点分版本号
+func compareDottedVersions(a, b string) int {
+	pa := strings.Split(a, ".")
+	pb := strings.Split(b, ".")
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		var x, y string
+		if i < len(pa) {
+			x = pa[i]
+		}
+		if i < len(pb) {
+			y = pb[i]
+		}
+		if x == y {
+			continue
+		}
+		// 数字按长度优先比较, 兼容 1.1.1k 之类的后缀
+		nx := strings.TrimRightFunc(x, isNotDigit)
+		ny := strings.TrimRightFunc(y, isNotDigit)
+		if len(nx) != len(ny) {
+			if len(nx) < len(ny) {
+				return -1
+			}
+			return 1
+		}
+		if x < y {
+			return -1
+		}
+		return 1
+	}
+	return 0
+}
+
+// isNotDigit 判断字符是否不是数字
+func isNotDigit(r rune) bool {
+	return r < '0' || r > '9'
+}
+
+// ElfExtractorFactory ELF提取器工厂
+type ElfExtractorFactory struct{}
+
+// CreateExtractor 创建ELF提取器
+func (f *ElfExtractorFactory) CreateExtractor(path string) Extractor {
+	return NewElfExtractor(path)
+}
+
+func init() {
+	// 注册ELF提取器
+	RegisterExtractor(ElfExtractorType, &ElfExtractorFactory{})
+}
+
+/*
+使用示例:
+
+1. 创建提取器(参数为构建输出目录或单个二进制文件):
+extractor := NewElfExtractor("/path/to/build/bin")
+
+2. 提取运行时依赖:
+deps, err := extractor.Extract()
+if err != nil {
+    log.Printf("Failed to analyze binaries: %v\n", err)
+    return
+}
+
+3. 处理依赖信息:
+for _, dep := range deps {
+    fmt.Printf("%s needed by %v (symbol versions: %v)\n",
+        dep.Name, dep.Metadata["required_by"], dep.Metadata["symbol_versions"])
+}
+
+4. 读取单个文件的动态链接信息:
+info, err := ReadElfInfo("/path/to/libfoo.so")
+if err == nil {
+    fmt.Printf("SONAME=%s NEEDED=%v RUNPATH=%v\n", info.Soname, info.Needed, info.RunPath)
+}
+*/
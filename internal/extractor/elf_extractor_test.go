@@ -0,0 +1,90 @@
+package extractor
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// buildC 使用系统C编译器编译测试程序, 没有编译器时跳过测试
+func buildC(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cc, err := exec.LookPath("cc")
+	if err != nil {
+		t.Skip("C compiler not available")
+	}
+	cmd := exec.Command(cc, args...)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Skipf("failed to build test binary: %v\n%s", err, out)
+	}
+}
+
+func TestElfExtractor_Extract(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"src/helper.c": "int helper(int x) { return x * 2; }\n",
+		"src/main.c": "#include <stdio.h>\n#include <math.h>\nint helper(int);\n" +
+			"int main(int argc, char **argv) { printf(\"%f %d\\n\", sqrt((double)argc), helper(argc)); return 0; }\n",
+	})
+	out := filepath.Join(root, "out")
+	require.NoError(t, os.MkdirAll(filepath.Join(out, "lib"), 0755))
+
+	buildC(t, root, "-shared", "-fPIC", "-Wl,-soname,libhelper.so.1", "-o", "out/lib/libhelper.so.1", "src/helper.c")
+	buildC(t, root, "-o", "out/app", "src/main.c", "-Lout/lib", "-l:libhelper.so.1", "-lm", "-Wl,-rpath,$ORIGIN/lib")
+
+	info, err := ReadElfInfo(filepath.Join(out, "lib", "libhelper.so.1"))
+	require.NoError(t, err)
+	assert.Equal(t, "libhelper.so.1", info.Soname)
+	assert.Equal(t, "shared-library", info.Type)
+
+	app, err := ReadElfInfo(filepath.Join(out, "app"))
+	require.NoError(t, err)
+	assert.Equal(t, "executable", app.Type)
+	assert.Contains(t, app.Needed, "libhelper.so.1")
+	assert.Equal(t, []string{"$ORIGIN/lib"}, append(app.RPath, app.RunPath...))
+
+	deps, err := NewElfExtractor(out).Extract()
+	require.NoError(t, err)
+
+	byName := make(map[string]int)
+	for i, dep := range deps {
+		byName[dep.Name] = i
+		assert.Equal(t, "runtime", dep.Type)
+	}
+	// 构建输出中自带的 libhelper 不是外部依赖
+	assert.NotContains(t, byName, "helper")
+	require.Contains(t, byName, "glibc")
+
+	glibc := deps[byName["glibc"]]
+	assert.Contains(t, glibc.Metadata["needed"], "libc.so.6")
+	assert.Equal(t, []string{"app"}, glibc.Metadata["required_by"])
+	assert.Contains(t, glibc.Metadata["search_paths"], filepath.Join(out, "lib"))
+	require.NotEmpty(t, glibc.Constraints)
+	assert.Equal(t, ">=", glibc.Constraints[0].Operator)
+}
+
+func TestElfLibraryName(t *testing.T) {
+	tests := map[string]string{
+		"libc.so.6":                  "glibc",
+		"ld-linux-x86-64.so.2":       "glibc",
+		"libssl.so.1.1":              "openssl",
+		"libz.so.1":                  "zlib",
+		"libstdc++.so.6":             "libstdc++",
+		"libboost_filesystem.so.1.8": "boost-filesystem",
+		"libfoo.so":                  "foo",
+	}
+	for soname, want := range tests {
+		assert.Equal(t, want, elfLibraryName(soname), soname)
+	}
+}
+
+func TestMinimumSymbolVersion(t *testing.T) {
+	assert.Equal(t, "2.34", minimumSymbolVersion([]string{"GLIBC_2.2.5", "GLIBC_2.34", "GLIBC_2.4"}))
+	assert.Equal(t, "1.1.1", minimumSymbolVersion([]string{"OPENSSL_1_1_0", "OPENSSL_1_1_1"}))
+	assert.Empty(t, minimumSymbolVersion([]string{"GLIBCXX_3.4.21", "CXXABI_1.3"}))
+}
@@ -1,6 +1,8 @@
 package extractor
 
 import (
+	"io/fs"
+
 	"github.com/lkpsg/ccscanner/pkg/models"
 )
 
@@ -13,6 +15,8 @@ type Extractor interface {
 // BaseExtractor 基础提取器
 type BaseExtractor struct {
 	FilePath string // 文件路径
+
+	fsys fs.FS // 读取文件的文件系统(为nil时读取本地磁盘)
 }
 
 // NewBaseExtractor 创建基础提取器
@@ -26,15 +30,33 @@ func NewBaseExtractor(path string) BaseExtractor {
 type ExtractorType string
 
 const (
-	CMakeExtractorType     ExtractorType = "cmake"     // CMake提取器
-	MakeExtractorType      ExtractorType = "make"      // Make提取器
-	ConanExtractorType     ExtractorType = "conan"     // Conan提取器
-	VcpkgExtractorType     ExtractorType = "vcpkg"     // Vcpkg提取器
-	SubmoduleExtractorType ExtractorType = "submodule" // Git子模块提取器
-	MesonExtractorType     ExtractorType = "meson"     // Meson提取器
-	PkgConfigExtractorType ExtractorType = "pkgconfig" // PkgConfig提取器
-	AutoconfExtractorType  ExtractorType = "autoconf"  // Autoconf提取器
-	ControlExtractorType   ExtractorType = "control"   // Control提取器
+	CMakeExtractorType           ExtractorType = "cmake"            // CMake提取器
+	MakeExtractorType            ExtractorType = "make"             // Make提取器
+	ConanExtractorType           ExtractorType = "conan"            // Conan提取器
+	VcpkgExtractorType           ExtractorType = "vcpkg"            // Vcpkg提取器
+	SubmoduleExtractorType       ExtractorType = "submodule"        // Git子模块提取器
+	MesonExtractorType           ExtractorType = "meson"            // Meson提取器
+	PkgConfigExtractorType       ExtractorType = "pkgconfig"        // PkgConfig提取器
+	AutoconfExtractorType        ExtractorType = "autoconf"         // Autoconf提取器
+	ControlExtractorType         ExtractorType = "control"          // Control提取器
+	PkgbuildExtractorType        ExtractorType = "pkgbuild"         // PKGBUILD/APKBUILD提取器
+	NixExtractorType             ExtractorType = "nix"              // Nix提取器
+	VendoredExtractorType        ExtractorType = "vendored"         // 内置第三方源码检测器
+	VersionMacroExtractorType    ExtractorType = "version-macro"    // 头文件版本宏提取器
+	IncludeExtractorType         ExtractorType = "include"          // #include依赖推断提取器
+	CompileCommandsExtractorType ExtractorType = "compile-commands" // compile_commands.json提取器
+	CMakeCacheExtractorType      ExtractorType = "cmake-cache"      // CMakeCache.txt/File API提取器
+	ElfExtractorType             ExtractorType = "elf"              // ELF二进制提取器
+	SignatureExtractorType       ExtractorType = "signature"        // 静态链接库签名提取器
+	NPMExtractorType             ExtractorType = "npm"              // npm提取器
+	YarnExtractorType            ExtractorType = "yarn"             // Yarn提取器
+	MavenExtractorType           ExtractorType = "maven"            // Maven提取器
+	CargoExtractorType           ExtractorType = "cargo"            // Cargo提取器
+	BazelExtractorType           ExtractorType = "bazel"            // Bazel提取器
+	BuckExtractorType            ExtractorType = "buck"             // Buck提取器
+	GradleExtractorType          ExtractorType = "gradle"           // Gradle提取器
+	NinjaExtractorType           ExtractorType = "ninja"            // Ninja提取器
+	SConsExtractorType           ExtractorType = "scons"            // SCons提取器
 )
 
 // ExtractorFactory 提取器工厂
@@ -64,6 +86,7 @@ type ExtractorConfig struct {
 	// 通用配置
 	IgnoreComments bool     // 是否忽略注释
 	IgnoreTests    bool     // 是否忽略测试依赖
+	Scopes         []string // 只保留这些范围的依赖(为空表示全部)
 	ExcludeFiles   []string // 排除的文件
 	IncludeFiles   []string // 包含的文件
 	MaxDepth       int      // 最大递归深度
@@ -81,7 +104,8 @@ type ExtractorConfig struct {
 	VcpkgRoot string // Vcpkg根目录
 
 	// Git配置
-	GitBranch string // Git分支
+	GitBranch     string   // Git分支
+	GitMirrorDirs []string // 本地Git镜像目录(用于查找子模块仓库)
 }
 
 // DefaultConfig 默认配置
@@ -0,0 +1,181 @@
+package extractor
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/lkpsg/ccscanner/pkg/models"
+)
+
+// FSExtractor 可以从虚拟文件系统(如压缩包、git提交)读取文件的提取器
+type FSExtractor interface {
+	Extractor
+	// SetFS 设置读取文件的文件系统, 设置后FilePath及提取器引用的其他路径均为fsys中的路径
+	SetFS(fsys fs.FS)
+}
+
+// SetFS 设置读取文件的文件系统, 为nil时读取本地磁盘
+func (b *BaseExtractor) SetFS(fsys fs.FS) {
+	b.fsys = fsys
+}
+
+// SkipFunc 判断遍历源码树时是否跳过路径, rel为相对遍历根目录的路径(以/分隔)
+type SkipFunc func(rel string, d fs.DirEntry) bool
+
+// TreeExtractor 遍历整个源码树的提取器, 扫描器通过SetSkip使其与目录遍历使用相同的过滤规则
+type TreeExtractor interface {
+	Extractor
+	SetSkip(skip SkipFunc)
+}
+
+// treeFilter 源码树提取器的路径过滤, 未设置时只跳过隐藏目录和超过最大深度的目录
+type treeFilter struct {
+	skipFn SkipFunc
+}
+
+// SetSkip 设置遍历时的跳过判断
+func (t *treeFilter) SetSkip(skip SkipFunc) {
+	t.skipFn = skip
+}
+
+// skip 判断walkFS回调中的路径是否跳过, 遍历根目录本身不跳过
+func (t *treeFilter) skip(root, path string, info os.FileInfo) bool {
+	if t.skipFn == nil {
+		return false
+	}
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == "." {
+		return false
+	}
+	return t.skipFn(filepath.ToSlash(rel), fs.FileInfoToDirEntry(info))
+}
+
+// open 打开文件
+func (b *BaseExtractor) open(name string) (fs.File, error) {
+	return openFS(b.fsys, name)
+}
+
+// readFile 读取文件内容
+func (b *BaseExtractor) readFile(name string) ([]byte, error) {
+	return readFileFS(b.fsys, name)
+}
+
+// stat 获取文件信息
+func (b *BaseExtractor) stat(name string) (fs.FileInfo, error) {
+	return statFS(b.fsys, name)
+}
+
+// annotate 补全提取器输出依赖的purl、来源位置、置信度和依赖范围
+func (b *BaseExtractor) annotate(typ ExtractorType, deps []models.Dependency) {
+	setPackageURLs(typ, deps)
+	setProvenance(b.fsys, typ, b.FilePath, deps)
+	setConfidence(typ, deps)
+	setScope(typ, deps)
+}
+
+// openFS 从fsys打开文件, fsys为nil时读取本地磁盘
+func openFS(fsys fs.FS, name string) (fs.File, error) {
+	if fsys == nil {
+		return os.Open(name)
+	}
+	return fsys.Open(fsPath(name))
+}
+
+// readFileFS 从fsys读取文件, fsys为nil时读取本地磁盘
+func readFileFS(fsys fs.FS, name string) ([]byte, error) {
+	if fsys == nil {
+		return os.ReadFile(name)
+	}
+	return fs.ReadFile(fsys, fsPath(name))
+}
+
+// statFS 从fsys获取文件信息, fsys为nil时读取本地磁盘
+func statFS(fsys fs.FS, name string) (fs.FileInfo, error) {
+	if fsys == nil {
+		return os.Stat(name)
+	}
+	return fs.Stat(fsys, fsPath(name))
+}
+
+// globFS 在fsys中查找匹配模式的文件, 返回的路径与pattern同样以name的形式拼接
+func globFS(fsys fs.FS, pattern string) ([]string, error) {
+	if fsys == nil {
+		return filepath.Glob(pattern)
+	}
+	return fs.Glob(fsys, fsPath(pattern))
+}
+
+// walkFS 遍历root下的文件和目录, fsys为nil时遍历本地磁盘, 否则遍历fsys(根目录为".")
+// 与filepath.Walk相同, 回调中的路径以root为前缀
+func walkFS(fsys fs.FS, root string, fn filepath.WalkFunc) error {
+	if fsys == nil {
+		return filepath.Walk(root, fn)
+	}
+	return fs.WalkDir(fsys, fsPath(root), func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return fn(name, nil, err)
+		}
+		info, err := d.Info()
+		if err != nil {
+			return fn(name, nil, err)
+		}
+		return fn(name, info, nil)
+	})
+}
+
+// randomAccessFile 支持随机读取的文件, 用于解析ELF和ar归档
+type randomAccessFile interface {
+	io.ReadSeeker
+	io.ReaderAt
+	io.Closer
+}
+
+// memFile 读入内存的文件
+type memFile struct {
+	*bytes.Reader
+}
+
+func (memFile) Close() error { return nil }
+
+// openRandomAccess 打开文件用于随机读取, fsys中的文件不支持随机读取时读入内存
+func openRandomAccess(fsys fs.FS, name string) (randomAccessFile, error) {
+	file, err := openFS(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	if f, ok := file.(randomAccessFile); ok {
+		return f, nil
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+	return memFile{bytes.NewReader(data)}, nil
+}
+
+// fsPath 将提取器拼接出的路径转换为fs.FS使用的路径(以/分隔、无前导/)
+// 超出根目录的路径(如 ../x)保持无效, 由fs.FS返回错误
+func fsPath(name string) string {
+	name = path.Clean(filepath.ToSlash(name))
+	return strings.TrimPrefix(name, "/")
+}
+
+/*
+使用示例:
+
+fsys := os.DirFS("/path/to/unpacked")
+ext := NewCMakeExtractor("src/CMakeLists.txt")
+ext.SetFS(fsys)
+deps, err := ext.Extract()
+if err != nil {
+	log.Fatal(err)
+}
+fmt.Println(deps[0].Provenance.File) // src/CMakeLists.txt
+*/
@@ -0,0 +1,87 @@
+package extractor
+
+import (
+	"fmt"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"src/vcpkg.json":       {Data: []byte(`{"name": "app", "dependencies": [{"name": "zlib"}]}`)},
+		"src/CMakeLists.txt":   {Data: []byte("include(cmake/deps.cmake)\n")},
+		"src/cmake/deps.cmake": {Data: []byte("find_package(OpenSSL REQUIRED)\n")},
+		"nix/flake.nix":        {Data: []byte("{ outputs = { ... }: { buildInputs = [ pkgs.zlib ]; }; }\n")},
+		"nix/flake.lock":       {Data: []byte(`{"nodes": {"root": {"inputs": {"nixpkgs": "nixpkgs"}}, "nixpkgs": {"locked": {"type": "github", "owner": "NixOS", "repo": "nixpkgs", "rev": "abc123"}}}, "root": "root", "version": 7}`)},
+		"unrelated/vcpkg.json": {Data: []byte("{")},
+	}
+
+	vcpkg := NewVcpkgExtractor("src/vcpkg.json")
+	vcpkg.SetFS(fsys)
+	deps, err := vcpkg.Extract()
+	require.NoError(t, err)
+	require.Len(t, deps, 1)
+	assert.Equal(t, "zlib", deps[0].Name)
+	require.NotNil(t, deps[0].Provenance)
+	assert.Equal(t, "src/vcpkg.json", deps[0].Provenance.File)
+	assert.Equal(t, 1, deps[0].Provenance.StartLine)
+
+	// include的文件同样从fsys读取
+	cmake := NewCMakeExtractor("src/CMakeLists.txt")
+	cmake.SetFS(fsys)
+	deps, err = cmake.Extract()
+	require.NoError(t, err)
+	files := make(map[string]string)
+	for _, dep := range deps {
+		files[dep.Name] = dep.ConfigFile
+	}
+	assert.Equal(t, "src/cmake/deps.cmake", files["OpenSSL"])
+
+	// 同目录的flake.lock从fsys读取
+	nix := NewNixExtractor("nix/flake.nix")
+	nix.SetFS(fsys)
+	deps, err = nix.Extract()
+	require.NoError(t, err)
+	require.Len(t, deps, 1)
+	assert.Equal(t, "abc123", deps[0].Commit)
+
+	// 文件不在fsys中时不会读取本地磁盘
+	missing := NewConanExtractor("conanfile.txt")
+	missing.SetFS(fsys)
+	_, err = missing.Extract()
+	assert.Error(t, err)
+}
+
+// gitlinkMapFS 提供子模块固定提交的测试文件系统
+type gitlinkMapFS struct {
+	fstest.MapFS
+	gitlinks map[string]string
+}
+
+func (f gitlinkMapFS) Gitlink(name string) (string, error) {
+	if commit, ok := f.gitlinks[name]; ok {
+		return commit, nil
+	}
+	return "", fmt.Errorf("no gitlink found for %s", name)
+}
+
+func TestSetFS_Gitlink(t *testing.T) {
+	fsys := gitlinkMapFS{
+		MapFS: fstest.MapFS{
+			".gitmodules": {Data: []byte("[submodule \"zlib\"]\n\tpath = third_party/zlib\n\turl = https://github.com/madler/zlib.git\n[submodule \"fmt\"]\n\tpath = third_party/fmt\n\turl = https://github.com/fmtlib/fmt.git\n")},
+		},
+		gitlinks: map[string]string{"third_party/zlib": "0123456789abcdef0123456789abcdef01234567"},
+	}
+
+	ext := NewSubmoduleExtractor(".gitmodules")
+	ext.SetFS(fsys)
+	deps, err := ext.Extract()
+	require.NoError(t, err)
+	require.Len(t, deps, 2)
+	assert.Equal(t, "0123456789abcdef0123456789abcdef01234567", deps[0].Commit)
+	assert.Empty(t, deps[1].Commit)
+	assert.Contains(t, deps[1].Description, "no gitlink found for third_party/fmt")
+}
@@ -78,10 +78,9 @@ func (e *GradleExtractor) Extract(projectPath string, filePath string) ([]models
 		// 提取 native 依赖
 		if matches := nativeDependencyRegex.FindStringSubmatch(line); len(matches) > 1 {
 			dep := models.Dependency{
-				Name:     matches[1],
-				Type:     "gradle_native",
-				FilePath: filePath,
-				Line:     lineNum,
+				Name:       matches[1],
+				Type:       "gradle_native",
+				Provenance: lineProvenance(GradleExtractorType, filePath, lineNum, line, nil, "gradle.native"),
 				Parent:   currentComponent,
 			}
 			dependencies = append(dependencies, dep)
@@ -90,10 +89,9 @@ func (e *GradleExtractor) Extract(projectPath string, filePath string) ([]models
 		// 提取插件依赖
 		if matches := pluginDependencyRegex.FindStringSubmatch(line); len(matches) > 2 {
 			dep := models.Dependency{
-				Name:     matches[1],
-				Type:     "gradle_plugin",
-				FilePath: filePath,
-				Line:     lineNum,
+				Name:       matches[1],
+				Type:       "gradle_plugin",
+				Provenance: lineProvenance(GradleExtractorType, filePath, lineNum, line, nil, "gradle.plugin"),
 				Version:  matches[2],
 			}
 			dependencies = append(dependencies, dep)
@@ -102,10 +100,9 @@ func (e *GradleExtractor) Extract(projectPath string, filePath string) ([]models
 		// 提取项目依赖
 		if matches := projectDependencyRegex.FindStringSubmatch(line); len(matches) > 1 {
 			dep := models.Dependency{
-				Name:     matches[1],
-				Type:     "gradle_project",
-				FilePath: filePath,
-				Line:     lineNum,
+				Name:       matches[1],
+				Type:       "gradle_project",
+				Provenance: lineProvenance(GradleExtractorType, filePath, lineNum, line, nil, "gradle.project"),
 			}
 			dependencies = append(dependencies, dep)
 		}
@@ -113,10 +110,9 @@ func (e *GradleExtractor) Extract(projectPath string, filePath string) ([]models
 		// 提取包含目录
 		if matches := includeDirRegex.FindStringSubmatch(line); len(matches) > 1 {
 			dep := models.Dependency{
-				Name:     matches[1],
-				Type:     "gradle_include_dir",
-				FilePath: filePath,
-				Line:     lineNum,
+				Name:       matches[1],
+				Type:       "gradle_include_dir",
+				Provenance: lineProvenance(GradleExtractorType, filePath, lineNum, line, nil, "gradle.include_dir"),
 				Parent:   currentComponent,
 			}
 			dependencies = append(dependencies, dep)
@@ -136,6 +132,8 @@ func (e *GradleExtractor) Extract(projectPath string, filePath string) ([]models
 		dependencies = append(dependencies, subprojects...)
 	}
 
+	setConfidence(GradleExtractorType, dependencies)
+	setScope(GradleExtractorType, dependencies)
 	return dependencies, nil
 }
 
@@ -164,10 +162,9 @@ func (e *GradleExtractor) extractSubprojects(filePath string) ([]models.Dependen
 
 		if matches := includeRegex.FindStringSubmatch(line); len(matches) > 1 {
 			dep := models.Dependency{
-				Name:     matches[1],
-				Type:     "gradle_subproject",
-				FilePath: filePath,
-				Line:     lineNum,
+				Name:       matches[1],
+				Type:       "gradle_subproject",
+				Provenance: lineProvenance(GradleExtractorType, filePath, lineNum, line, nil, "gradle.subproject"),
 			}
 			dependencies = append(dependencies, dep)
 		}
@@ -177,6 +174,8 @@ func (e *GradleExtractor) extractSubprojects(filePath string) ([]models.Dependen
 		return nil, fmt.Errorf("error scanning settings file %s: %v", filePath, err)
 	}
 
+	setConfidence(GradleExtractorType, dependencies)
+	setScope(GradleExtractorType, dependencies)
 	return dependencies, nil
 }
 
@@ -109,7 +109,7 @@ components.native {
 		assert.True(t, ok, "Unexpected dependency: %s", dep.Name)
 		assert.Equal(t, expected.Type, dep.Type)
 		assert.Equal(t, expected.Parent, dep.Parent)
-		assert.Equal(t, testFile, dep.FilePath)
+		assert.Equal(t, testFile, dep.Provenance.File)
 	}
 }
 
@@ -148,7 +148,7 @@ include ':lib:network'
 	for i, dep := range deps {
 		assert.Equal(t, expectedSubprojects[i], dep.Name)
 		assert.Equal(t, "gradle_subproject", dep.Type)
-		assert.Equal(t, testFile, dep.FilePath)
+		assert.Equal(t, testFile, dep.Provenance.File)
 	}
 }
 
@@ -211,7 +211,7 @@ components.native {
 		assert.True(t, ok, "Unexpected dependency: %s", dep.Name)
 		assert.Equal(t, expected.Type, dep.Type)
 		assert.Equal(t, expected.Parent, dep.Parent)
-		assert.Equal(t, testFile, dep.FilePath)
+		assert.Equal(t, testFile, dep.Provenance.File)
 	}
 }
 
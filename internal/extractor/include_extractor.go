@@ -0,0 +1,302 @@
+package extractor
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/lkpsg/ccscanner/pkg/models"
+)
+
+// IncludeMapping 头文件路径到库的映射
+type IncludeMapping struct {
+	Pattern   string `json:"pattern"`             // 头文件路径, 以/结尾时按前缀匹配
+	Library   string `json:"library"`             // 库名称
+	Component bool   `json:"component,omitempty"` // 是否按下一级路径区分组件(如 boost/asio.hpp → boost-asio)
+}
+
+//go:embed data/include_map.json
+var defaultIncludeMappings []byte
+
+var (
+	includeMappings   []IncludeMapping
+	includeMappingsMu sync.RWMutex
+)
+
+// includeRe 匹配 #include <...>
+var includeRe = regexp.MustCompile(`(?m)^[ \t]*#[ \t]*include[ \t]*<([^>\n]+)>`)
+
+// cSourceExts C/C++源文件和头文件扩展名
+var cSourceExts = map[string]bool{
+	".c": true, ".cc": true, ".cpp": true, ".cxx": true, ".c++": true,
+	".h": true, ".hh": true, ".hpp": true, ".hxx": true, ".h++": true,
+	".ipp": true, ".inl": true, ".tcc": true, ".m": true, ".mm": true,
+}
+
+// maxIncludeEvidence 每个依赖最多记录的引用文件数
+const maxIncludeEvidence = 20
+
+func init() {
+	if err := json.Unmarshal(defaultIncludeMappings, &includeMappings); err != nil {
+		panic(fmt.Sprintf("invalid include mapping data: %v", err))
+	}
+}
+
+// LoadIncludeMappings 从JSON文件加载额外的头文件映射,同名pattern会被替换
+func LoadIncludeMappings(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var mappings []IncludeMapping
+	if err := json.Unmarshal(data, &mappings); err != nil {
+		return fmt.Errorf("failed to parse include mapping file %s: %v", path, err)
+	}
+
+	includeMappingsMu.Lock()
+	defer includeMappingsMu.Unlock()
+	for _, mapping := range mappings {
+		replaced := false
+		for i := range includeMappings {
+			if includeMappings[i].Pattern == mapping.Pattern {
+				includeMappings[i] = mapping
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			includeMappings = append(includeMappings, mapping)
+		}
+	}
+	return nil
+}
+
+// IncludeExtractor 基于 #include 指令推断依赖的提取器
+type IncludeExtractor struct {
+	BaseExtractor
+	treeFilter
+	config ExtractorConfig
+}
+
+// NewIncludeExtractor 创建 #include 依赖推断提取器, path为要扫描的源码目录
+func NewIncludeExtractor(path string) *IncludeExtractor {
+	return &IncludeExtractor{
+		BaseExtractor: NewBaseExtractor(path),
+		config:        DefaultConfig,
+	}
+}
+
+// includeUsage 某个库的引用情况
+type includeUsage struct {
+	headers map[string]bool
+	files   map[string]bool
+}
+
+// Extract 收集源码中的 #include <...> 并映射为依赖
+func (e *IncludeExtractor) Extract() ([]models.Dependency, error) {
+	root := e.FilePath
+	if info, err := e.stat(root); err != nil {
+		return nil, NewExtractorError(IncludeExtractorType, root, err.Error())
+	} else if !info.IsDir() {
+		root = filepath.Dir(root)
+	}
+
+	mappings := sortedIncludeMappings()
+	usages := make(map[string]*includeUsage)
+	var order []string
+
+	err := walkFS(e.fsys, root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if e.skip(root, path, info) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.IsDir() {
+			if path != root && strings.HasPrefix(info.Name(), ".") {
+				return filepath.SkipDir
+			}
+			if e.config.MaxDepth > 0 && pathDepth(root, path) > e.config.MaxDepth {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !cSourceExts[strings.ToLower(filepath.Ext(path))] {
+			return nil
+		}
+
+		content, err := readFilePrefix(e.fsys, path, maxFingerprintBytes)
+		if err != nil {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			rel = path
+		}
+		for _, header := range parseIncludes(content) {
+			library := mapIncludeToLibrary(header, mappings)
+			if library == "" {
+				continue
+			}
+			usage, ok := usages[library]
+			if !ok {
+				usage = &includeUsage{headers: make(map[string]bool), files: make(map[string]bool)}
+				usages[library] = usage
+				order = append(order, library)
+			}
+			usage.headers[header] = true
+			usage.files[filepath.ToSlash(rel)] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, NewExtractorError(IncludeExtractorType, root, err.Error())
+	}
+
+	sort.Strings(order)
+	deps := make([]models.Dependency, 0, len(order))
+	for _, library := range order {
+		usage := usages[library]
+		files := sortedKeys(usage.files)
+
+		dep := models.NewDependency(library)
+		dep.Type = "library"
+		dep.Source = "include"
+		dep.Description = "Inferred from #include directives"
+		dep.DetectedBy = "IncludeExtractor"
+		dep.ConfigFile = root
+		dep.ConfigFileType = "source"
+		dep.Metadata = map[string]interface{}{
+			"headers":       sortedKeys(usage.headers),
+			"included_by":   truncateStrings(files, maxIncludeEvidence),
+			"include_count": len(files),
+			"inferred":      true,
+		}
+		deps = append(deps, *dep)
+	}
+
+	e.annotate(IncludeExtractorType, deps)
+	return deps, nil
+}
+
+// parseIncludes 提取 #include <...> 中的头文件路径
+func parseIncludes(content string) []string {
+	var headers []string
+	for _, match := range includeRe.FindAllStringSubmatch(content, -1) {
+		header := strings.TrimSpace(match[1])
+		if header != "" {
+			headers = append(headers, header)
+		}
+	}
+	return headers
+}
+
+// sortedIncludeMappings 返回按pattern长度降序排列的映射, 保证更具体的规则优先
+func sortedIncludeMappings() []IncludeMapping {
+	includeMappingsMu.RLock()
+	mappings := append([]IncludeMapping(nil), includeMappings...)
+	includeMappingsMu.RUnlock()
+
+	sort.SliceStable(mappings, func(i, j int) bool {
+		return len(mappings[i].Pattern) > len(mappings[j].Pattern)
+	})
+	return mappings
+}
+
+// mapIncludeToLibrary 将头文件路径映射为库名称, 未知头文件返回空
+func mapIncludeToLibrary(header string, mappings []IncludeMapping) string {
+	for _, mapping := range mappings {
+		if strings.HasSuffix(mapping.Pattern, "/") {
+			if !strings.HasPrefix(header, mapping.Pattern) {
+				continue
+			}
+		} else if header != mapping.Pattern {
+			continue
+		}
+
+		if !mapping.Component {
+			return mapping.Library
+		}
+		rest := strings.TrimPrefix(header, mapping.Pattern)
+		component := strings.SplitN(rest, "/", 2)[0]
+		component = strings.TrimSuffix(component, filepath.Ext(component))
+		if component == "" {
+			return mapping.Library
+		}
+		return mapping.Library + "-" + component
+	}
+	return ""
+}
+
+// sortedKeys 返回集合中排序后的元素
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// truncateStrings 截取前n个元素
+func truncateStrings(list []string, n int) []string {
+	if len(list) > n {
+		return list[:n]
+	}
+	return list
+}
+
+// IncludeExtractorFactory #include 依赖推断提取器工厂
+type IncludeExtractorFactory struct{}
+
+// CreateExtractor 创建 #include 依赖推断提取器
+func (f *IncludeExtractorFactory) CreateExtractor(path string) Extractor {
+	return NewIncludeExtractor(path)
+}
+
+func init() {
+	// 注册 #include 依赖推断提取器
+	RegisterExtractor(IncludeExtractorType, &IncludeExtractorFactory{})
+}
+
+/*
+使用示例:
+
+1. 创建提取器(参数为源码目录):
+extractor := NewIncludeExtractor("/path/to/project/src")
+
+2. 加载自定义映射(可选):
+if err := LoadIncludeMappings("include_map.json"); err != nil {
+    log.Printf("Failed to load include mappings: %v\n", err)
+}
+
+3. 提取依赖:
+deps, err := extractor.Extract()
+if err != nil {
+    log.Printf("Failed to infer dependencies: %v\n", err)
+    return
+}
+
+4. 处理依赖信息(推断结果的置信度较低, 见 dep.Confidence 和 dep.ConfidenceReason):
+for _, dep := range deps {
+    fmt.Printf("%s %.1f (included by %v)\n", dep.Name, dep.Confidence, dep.Metadata["included_by"])
+}
+
+映射文件格式:
+```json
+[
+  {"pattern": "openssl/", "library": "openssl"},
+  {"pattern": "zlib.h", "library": "zlib"},
+  {"pattern": "boost/", "library": "boost", "component": true}
+]
+```
+*/
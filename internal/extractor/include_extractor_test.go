@@ -0,0 +1,60 @@
+package extractor
+
+import (
+	"testing"
+
+	"github.com/lkpsg/ccscanner/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIncludeExtractor_Extract(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"src/net.cpp": "#include <openssl/ssl.h>\n#include <openssl/err.h>\n#include <curl/curl.h>\n" +
+			"#include <boost/asio.hpp>\n#include <boost/filesystem/path.hpp>\n#include <vector>\n#include \"local.h\"\n",
+		"src/util.c":       "#  include <zlib.h>\n#include <openssl/evp.h>\n#include <stdio.h>\n",
+		"src/proto.h":      "#include <google/protobuf/message.h>\n",
+		"docs/example.txt": "#include <sqlite3.h>\n",
+		".build/gen.c":     "#include <png.h>\n",
+		"include/local.h":  "#pragma once\n",
+		"src/unknown.hpp":  "#include <mycompany/internal.h>\n",
+	})
+
+	deps, err := NewIncludeExtractor(root).Extract()
+	require.NoError(t, err)
+
+	byName := make(map[string]int)
+	for i, dep := range deps {
+		byName[dep.Name] = i
+		assert.Equal(t, "include", dep.Source)
+		// 置信度只记录在类型化字段中
+		assert.NotContains(t, dep.Metadata, "confidence")
+		assert.Less(t, dep.Confidence, models.LowConfidence)
+	}
+	assert.Len(t, deps, 6)
+	for _, name := range []string{"openssl", "libcurl", "zlib", "boost-asio", "boost-filesystem", "protobuf"} {
+		assert.Contains(t, byName, name)
+	}
+
+	openssl := deps[byName["openssl"]]
+	assert.Equal(t, []string{"openssl/err.h", "openssl/evp.h", "openssl/ssl.h"}, openssl.Metadata["headers"])
+	assert.Equal(t, []string{"src/net.cpp", "src/util.c"}, openssl.Metadata["included_by"])
+	assert.Equal(t, 2, openssl.Metadata["include_count"])
+}
+
+func TestMapIncludeToLibrary(t *testing.T) {
+	mappings := sortedIncludeMappings()
+	tests := map[string]string{
+		"openssl/ssl.h":            "openssl",
+		"zlib.h":                   "zlib",
+		"minizip/zlib.h":           "",
+		"google/protobuf/any.pb.h": "protobuf",
+		"boost/shared_ptr.hpp":     "boost-shared_ptr",
+		"gtest/gtest.h":            "googletest",
+		"string":                   "",
+	}
+	for header, want := range tests {
+		assert.Equal(t, want, mapIncludeToLibrary(header, mappings), header)
+	}
+}
@@ -3,7 +3,6 @@ package extractor
 import (
 	"bufio"
 	"fmt"
-	"os"
 	"regexp"
 	"strings"
 
@@ -27,7 +26,7 @@ func NewMakeExtractor(path string) *MakeExtractor {
 // Extract 提取Make依赖
 func (e *MakeExtractor) Extract() ([]models.Dependency, error) {
 	// 读取Makefile
-	file, err := os.Open(e.FilePath)
+	file, err := e.open(e.FilePath)
 	if err != nil {
 		return nil, NewExtractorError(MakeExtractorType, e.FilePath, err.Error())
 	}
@@ -156,6 +155,7 @@ func (e *MakeExtractor) Extract() ([]models.Dependency, error) {
 		return nil, NewExtractorError(MakeExtractorType, e.FilePath, err.Error())
 	}
 
+	e.annotate(MakeExtractorType, deps)
 	return deps, nil
 }
 
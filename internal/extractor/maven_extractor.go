@@ -100,6 +100,13 @@ func (e *MavenExtractor) Extract(dir string) ([]models.Dependency, error) {
 
 	e.logger.Info("Completed Maven dependency extraction",
 		zap.Int("total_deps", len(allDeps)))
+	locator := newLocator(nil)
+	for i := range allDeps {
+		allDeps[i].PURL = mavenPackageURL(allDeps[i].Name, allDeps[i].Version)
+		locator.fill(MavenExtractorType, sourceFile(allDeps[i].Source), &allDeps[i])
+		scoreConfidence(MavenExtractorType, &allDeps[i])
+		classifyScope(MavenExtractorType, &allDeps[i])
+	}
 	return allDeps, nil
 }
 
@@ -184,6 +191,12 @@ func convertMavenDependency(dep MavenDependency, source string) models.Dependenc
 		version = strings.TrimSuffix(strings.TrimPrefix(version, "${"), "}")
 	}
 
+	// 确定依赖范围, provided由运行环境提供, 只在编译时需要
+	scope := models.NormalizeScope(dep.Scope)
+	if dep.Optional && (scope == "" || scope == models.ScopeRuntime) {
+		scope = models.ScopeOptional
+	}
+
 	// 构建排除项列表
 	var conflicts []models.Dependency
 	for _, excl := range dep.Exclusions {
@@ -198,6 +211,7 @@ func convertMavenDependency(dep MavenDependency, source string) models.Dependenc
 		Version:     version,
 		Type:        depType,
 		Required:    !dep.Optional,
+		Scope:       scope,
 		BuildSystem: "maven",
 		Source:      source,
 		Conflicts:   conflicts,
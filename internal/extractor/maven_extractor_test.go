@@ -257,6 +257,7 @@ func TestMavenExtractor_ConvertMavenDependency(t *testing.T) {
 				Version:     "4.12",
 				Type:        "test",
 				Required:    false,
+				Scope:       models.ScopeTest,
 				BuildSystem: "maven",
 				Source:      "pom.xml",
 			},
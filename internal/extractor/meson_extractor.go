@@ -3,7 +3,6 @@ package extractor
 import (
 	"bufio"
 	"fmt"
-	"os"
 	"regexp"
 	"strings"
 
@@ -27,7 +26,7 @@ func NewMesonExtractor(path string) *MesonExtractor {
 // Extract 提取Meson依赖
 func (e *MesonExtractor) Extract() ([]models.Dependency, error) {
 	// 读取meson.build文件
-	file, err := os.Open(e.FilePath)
+	file, err := e.open(e.FilePath)
 	if err != nil {
 		return nil, NewExtractorError(MesonExtractorType, e.FilePath, err.Error())
 	}
@@ -139,6 +138,7 @@ func (e *MesonExtractor) Extract() ([]models.Dependency, error) {
 		return nil, NewExtractorError(MesonExtractorType, e.FilePath, err.Error())
 	}
 
+	e.annotate(MesonExtractorType, deps)
 	return deps, nil
 }
 
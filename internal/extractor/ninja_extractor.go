@@ -77,10 +77,9 @@ func (e *NinjaExtractor) Extract(projectPath string, filePath string) ([]models.
 			includePath := strings.TrimSpace(matches[1])
 			includePath = expandVariables(includePath, variables)
 			dep := models.Dependency{
-				Name:     includePath,
-				Type:     "ninja_include",
-				FilePath: filePath,
-				Line:     lineNum,
+				Name:       includePath,
+				Type:       "ninja_include",
+				Provenance: lineProvenance(NinjaExtractorType, filePath, lineNum, line, nil, "ninja.include"),
 			}
 			dependencies = append(dependencies, dep)
 			continue
@@ -91,10 +90,9 @@ func (e *NinjaExtractor) Extract(projectPath string, filePath string) ([]models.
 			subninjaPath := strings.TrimSpace(matches[1])
 			subninjaPath = expandVariables(subninjaPath, variables)
 			dep := models.Dependency{
-				Name:     subninjaPath,
-				Type:     "ninja_subninja",
-				FilePath: filePath,
-				Line:     lineNum,
+				Name:       subninjaPath,
+				Type:       "ninja_subninja",
+				Provenance: lineProvenance(NinjaExtractorType, filePath, lineNum, line, nil, "ninja.subninja"),
 			}
 			dependencies = append(dependencies, dep)
 			continue
@@ -116,10 +114,9 @@ func (e *NinjaExtractor) Extract(projectPath string, filePath string) ([]models.
 			for _, input := range inputs {
 				input = expandVariables(input, variables)
 				dep := models.Dependency{
-					Name:     input,
-					Type:     "ninja_input",
-					FilePath: filePath,
-					Line:     lineNum,
+					Name:       input,
+					Type:       "ninja_input",
+					Provenance: lineProvenance(NinjaExtractorType, filePath, lineNum, line, nil, "ninja.input"),
 					Parent:   outputs[0], // 使用第一个输出作为父节点
 					Rule:     rule,
 				}
@@ -130,10 +127,9 @@ func (e *NinjaExtractor) Extract(projectPath string, filePath string) ([]models.
 			for _, implicit := range implicitDeps {
 				implicit = expandVariables(implicit, variables)
 				dep := models.Dependency{
-					Name:     implicit,
-					Type:     "ninja_implicit",
-					FilePath: filePath,
-					Line:     lineNum,
+					Name:       implicit,
+					Type:       "ninja_implicit",
+					Provenance: lineProvenance(NinjaExtractorType, filePath, lineNum, line, nil, "ninja.implicit"),
 					Parent:   outputs[0],
 					Rule:     rule,
 				}
@@ -146,6 +142,8 @@ func (e *NinjaExtractor) Extract(projectPath string, filePath string) ([]models.
 		return nil, fmt.Errorf("error scanning file %s: %v", filePath, err)
 	}
 
+	setConfidence(NinjaExtractorType, dependencies)
+	setScope(NinjaExtractorType, dependencies)
 	return dependencies, nil
 }
 
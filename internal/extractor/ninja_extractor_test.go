@@ -103,7 +103,7 @@ subninja build/lib.ninja
 		assert.Equal(t, expected.Type, dep.Type)
 		assert.Equal(t, expected.Parent, dep.Parent)
 		assert.Equal(t, expected.Rule, dep.Rule)
-		assert.Equal(t, testFile, dep.FilePath)
+		assert.Equal(t, testFile, dep.Provenance.File)
 	}
 }
 
@@ -145,7 +145,7 @@ build ${bindir}/app: link ${objdir}/main.o
 		assert.True(t, ok, "Unexpected dependency: %s", dep.Name)
 		assert.Equal(t, expected.Type, dep.Type)
 		assert.Equal(t, expected.Parent, dep.Parent)
-		assert.Equal(t, testFile, dep.FilePath)
+		assert.Equal(t, testFile, dep.Provenance.File)
 	}
 }
 
@@ -0,0 +1,647 @@
+package extractor
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lkpsg/ccscanner/pkg/models"
+)
+
+// FlakeLock flake.lock锁文件
+type FlakeLock struct {
+	Nodes   map[string]FlakeNode `json:"nodes"`
+	Root    string               `json:"root"`
+	Version int                  `json:"version"`
+}
+
+// FlakeNode flake.lock中的节点
+type FlakeNode struct {
+	Inputs   map[string]json.RawMessage `json:"inputs,omitempty"`   // 值为节点名或follows路径
+	Locked   *FlakeRef                  `json:"locked,omitempty"`   // 锁定的引用
+	Original *FlakeRef                  `json:"original,omitempty"` // 原始引用
+	Flake    *bool                      `json:"flake,omitempty"`
+}
+
+// FlakeRef flake引用
+type FlakeRef struct {
+	Type         string `json:"type"`
+	Owner        string `json:"owner,omitempty"`
+	Repo         string `json:"repo,omitempty"`
+	Ref          string `json:"ref,omitempty"`
+	Rev          string `json:"rev,omitempty"`
+	URL          string `json:"url,omitempty"`
+	Path         string `json:"path,omitempty"`
+	ID           string `json:"id,omitempty"`
+	NarHash      string `json:"narHash,omitempty"`
+	LastModified int64  `json:"lastModified,omitempty"`
+}
+
+// NixExtractor Nix依赖提取器
+type NixExtractor struct {
+	BaseExtractor
+	config ExtractorConfig
+}
+
+// NewNixExtractor 创建Nix提取器
+func NewNixExtractor(path string) *NixExtractor {
+	return &NixExtractor{
+		BaseExtractor: NewBaseExtractor(path),
+		config:        DefaultConfig,
+	}
+}
+
+// nixInputKinds 需要提取的输入属性及其依赖类型
+var nixInputKinds = map[string]string{
+	"buildInputs":           "library",
+	"nativeBuildInputs":     "build",
+	"propagatedBuildInputs": "propagated",
+	"checkInputs":           "check",
+	"nativeCheckInputs":     "check",
+}
+
+// Extract 提取Nix依赖
+func (e *NixExtractor) Extract() ([]models.Dependency, error) {
+	var deps []models.Dependency
+	var err error
+
+	switch filepath.Base(e.FilePath) {
+	case "flake.lock":
+		deps, err = e.extractFromLock()
+	case "default.nix", "flake.nix", "shell.nix":
+		deps, err = e.extractFromExpr()
+	default:
+		return nil, NewExtractorError(NixExtractorType, e.FilePath, "unsupported file type")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	e.annotate(NixExtractorType, deps)
+	return deps, nil
+}
+
+// extractFromLock 从flake.lock提取锁定的输入
+func (e *NixExtractor) extractFromLock() ([]models.Dependency, error) {
+	lock, err := readFlakeLock(e.fsys, e.FilePath)
+	if err != nil {
+		return nil, NewExtractorError(NixExtractorType, e.FilePath, err.Error())
+	}
+
+	rootName := lock.rootName()
+	inputNames := lock.inputNames()
+	direct := make(map[string]bool)
+	for _, target := range lock.inputsOf(rootName) {
+		direct[target] = true
+	}
+
+	names := make([]string, 0, len(lock.Nodes))
+	for name := range lock.Nodes {
+		if name != rootName {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	deps := make([]models.Dependency, 0, len(names))
+	for _, name := range names {
+		node := lock.Nodes[name]
+		if node.Locked == nil {
+			continue
+		}
+
+		dep := models.NewDependency(inputName(inputNames, name))
+		dep.Type = "flake-input"
+		dep.BuildSystem = "nix"
+		dep.DetectedBy = "NixExtractor"
+		dep.ConfigFile = e.FilePath
+		dep.ConfigFileType = "flake.lock"
+		dep.Languages = []string{"Nix"}
+		dep.Commit = node.Locked.Rev
+		dep.Checksum = node.Locked.NarHash
+		dep.Source = node.Locked.Type
+		dep.Repository = flakeRepository(node.Locked)
+		if node.Locked.LastModified > 0 {
+			dep.LastUpdated = time.Unix(node.Locked.LastModified, 0).UTC()
+		}
+		if node.Original != nil && node.Original.Ref != "" {
+			if looksLikeVersion(node.Original.Ref) {
+				dep.Version = strings.TrimPrefix(node.Original.Ref, "v")
+			} else {
+				dep.Branch = node.Original.Ref
+			}
+		}
+		for _, target := range lock.inputsOf(name) {
+			dep.Dependencies = append(dep.Dependencies, inputName(inputNames, target))
+		}
+		if name != dep.Name {
+			dep.Metadata = map[string]interface{}{"flake_node": name}
+		}
+		if direct[name] {
+			dep.Description = "Nix flake input"
+			dep.Relationship = models.RelationshipDirect
+		} else {
+			dep.Description = "Transitive Nix flake input"
+			dep.Relationship = models.RelationshipTransitive
+		}
+
+		deps = append(deps, *dep)
+	}
+
+	return deps, nil
+}
+
+// extractFromExpr 从Nix表达式中提取buildInputs等输入
+func (e *NixExtractor) extractFromExpr() ([]models.Dependency, error) {
+	data, err := e.readFile(e.FilePath)
+	if err != nil {
+		return nil, NewExtractorError(NixExtractorType, e.FilePath, err.Error())
+	}
+
+	// 同目录下存在flake.lock时,nixpkgs中的包固定在其锁定的revision上
+	var nixpkgs *FlakeRef
+	if lock, err := readFlakeLock(e.fsys, filepath.Join(filepath.Dir(e.FilePath), "flake.lock")); err == nil {
+		nixpkgs = lock.lockedInput("nixpkgs")
+	}
+
+	deps := make([]models.Dependency, 0)
+	seen := make(map[string]bool)
+	for _, input := range parseNixInputs(string(data)) {
+		depType, ok := nixInputKinds[input.attr]
+		if !ok {
+			continue
+		}
+		key := input.attr + "/" + input.name
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		dep := models.NewDependency(input.name)
+		dep.Type = depType
+		dep.BuildSystem = "nix"
+		dep.DetectedBy = "NixExtractor"
+		dep.ConfigFile = e.FilePath
+		dep.ConfigFileType = filepath.Base(e.FilePath)
+		dep.Description = fmt.Sprintf("Nix %s", input.attr)
+		dep.Source = "nixpkgs"
+		if input.optional {
+			dep.Optional = true
+			dep.Required = false
+		}
+		if nixpkgs != nil {
+			dep.Commit = nixpkgs.Rev
+			dep.Repository = flakeRepository(nixpkgs)
+		}
+		deps = append(deps, *dep)
+	}
+
+	return deps, nil
+}
+
+// readFlakeLock 读取并解析flake.lock
+func readFlakeLock(fsys fs.FS, path string) (*FlakeLock, error) {
+	data, err := readFileFS(fsys, path)
+	if err != nil {
+		return nil, err
+	}
+	var lock FlakeLock
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil, fmt.Errorf("failed to parse flake.lock: %v", err)
+	}
+	return &lock, nil
+}
+
+// rootName 返回根节点名
+func (l *FlakeLock) rootName() string {
+	if l.Root == "" {
+		return "root"
+	}
+	return l.Root
+}
+
+// inputsOf 返回节点的输入所指向的节点名,follows路径会被解析
+func (l *FlakeLock) inputsOf(name string) []string {
+	node, ok := l.Nodes[name]
+	if !ok {
+		return nil
+	}
+	keys := make([]string, 0, len(node.Inputs))
+	for key := range node.Inputs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	targets := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if target := l.resolveInput(node.Inputs[key]); target != "" {
+			targets = append(targets, target)
+		}
+	}
+	return targets
+}
+
+// inputNames 返回节点名到输入名的映射,从根节点广度优先遍历,
+// 根节点的输入优先,其余节点取首个引用它的输入名(锁文件会把重名节点记为nixpkgs_2等)
+func (l *FlakeLock) inputNames() map[string]string {
+	names := make(map[string]string)
+	queue := []string{l.rootName()}
+	seen := map[string]bool{l.rootName(): true}
+	for len(queue) > 0 {
+		node := l.Nodes[queue[0]]
+		queue = queue[1:]
+
+		keys := make([]string, 0, len(node.Inputs))
+		for key := range node.Inputs {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			target := l.resolveInput(node.Inputs[key])
+			if target == "" {
+				continue
+			}
+			if _, ok := names[target]; !ok {
+				names[target] = key
+			}
+			if !seen[target] {
+				seen[target] = true
+				queue = append(queue, target)
+			}
+		}
+	}
+	return names
+}
+
+// inputName 返回节点对应的输入名,未被引用的节点沿用节点名
+func inputName(names map[string]string, node string) string {
+	if name, ok := names[node]; ok {
+		return name
+	}
+	return node
+}
+
+// resolveInput 解析输入引用:字符串为节点名,数组为从根节点开始的follows路径
+func (l *FlakeLock) resolveInput(raw json.RawMessage) string {
+	var target string
+	if err := json.Unmarshal(raw, &target); err == nil {
+		return target
+	}
+
+	var path []string
+	if err := json.Unmarshal(raw, &path); err != nil || len(path) == 0 {
+		return ""
+	}
+	current := l.rootName()
+	for _, step := range path {
+		node, ok := l.Nodes[current]
+		if !ok {
+			return ""
+		}
+		next, ok := node.Inputs[step]
+		if !ok {
+			return ""
+		}
+		if current = l.resolveInput(next); current == "" {
+			return ""
+		}
+	}
+	return current
+}
+
+// lockedInput 返回根节点某个输入的锁定引用
+func (l *FlakeLock) lockedInput(input string) *FlakeRef {
+	raw, ok := l.Nodes[l.rootName()].Inputs[input]
+	if !ok {
+		return nil
+	}
+	return l.Nodes[l.resolveInput(raw)].Locked
+}
+
+// flakeRepository 根据flake引用生成仓库地址
+func flakeRepository(ref *FlakeRef) string {
+	switch ref.Type {
+	case "github":
+		return fmt.Sprintf("https://github.com/%s/%s", ref.Owner, ref.Repo)
+	case "gitlab":
+		return fmt.Sprintf("https://gitlab.com/%s/%s", ref.Owner, ref.Repo)
+	case "sourcehut":
+		return fmt.Sprintf("https://git.sr.ht/%s/%s", ref.Owner, ref.Repo)
+	case "path":
+		return ref.Path
+	}
+	return ref.URL
+}
+
+// looksLikeVersion 判断引用名是否像版本号(如 v1.2.0、2.4)
+func looksLikeVersion(ref string) bool {
+	ref = strings.TrimPrefix(ref, "v")
+	return ref != "" && ref[0] >= '0' && ref[0] <= '9' && strings.Contains(ref, ".")
+}
+
+// nixInput Nix表达式中出现的一个输入
+type nixInput struct {
+	attr     string // 所在属性(buildInputs等)
+	name     string // 包名
+	optional bool   // 是否通过lib.optional(s)条件引入
+}
+
+// nixToken Nix词法单元
+type nixToken struct {
+	kind  byte // 'i' 标识符/属性路径, 's' 字符串, 其他为标点本身
+	value string
+}
+
+// parseNixInputs 用Nix表达式子集解析器提取 xxxInputs = ...; 中列出的包
+//
+// 支持 with pkgs; 前缀、[ ... ] 列表、++ 拼接、lib.optional(s) 条件以及
+// (python3.withPackages ...) 这样的括号表达式(取其头部属性路径)。
+func parseNixInputs(src string) []nixInput {
+	tokens := tokenizeNix(src)
+	inputs := make([]nixInput, 0)
+
+	for i := 0; i+1 < len(tokens); i++ {
+		tok := tokens[i]
+		if tok.kind != 'i' || tokens[i+1].kind != '=' {
+			continue
+		}
+		attr := tok.value
+		if idx := strings.LastIndex(attr, "."); idx >= 0 {
+			attr = attr[idx+1:]
+		}
+		if _, ok := nixInputKinds[attr]; !ok {
+			continue
+		}
+
+		// 收集直到顶层分号的右值
+		end := i + 2
+		depth := 0
+		for ; end < len(tokens); end++ {
+			switch tokens[end].kind {
+			case '[', '(', '{':
+				depth++
+			case ']', ')', '}':
+				depth--
+			}
+			if depth < 0 || (depth == 0 && tokens[end].kind == ';' && !isNixWithPrefix(tokens, i+2, end)) {
+				break
+			}
+		}
+		for _, item := range collectNixListItems(tokens[i+2 : end]) {
+			item.attr = attr
+			inputs = append(inputs, item)
+		}
+		i = end
+	}
+
+	return inputs
+}
+
+// isNixWithPrefix 判断位于end处的分号是否属于 with xxx; 前缀
+func isNixWithPrefix(tokens []nixToken, start, end int) bool {
+	return end >= 2 && end-2 >= start && tokens[end-2].kind == 'i' && tokens[end-2].value == "with"
+}
+
+// collectNixListItems 从右值中收集列表元素
+func collectNixListItems(expr []nixToken) []nixInput {
+	items := make([]nixInput, 0)
+	optional := false
+	singleOptional := -1 // lib.optional cond pkg 中剩余需要跳过的参数个数
+
+	for i := 0; i < len(expr); i++ {
+		tok := expr[i]
+		switch {
+		case tok.kind == '+':
+			optional = false
+			singleOptional = -1
+		case tok.kind == 'i' && (tok.value == "with" || tok.value == "inherit"):
+			// 跳过 with xxx;
+			for i < len(expr) && expr[i].kind != ';' {
+				i++
+			}
+		case tok.kind == 'i' && isNixOptionalCall(tok.value):
+			optional = true
+			if strings.HasSuffix(tok.value, "optional") {
+				singleOptional = 1
+			}
+		case tok.kind == '[':
+			end := matchNixBracket(expr, i)
+			for _, name := range nixListElements(expr[i+1 : end]) {
+				items = append(items, nixInput{name: name, optional: optional})
+			}
+			i = end
+		case singleOptional >= 0 && (tok.kind == 'i' || tok.kind == '('):
+			// lib.optional cond pkg: 跳过条件,取第二个参数
+			end := i
+			if tok.kind == '(' {
+				end = matchNixBracket(expr, i)
+			}
+			if singleOptional == 0 {
+				if name := nixElementName(expr[i : end+1]); name != "" {
+					items = append(items, nixInput{name: name, optional: true})
+				}
+			}
+			singleOptional--
+			i = end
+		}
+	}
+	return items
+}
+
+// isNixOptionalCall 判断是否为lib.optional/lib.optionals调用
+func isNixOptionalCall(path string) bool {
+	return path == "optional" || path == "optionals" ||
+		strings.HasSuffix(path, ".optional") || strings.HasSuffix(path, ".optionals")
+}
+
+// matchNixBracket 返回与start处左括号匹配的右括号下标
+func matchNixBracket(tokens []nixToken, start int) int {
+	depth := 0
+	for i := start; i < len(tokens); i++ {
+		switch tokens[i].kind {
+		case '[', '(', '{':
+			depth++
+		case ']', ')', '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(tokens) - 1
+}
+
+// nixListElements 解析列表中的元素名
+func nixListElements(list []nixToken) []string {
+	names := make([]string, 0)
+	for i := 0; i < len(list); i++ {
+		end := i
+		if list[i].kind == '(' || list[i].kind == '[' || list[i].kind == '{' {
+			end = matchNixBracket(list, i)
+		}
+		if name := nixElementName(list[i : end+1]); name != "" {
+			names = append(names, name)
+		}
+		i = end
+	}
+	return names
+}
+
+// nixOutputSuffixes 包输出名后缀
+var nixOutputSuffixes = []string{".dev", ".out", ".bin", ".lib", ".man", ".doc"}
+
+// nixElementName 从元素(属性路径或括号表达式)中得到包名
+func nixElementName(elem []nixToken) string {
+	if len(elem) == 0 {
+		return ""
+	}
+	var path string
+	switch elem[0].kind {
+	case 'i':
+		path = elem[0].value
+	case '(':
+		for _, tok := range elem[1:] {
+			if tok.kind == 'i' {
+				path = tok.value
+				break
+			}
+		}
+	default:
+		return ""
+	}
+
+	segments := strings.Split(strings.TrimPrefix(path, "pkgs."), ".")
+	kept := make([]string, 0, len(segments))
+	for _, seg := range segments {
+		// python3.withPackages / foo.overrideAttrs 等只保留包本身
+		if strings.HasPrefix(seg, "with") || strings.HasPrefix(seg, "override") {
+			break
+		}
+		kept = append(kept, seg)
+	}
+	name := strings.Join(kept, ".")
+	for _, suffix := range nixOutputSuffixes {
+		name = strings.TrimSuffix(name, suffix)
+	}
+	switch name {
+	case "", "lib", "stdenv", "pkgs", "null", "true", "false", "if", "then", "else":
+		return ""
+	}
+	return name
+}
+
+// tokenizeNix 将Nix源码切分为词法单元,忽略注释和字符串内容
+func tokenizeNix(src string) []nixToken {
+	tokens := make([]nixToken, 0)
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '#':
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case c == '/' && i+1 < len(src) && src[i+1] == '*':
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				return tokens
+			}
+			i += end + 4
+		case c == '"':
+			j := i + 1
+			for j < len(src) && src[j] != '"' {
+				if src[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			tokens = append(tokens, nixToken{kind: 's', value: src[min(i+1, len(src)):min(j, len(src))]})
+			i = j + 1
+		case c == '\'' && i+1 < len(src) && src[i+1] == '\'':
+			end := strings.Index(src[i+2:], "''")
+			if end < 0 {
+				return tokens
+			}
+			tokens = append(tokens, nixToken{kind: 's', value: src[i+2 : i+2+end]})
+			i += end + 4
+		case isNixIdentStart(c):
+			j := i
+			for j < len(src) && (isNixIdentChar(src[j]) || (src[j] == '.' && j+1 < len(src) && isNixIdentStart(src[j+1]))) {
+				j++
+			}
+			tokens = append(tokens, nixToken{kind: 'i', value: src[i:j]})
+			i = j
+		case c == '+' && i+1 < len(src) && src[i+1] == '+':
+			tokens = append(tokens, nixToken{kind: '+', value: "++"})
+			i += 2
+		case c == '=' && i+1 < len(src) && src[i+1] == '=':
+			tokens = append(tokens, nixToken{kind: 'o', value: "=="})
+			i += 2
+		default:
+			tokens = append(tokens, nixToken{kind: c, value: string(c)})
+			i++
+		}
+	}
+	return tokens
+}
+
+// isNixIdentStart 判断是否为标识符首字符
+func isNixIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// isNixIdentChar 判断是否为标识符字符
+func isNixIdentChar(c byte) bool {
+	return isNixIdentStart(c) || (c >= '0' && c <= '9') || c == '-' || c == '\''
+}
+
+// NixExtractorFactory Nix提取器工厂
+type NixExtractorFactory struct{}
+
+// CreateExtractor 创建Nix提取器
+func (f *NixExtractorFactory) CreateExtractor(path string) Extractor {
+	return NewNixExtractor(path)
+}
+
+func init() {
+	// 注册Nix提取器
+	RegisterExtractor(NixExtractorType, &NixExtractorFactory{})
+}
+
+/*
+使用示例:
+
+1. 创建Nix提取器:
+extractor := NewNixExtractor("flake.lock")
+
+2. 提取依赖:
+deps, err := extractor.Extract()
+if err != nil {
+    log.Printf("Failed to extract dependencies: %v\n", err)
+    return
+}
+
+3. 处理依赖信息:
+for _, dep := range deps {
+    fmt.Printf("Found input: %s @ %s (%s)\n", dep.Name, dep.Commit, dep.Checksum)
+}
+
+示例default.nix文件:
+```nix
+{ pkgs ? import <nixpkgs> {} }:
+pkgs.stdenv.mkDerivation {
+  pname = "myproject";
+  version = "1.0.0";
+  nativeBuildInputs = with pkgs; [ cmake pkg-config ];
+  buildInputs = [ pkgs.zlib pkgs.openssl.dev (pkgs.python3.withPackages (ps: [ ps.numpy ])) ]
+    ++ pkgs.lib.optionals pkgs.stdenv.isDarwin [ pkgs.darwin.apple_sdk.frameworks.Security ];
+  propagatedBuildInputs = [ pkgs.boost ];
+}
+```
+*/
@@ -0,0 +1,200 @@
+package extractor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lkpsg/ccscanner/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testFlakeLock = `{
+  "nodes": {
+    "flake-utils": {
+      "inputs": { "systems": "systems" },
+      "locked": {
+        "lastModified": 1701680307,
+        "narHash": "sha256-kAuep2h5ajznlPMD9rnQyffWG8EM/C73lejGofXvdM8=",
+        "owner": "numtide",
+        "repo": "flake-utils",
+        "rev": "4022d587cbbfd70fe950c1e2083a02621806a725",
+        "type": "github"
+      },
+      "original": { "owner": "numtide", "repo": "flake-utils", "type": "github" }
+    },
+    "nixpkgs": {
+      "locked": {
+        "lastModified": 1702312524,
+        "narHash": "sha256-gkZJRDBUCpTPBvQk25G0B7vfbpEYM5s5OZqghkjZsnE=",
+        "owner": "NixOS",
+        "repo": "nixpkgs",
+        "rev": "a9bf124c46ef298113270b1f84a164865987a91c",
+        "type": "github"
+      },
+      "original": { "owner": "NixOS", "ref": "nixos-23.11", "repo": "nixpkgs", "type": "github" }
+    },
+    "fmt-src": {
+      "flake": false,
+      "inputs": { "nixpkgs": ["nixpkgs"] },
+      "locked": {
+        "narHash": "sha256-AAAA",
+        "owner": "fmtlib",
+        "repo": "fmt",
+        "rev": "f5e54359df4c26b6230fc61d38aa294581393084",
+        "type": "github"
+      },
+      "original": { "owner": "fmtlib", "ref": "10.1.1", "repo": "fmt", "type": "github" }
+    },
+    "root": {
+      "inputs": { "flake-utils": "flake-utils", "nixpkgs": "nixpkgs", "fmt": "fmt-src" }
+    },
+    "systems": {
+      "locked": {
+        "narHash": "sha256-BBBB",
+        "owner": "nix-systems",
+        "repo": "default",
+        "rev": "da67096a3b9bf56a91d16901293e51ba5b49a27e",
+        "type": "github"
+      },
+      "original": { "owner": "nix-systems", "repo": "default", "type": "github" }
+    }
+  },
+  "root": "root",
+  "version": 7
+}`
+
+func TestNixExtractor_ExtractFlakeLock(t *testing.T) {
+	tempDir := t.TempDir()
+	lockFile := filepath.Join(tempDir, "flake.lock")
+	require.NoError(t, os.WriteFile(lockFile, []byte(testFlakeLock), 0644))
+
+	deps, err := NewNixExtractor(lockFile).Extract()
+	require.NoError(t, err)
+	require.Len(t, deps, 4)
+
+	byName := make(map[string]int)
+	for i, dep := range deps {
+		byName[dep.Name] = i
+		assert.Equal(t, "flake-input", dep.Type)
+		assert.Equal(t, "nix", dep.BuildSystem)
+	}
+
+	nixpkgs := deps[byName["nixpkgs"]]
+	assert.Equal(t, "a9bf124c46ef298113270b1f84a164865987a91c", nixpkgs.Commit)
+	assert.Equal(t, "sha256-gkZJRDBUCpTPBvQk25G0B7vfbpEYM5s5OZqghkjZsnE=", nixpkgs.Checksum)
+	assert.Equal(t, "https://github.com/NixOS/nixpkgs", nixpkgs.Repository)
+	assert.Equal(t, "nixos-23.11", nixpkgs.Branch)
+	assert.Equal(t, "Nix flake input", nixpkgs.Description)
+
+	fmtSrc := deps[byName["fmt"]]
+	assert.Equal(t, "10.1.1", fmtSrc.Version)
+	assert.Equal(t, []string{"nixpkgs"}, fmtSrc.Dependencies)
+	assert.Equal(t, "fmt-src", fmtSrc.Metadata["flake_node"])
+
+	systems := deps[byName["systems"]]
+	assert.Equal(t, "Transitive Nix flake input", systems.Description)
+}
+
+func TestNixExtractor_ExtractFlakeLock_RenamedInputs(t *testing.T) {
+	const lockContent = `{
+  "nodes": {
+    "nixpkgs": {
+      "locked": { "narHash": "sha256-old", "owner": "NixOS", "repo": "nixpkgs", "rev": "1111111111111111111111111111111111111111", "type": "github" },
+      "original": { "owner": "NixOS", "ref": "nixos-23.05", "repo": "nixpkgs", "type": "github" }
+    },
+    "nixpkgs_2": {
+      "locked": { "narHash": "sha256-new", "owner": "NixOS", "repo": "nixpkgs", "rev": "2222222222222222222222222222222222222222", "type": "github" },
+      "original": { "owner": "NixOS", "ref": "nixos-23.11", "repo": "nixpkgs", "type": "github" }
+    },
+    "utils": {
+      "inputs": { "nixpkgs": "nixpkgs" },
+      "locked": { "narHash": "sha256-utils", "owner": "numtide", "repo": "flake-utils", "rev": "3333333333333333333333333333333333333333", "type": "github" },
+      "original": { "owner": "numtide", "repo": "flake-utils", "type": "github" }
+    },
+    "root": {
+      "inputs": { "flake-utils": "utils", "nixpkgs": "nixpkgs_2" }
+    }
+  },
+  "root": "root",
+  "version": 7
+}`
+	lockFile := filepath.Join(t.TempDir(), "flake.lock")
+	require.NoError(t, os.WriteFile(lockFile, []byte(lockContent), 0644))
+
+	deps, err := NewNixExtractor(lockFile).Extract()
+	require.NoError(t, err)
+	require.Len(t, deps, 3)
+
+	byCommit := make(map[string]int)
+	for i, dep := range deps {
+		byCommit[dep.Commit] = i
+	}
+
+	direct := deps[byCommit["2222222222222222222222222222222222222222"]]
+	assert.Equal(t, "nixpkgs", direct.Name)
+	assert.Equal(t, "nixos-23.11", direct.Branch)
+	assert.Equal(t, models.RelationshipDirect, direct.Relationship)
+	assert.Equal(t, "nixpkgs_2", direct.Metadata["flake_node"])
+
+	utils := deps[byCommit["3333333333333333333333333333333333333333"]]
+	assert.Equal(t, "flake-utils", utils.Name)
+	assert.Equal(t, []string{"nixpkgs"}, utils.Dependencies)
+
+	transitive := deps[byCommit["1111111111111111111111111111111111111111"]]
+	assert.Equal(t, "nixpkgs", transitive.Name)
+	assert.Equal(t, models.RelationshipTransitive, transitive.Relationship)
+	assert.Nil(t, transitive.Metadata)
+}
+
+func TestNixExtractor_ExtractBuildInputs(t *testing.T) {
+	tempDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, "flake.lock"), []byte(testFlakeLock), 0644))
+
+	nixFile := filepath.Join(tempDir, "default.nix")
+	content := `{ pkgs ? import <nixpkgs> {} }:
+# buildInputs = [ commented ];
+pkgs.stdenv.mkDerivation {
+  pname = "myproject";
+  version = "1.0.0";
+  nativeBuildInputs = with pkgs; [ cmake pkg-config ];
+  buildInputs = [
+    pkgs.zlib
+    pkgs.openssl.dev
+    (pkgs.python3.withPackages (ps: [ ps.numpy ]))
+    "not-a-package"
+  ] ++ pkgs.lib.optionals pkgs.stdenv.isDarwin [ pkgs.darwin.apple_sdk.frameworks.Security ]
+    ++ pkgs.lib.optional pkgs.stdenv.isLinux pkgs.libcap;
+  propagatedBuildInputs = [ pkgs.boost ];
+  /* nativeBuildInputs = [ ignored ]; */
+}
+`
+	require.NoError(t, os.WriteFile(nixFile, []byte(content), 0644))
+
+	deps, err := NewNixExtractor(nixFile).Extract()
+	require.NoError(t, err)
+
+	got := make(map[string]string)
+	optional := make(map[string]bool)
+	for _, dep := range deps {
+		got[dep.Name] = dep.Type
+		optional[dep.Name] = dep.Optional
+		assert.Equal(t, "a9bf124c46ef298113270b1f84a164865987a91c", dep.Commit)
+		assert.Equal(t, "default.nix", dep.ConfigFileType)
+	}
+
+	assert.Equal(t, map[string]string{
+		"cmake":                                "build",
+		"pkg-config":                           "build",
+		"zlib":                                 "library",
+		"openssl":                              "library",
+		"python3":                              "library",
+		"darwin.apple_sdk.frameworks.Security": "library",
+		"libcap":                               "library",
+		"boost":                                "propagated",
+	}, got)
+	assert.True(t, optional["darwin.apple_sdk.frameworks.Security"])
+	assert.True(t, optional["libcap"])
+	assert.False(t, optional["zlib"])
+}
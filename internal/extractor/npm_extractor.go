@@ -97,6 +97,13 @@ func (e *NPMExtractor) Extract(dir string) ([]models.Dependency, error) {
 
 	e.logger.Info("Completed NPM dependency extraction",
 		zap.Int("total_deps", len(allDeps)))
+	locator := newLocator(nil)
+	for i := range allDeps {
+		allDeps[i].PURL = npmPackageURL(allDeps[i].Name, lockedVersion(&allDeps[i]))
+		locator.fill(NPMExtractorType, sourceFile(allDeps[i].Source), &allDeps[i])
+		scoreConfidence(NPMExtractorType, &allDeps[i])
+		classifyScope(NPMExtractorType, &allDeps[i])
+	}
 	return allDeps, nil
 }
 
@@ -214,12 +221,21 @@ func (e *NPMExtractor) parseLockfile(file string) ([]models.Dependency, error) {
 	// 处理锁定的依赖
 	for name, entry := range lock.Dependencies {
 		dep := models.Dependency{
-			Name:        name,
-			Version:     entry.Version,
-			Type:        "locked",
-			Required:    !entry.Optional,
-			BuildSystem: "npm",
-			Source:      file,
+			Name:         name,
+			Version:      entry.Version,
+			Type:         "locked",
+			Relationship: models.RelationshipTransitive,
+			Required:     !entry.Optional,
+			BuildSystem:  "npm",
+			Source:       file,
+		}
+
+		// 锁定文件标记了只由开发依赖或可选依赖引入的包
+		switch {
+		case entry.Dev:
+			dep.Scope = models.ScopeDev
+		case entry.Optional:
+			dep.Scope = models.ScopeOptional
 		}
 
 		// 添加解析URL
@@ -236,12 +252,13 @@ func (e *NPMExtractor) parseLockfile(file string) ([]models.Dependency, error) {
 		var subDeps []models.Dependency
 		for subName, subVersion := range entry.Dependencies {
 			subDeps = append(subDeps, models.Dependency{
-				Name:        subName,
-				Version:     subVersion,
-				Type:        "transitive",
-				Required:    true,
-				BuildSystem: "npm",
-				Source:      file,
+				Name:         subName,
+				Version:      subVersion,
+				Type:         "transitive",
+				Relationship: models.RelationshipTransitive,
+				Required:     true,
+				BuildSystem:  "npm",
+				Source:       file,
 			})
 		}
 		dep.Dependencies = subDeps
@@ -0,0 +1,743 @@
+package extractor
+
+import (
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/lkpsg/ccscanner/pkg/models"
+)
+
+// PkgbuildExtractor Arch PKGBUILD / Alpine APKBUILD依赖提取器
+type PkgbuildExtractor struct {
+	BaseExtractor
+	config ExtractorConfig
+}
+
+// NewPkgbuildExtractor 创建PKGBUILD/APKBUILD提取器
+func NewPkgbuildExtractor(path string) *PkgbuildExtractor {
+	return &PkgbuildExtractor{
+		BaseExtractor: NewBaseExtractor(path),
+		config:        DefaultConfig,
+	}
+}
+
+// shellVars 打包脚本中求值得到的变量
+type shellVars struct {
+	scalars map[string]string   // 标量变量
+	arrays  map[string][]string // 数组变量
+	lines   map[string]int      // 变量首次赋值所在行
+}
+
+// pkgbuildDepKinds 依赖数组及其对应的依赖类型(与依赖范围一致)
+var pkgbuildDepKinds = []struct {
+	name    string
+	depType string
+}{
+	{"depends", "runtime"},
+	{"makedepends", "build"},
+	{"checkdepends", "test"},
+	{"optdepends", "optional"},
+}
+
+// pkgbuildChecksumKinds 校验和数组(按优先级排列)
+var pkgbuildChecksumKinds = []string{"sha256sums", "sha512sums", "b2sums", "sha1sums", "md5sums"}
+
+// Extract 提取PKGBUILD/APKBUILD依赖
+func (e *PkgbuildExtractor) Extract() ([]models.Dependency, error) {
+	data, err := e.readFile(e.FilePath)
+	if err != nil {
+		return nil, NewExtractorError(PkgbuildExtractorType, e.FilePath, err.Error())
+	}
+
+	vars := parseShellAssignments(string(data))
+	fileType := filepath.Base(e.FilePath)
+	buildSystem := "makepkg"
+	if fileType == "APKBUILD" {
+		buildSystem = "abuild"
+	}
+
+	deps := make([]models.Dependency, 0)
+
+	// 处理依赖数组(包括depends_x86_64这类架构相关的数组)
+	for _, kind := range pkgbuildDepKinds {
+		for _, key := range vars.keysWithPrefix(kind.name) {
+			for _, item := range vars.list(key) {
+				dep := e.parseDependencyItem(item, kind.depType)
+				if dep == nil {
+					continue
+				}
+				dep.BuildSystem = buildSystem
+				dep.ConfigFileType = fileType
+				dep.Scope = kind.depType
+				deps = append(deps, *dep)
+			}
+		}
+	}
+
+	// 处理源码地址
+	for _, key := range vars.keysWithPrefix("source") {
+		suffix := strings.TrimPrefix(key, "source")
+		checksums := e.checksums(vars, suffix)
+		for i, item := range vars.list(key) {
+			dep := e.parseSourceItem(item, vars)
+			if dep == nil {
+				continue
+			}
+			if sum, ok := checksums.lookup(i, sourceFileName(item)); ok {
+				dep.Checksum = sum
+			}
+			dep.BuildSystem = buildSystem
+			dep.ConfigFileType = fileType
+			deps = append(deps, *dep)
+		}
+	}
+
+	e.annotate(PkgbuildExtractorType, deps)
+	return deps, nil
+}
+
+// parseDependencyItem 解析依赖项(如 openssl>=1.1、"python: bindings")
+func (e *PkgbuildExtractor) parseDependencyItem(item, depType string) *models.Dependency {
+	item = strings.TrimSpace(item)
+	// APKBUILD中以!开头表示冲突,不属于依赖
+	if item == "" || strings.HasPrefix(item, "!") {
+		return nil
+	}
+
+	var description string
+	if depType == "optional" {
+		if idx := strings.Index(item, ":"); idx > 0 && !strings.Contains(item[:idx], "/") {
+			description = strings.TrimSpace(item[idx+1:])
+			item = strings.TrimSpace(item[:idx])
+		}
+	}
+
+	name, operator, version := splitPkgConstraint(item)
+	if name == "" {
+		return nil
+	}
+
+	dep := models.NewDependency(name)
+	dep.Type = depType
+	dep.DetectedBy = "PkgbuildExtractor"
+	dep.ConfigFile = e.FilePath
+	dep.Description = description
+	if operator != "" && version != "" {
+		dep.Constraints = append(dep.Constraints, models.VersionConstrain{
+			Operator: operator,
+			Version:  version,
+		})
+		if operator == "=" {
+			dep.Version = version
+		}
+	}
+	if depType == "optional" {
+		dep.Optional = true
+		dep.Required = false
+	}
+	return dep
+}
+
+// parseSourceItem 解析source数组中的远程源码地址
+func (e *PkgbuildExtractor) parseSourceItem(item string, vars *shellVars) *models.Dependency {
+	// 支持 "文件名::地址" 的重命名写法
+	if idx := strings.Index(item, "::"); idx >= 0 {
+		item = item[idx+2:]
+	}
+	if !strings.Contains(item, "://") {
+		// 本地补丁等文件不是外部依赖
+		return nil
+	}
+
+	url, fragment := item, ""
+	if idx := strings.Index(item, "#"); idx >= 0 {
+		url, fragment = item[:idx], item[idx+1:]
+	}
+
+	name := sourceProjectName(url)
+	dep := models.NewDependency(name)
+	dep.Type = "source"
+	dep.DetectedBy = "PkgbuildExtractor"
+	dep.ConfigFile = e.FilePath
+	dep.Repository = strings.TrimPrefix(url, "git+")
+	dep.Source = sourceHost(url)
+	// 以提交等方式固定的其他上游源码不继承软件包的版本
+	if pkgver := vars.scalars["pkgver"]; pkgver != "" && (isOwnSource(name, vars) || urlHasVersion(url, pkgver)) {
+		dep.Version = pkgver
+	}
+
+	// 处理 #tag= / #commit= / #branch= 片段
+	if key, value, ok := strings.Cut(fragment, "="); ok {
+		switch key {
+		case "tag":
+			dep.Version = strings.TrimPrefix(value, "v")
+		case "commit":
+			dep.Commit = value
+		case "branch":
+			dep.Branch = value
+		}
+	}
+	return dep
+}
+
+// isOwnSource 判断源码是否属于软件包本身(名称与pkgname或pkgbase一致)
+func isOwnSource(name string, vars *shellVars) bool {
+	names := append(vars.list("pkgname"), vars.scalars["pkgbase"])
+	for _, pkgname := range names {
+		if pkgname != "" && strings.EqualFold(name, pkgname) {
+			return true
+		}
+	}
+	return false
+}
+
+// versionPrefixRe 完整版本号之前的内容: 开头或非字母数字的分隔符, 可带v前缀
+var versionPrefixRe = regexp.MustCompile(`(?:^|[^0-9A-Za-z.])v?$`)
+
+// versionSuffixRe 完整版本号之后的内容: 结尾、非数字分隔符或扩展名
+var versionSuffixRe = regexp.MustCompile(`^(?:$|[^0-9.]|\.[^0-9])`)
+
+// archiveNameRe 源码包文件名中的项目名和版本(如 zlib-1.3.tar.gz)
+var archiveNameRe = regexp.MustCompile(`^(.+?)[-_]v?\d[\w.]*?(\.tar(\.\w+)?|\.tgz|\.zip|\.tbz2?|\.txz)?$`)
+
+// urlHasVersion 判断地址中是否包含完整的版本号(如 libfoo-1.2.11.tar.gz、/v1.2.11/)
+func urlHasVersion(url, version string) bool {
+	if version == "" {
+		return false
+	}
+	for i := 0; i+len(version) <= len(url); i++ {
+		j := strings.Index(url[i:], version)
+		if j < 0 {
+			return false
+		}
+		i += j
+		if versionPrefixRe.MatchString(url[:i]) && versionSuffixRe.MatchString(url[i+len(version):]) {
+			return true
+		}
+	}
+	return false
+}
+
+// sourceChecksums 与source数组对应的校验和
+type sourceChecksums struct {
+	algo    string
+	byIndex []string          // PKGBUILD: 按下标对应
+	byFile  map[string]string // APKBUILD: "hash  filename" 形式
+}
+
+// checksums 获取与source数组(及其架构后缀)对应的校验和
+func (e *PkgbuildExtractor) checksums(vars *shellVars, suffix string) sourceChecksums {
+	for _, kind := range pkgbuildChecksumKinds {
+		key := kind + suffix
+		if _, ok := vars.arrays[key]; ok {
+			return sourceChecksums{algo: strings.TrimSuffix(kind, "sums"), byIndex: vars.arrays[key]}
+		}
+		if value, ok := vars.scalars[key]; ok {
+			byFile := make(map[string]string)
+			for _, line := range strings.Split(value, "\n") {
+				fields := strings.Fields(line)
+				if len(fields) == 2 {
+					byFile[fields[1]] = fields[0]
+				}
+			}
+			return sourceChecksums{algo: strings.TrimSuffix(kind, "sums"), byFile: byFile}
+		}
+	}
+	return sourceChecksums{}
+}
+
+// lookup 查找指定源码的校验和,格式为 "算法:摘要"
+func (c sourceChecksums) lookup(index int, fileName string) (string, bool) {
+	var sum string
+	if c.byFile != nil {
+		sum = c.byFile[fileName]
+	} else if index < len(c.byIndex) {
+		sum = c.byIndex[index]
+	}
+	if sum == "" || sum == "SKIP" {
+		return "", false
+	}
+	return c.algo + ":" + sum, true
+}
+
+// splitPkgConstraint 拆分 name>=version 形式的依赖
+func splitPkgConstraint(item string) (name, operator, version string) {
+	for _, op := range []string{">=", "<=", "=", ">", "<", "~"} {
+		if idx := strings.Index(item, op); idx > 0 {
+			return item[:idx], op, item[idx+len(op):]
+		}
+	}
+	return item, "", ""
+}
+
+// sourceFileName 获取source项对应的本地文件名
+func sourceFileName(item string) string {
+	if idx := strings.Index(item, "::"); idx >= 0 {
+		return item[:idx]
+	}
+	if idx := strings.Index(item, "#"); idx >= 0 {
+		item = item[:idx]
+	}
+	return path.Base(item)
+}
+
+// sourceProjectName 从源码地址推断项目名
+func sourceProjectName(url string) string {
+	url = strings.TrimSuffix(strings.TrimPrefix(url, "git+"), "/")
+	host := sourceHost(url)
+	parts := strings.Split(url, "/")
+	if host != "" && len(parts) >= 5 {
+		// https://github.com/owner/repo/...
+		return strings.TrimSuffix(parts[4], ".git")
+	}
+
+	base := strings.TrimSuffix(path.Base(url), ".git")
+	if matches := archiveNameRe.FindStringSubmatch(base); len(matches) > 1 {
+		return matches[1]
+	}
+	return base
+}
+
+// sourceHost 从地址识别代码托管平台
+func sourceHost(url string) string {
+	switch {
+	case strings.Contains(url, "github.com"):
+		return "github"
+	case strings.Contains(url, "gitlab.com"):
+		return "gitlab"
+	case strings.Contains(url, "bitbucket.org"):
+		return "bitbucket"
+	}
+	return ""
+}
+
+// keysWithPrefix 按首次出现顺序返回以prefix开头(可带_arch后缀)的变量名
+func (v *shellVars) keysWithPrefix(prefix string) []string {
+	keys := make([]string, 0)
+	for key := range v.lines {
+		if key == prefix || strings.HasPrefix(key, prefix+"_") {
+			keys = append(keys, key)
+		}
+	}
+	// 按定义行号排序,保证输出稳定
+	for i := 1; i < len(keys); i++ {
+		for j := i; j > 0 && v.lines[keys[j]] < v.lines[keys[j-1]]; j-- {
+			keys[j], keys[j-1] = keys[j-1], keys[j]
+		}
+	}
+	return keys
+}
+
+// list 以列表形式读取变量(APKBUILD中用空白分隔的字符串也视为列表)
+func (v *shellVars) list(key string) []string {
+	if items, ok := v.arrays[key]; ok {
+		return items
+	}
+	return strings.Fields(v.scalars[key])
+}
+
+// parseShellAssignments 求值打包脚本中顶层的简单变量和数组赋值
+//
+// 只支持PKGBUILD/APKBUILD中常见的写法: name=value、name=(a b c)、name+=(...)、
+// 单双引号、反斜杠续行以及$name/${name}形式的变量展开。函数体中的内容会被跳过。
+func parseShellAssignments(content string) *shellVars {
+	vars := &shellVars{
+		scalars: make(map[string]string),
+		arrays:  make(map[string][]string),
+		lines:   make(map[string]int),
+	}
+	p := &shellParser{src: content, line: 1, vars: vars}
+	p.parse()
+	return vars
+}
+
+// shellParser 简化的shell赋值语句解析器
+type shellParser struct {
+	src  string
+	pos  int
+	line int
+	vars *shellVars
+}
+
+var shellAssignRe = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*)(\+?=)`)
+var shellFuncRe = regexp.MustCompile(`^[A-Za-z_][\w-]*\s*\(\)\s*\{?`)
+
+// parse 解析整个脚本
+func (p *shellParser) parse() {
+	for p.pos < len(p.src) {
+		p.skipBlank()
+		if p.pos >= len(p.src) {
+			return
+		}
+		rest := p.src[p.pos:]
+
+		if matches := shellAssignRe.FindStringSubmatch(rest); matches != nil {
+			name, op := matches[1], matches[2]
+			startLine := p.line
+			p.advance(len(matches[0]))
+			if p.pos < len(p.src) && p.src[p.pos] == '(' {
+				p.advance(1)
+				items := p.parseArray()
+				if op == "+=" {
+					items = append(p.vars.arrays[name], items...)
+				}
+				p.vars.arrays[name] = items
+				delete(p.vars.scalars, name)
+			} else {
+				value := p.parseWord()
+				if op == "+=" {
+					value = p.vars.scalars[name] + value
+				}
+				p.vars.scalars[name] = value
+				delete(p.vars.arrays, name)
+			}
+			if _, ok := p.vars.lines[name]; !ok {
+				p.vars.lines[name] = startLine
+			}
+			continue
+		}
+
+		if shellFuncRe.MatchString(rest) {
+			p.skipFunction()
+			continue
+		}
+
+		p.skipLine()
+	}
+}
+
+// advance 前进n个字符并维护行号
+func (p *shellParser) advance(n int) {
+	for i := 0; i < n && p.pos < len(p.src); i++ {
+		if p.src[p.pos] == '\n' {
+			p.line++
+		}
+		p.pos++
+	}
+}
+
+// skipBlank 跳过空白、分号和注释
+func (p *shellParser) skipBlank() {
+	for p.pos < len(p.src) {
+		switch c := p.src[p.pos]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ';':
+			p.advance(1)
+		case c == '#':
+			p.skipLine()
+		default:
+			return
+		}
+	}
+}
+
+// skipLine 跳过当前行剩余部分
+func (p *shellParser) skipLine() {
+	for p.pos < len(p.src) && p.src[p.pos] != '\n' {
+		p.pos++
+	}
+}
+
+// skipFunction 跳过函数定义
+func (p *shellParser) skipFunction() {
+	depth := 0
+	started := false
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		switch {
+		case c == '#' && (p.pos == 0 || p.src[p.pos-1] == ' ' || p.src[p.pos-1] == '\n' || p.src[p.pos-1] == '\t'):
+			p.skipLine()
+			continue
+		case c == '\'' || c == '"':
+			p.parseWord()
+			continue
+		case c == '$' && p.pos+1 < len(p.src) && p.src[p.pos+1] == '{':
+			p.skipBraceExpansion()
+			continue
+		case c == '{':
+			depth++
+			started = true
+		case c == '}':
+			depth--
+		}
+		p.advance(1)
+		if started && depth == 0 {
+			return
+		}
+	}
+}
+
+// skipBraceExpansion 跳过${...}
+func (p *shellParser) skipBraceExpansion() {
+	depth := 0
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		p.advance(1)
+		if c == '{' {
+			depth++
+		} else if c == '}' {
+			depth--
+			if depth == 0 {
+				return
+			}
+		}
+	}
+}
+
+// parseArray 解析数组元素直到右括号
+func (p *shellParser) parseArray() []string {
+	items := make([]string, 0)
+	for p.pos < len(p.src) {
+		p.skipBlank()
+		if p.pos >= len(p.src) {
+			break
+		}
+		if p.src[p.pos] == ')' {
+			p.advance(1)
+			break
+		}
+		if word := p.parseWord(); word != "" {
+			items = append(items, word)
+		} else if p.pos < len(p.src) && p.src[p.pos] != ')' {
+			// 无法识别的字符,跳过以避免死循环
+			p.advance(1)
+		}
+	}
+	return items
+}
+
+// parseWord 解析一个shell单词,处理引号、转义和变量展开
+func (p *shellParser) parseWord() string {
+	var b strings.Builder
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		switch c {
+		case ' ', '\t', '\n', '\r', ';', ')':
+			return b.String()
+		case '\\':
+			if p.pos+1 < len(p.src) {
+				if p.src[p.pos+1] != '\n' {
+					b.WriteByte(p.src[p.pos+1])
+				}
+				p.advance(2)
+			} else {
+				p.advance(1)
+			}
+		case '\'':
+			p.advance(1)
+			for p.pos < len(p.src) && p.src[p.pos] != '\'' {
+				b.WriteByte(p.src[p.pos])
+				p.advance(1)
+			}
+			p.advance(1)
+		case '"':
+			p.advance(1)
+			for p.pos < len(p.src) && p.src[p.pos] != '"' {
+				switch p.src[p.pos] {
+				case '\\':
+					if p.pos+1 < len(p.src) && strings.IndexByte("\"\\$`", p.src[p.pos+1]) >= 0 {
+						b.WriteByte(p.src[p.pos+1])
+						p.advance(2)
+						continue
+					}
+					if p.pos+1 < len(p.src) && p.src[p.pos+1] == '\n' {
+						p.advance(2)
+						continue
+					}
+					b.WriteByte('\\')
+					p.advance(1)
+				case '$':
+					b.WriteString(p.parseExpansion())
+				default:
+					b.WriteByte(p.src[p.pos])
+					p.advance(1)
+				}
+			}
+			p.advance(1)
+		case '$':
+			b.WriteString(p.parseExpansion())
+		default:
+			b.WriteByte(c)
+			p.advance(1)
+		}
+	}
+	return b.String()
+}
+
+var shellVarNameRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*`)
+
+// parseExpansion 解析并展开$name或${name...},未知变量保持原样
+func (p *shellParser) parseExpansion() string {
+	rest := p.src[p.pos+1:]
+	if strings.HasPrefix(rest, "{") {
+		end := strings.IndexByte(rest, '}')
+		if end < 0 {
+			p.advance(1)
+			return "$"
+		}
+		expr := rest[1:end]
+		raw := p.src[p.pos : p.pos+end+2]
+		p.advance(end + 2)
+		if value, ok := p.expandExpr(expr); ok {
+			return value
+		}
+		return raw
+	}
+
+	name := shellVarNameRe.FindString(rest)
+	if name == "" {
+		p.advance(1)
+		return "$"
+	}
+	p.advance(len(name) + 1)
+	if value, ok := p.lookup(name); ok {
+		return value
+	}
+	return "$" + name
+}
+
+// lookup 查找变量值,数组变量取全部元素
+func (p *shellParser) lookup(name string) (string, bool) {
+	if value, ok := p.vars.scalars[name]; ok {
+		return value, true
+	}
+	if items, ok := p.vars.arrays[name]; ok {
+		return strings.Join(items, " "), true
+	}
+	return "", false
+}
+
+// expandExpr 展开${...}内部表达式,支持 ${v}、${v[@]}、${v//a/b}、${v/a/b}、${v%p}、${v%%p}、${v#p}、${v##p}
+func (p *shellParser) expandExpr(expr string) (string, bool) {
+	name := shellVarNameRe.FindString(expr)
+	if name == "" {
+		return "", false
+	}
+	op := expr[len(name):]
+	if op == "[@]" || op == "[*]" {
+		op = ""
+	}
+	value, ok := p.lookup(name)
+	if !ok {
+		return "", false
+	}
+
+	switch {
+	case op == "":
+		return value, true
+	case strings.HasPrefix(op, "//"):
+		from, to, _ := strings.Cut(op[2:], "/")
+		return strings.ReplaceAll(value, from, to), true
+	case strings.HasPrefix(op, "/"):
+		from, to, _ := strings.Cut(op[1:], "/")
+		return strings.Replace(value, from, to, 1), true
+	case strings.HasPrefix(op, "%%"):
+		return trimShellSuffix(value, op[2:], true), true
+	case strings.HasPrefix(op, "%"):
+		return trimShellSuffix(value, op[1:], false), true
+	case strings.HasPrefix(op, "##"):
+		return trimShellPrefix(value, op[2:], true), true
+	case strings.HasPrefix(op, "#"):
+		return trimShellPrefix(value, op[1:], false), true
+	}
+	return "", false
+}
+
+// trimShellSuffix 删除匹配glob模式的后缀
+func trimShellSuffix(value, pattern string, longest bool) string {
+	if longest {
+		for i := 0; i <= len(value); i++ {
+			if ok, _ := path.Match(pattern, value[i:]); ok {
+				return value[:i]
+			}
+		}
+		return value
+	}
+	for i := len(value); i >= 0; i-- {
+		if ok, _ := path.Match(pattern, value[i:]); ok {
+			return value[:i]
+		}
+	}
+	return value
+}
+
+// trimShellPrefix 删除匹配glob模式的前缀
+func trimShellPrefix(value, pattern string, longest bool) string {
+	if longest {
+		for i := len(value); i >= 0; i-- {
+			if ok, _ := path.Match(pattern, value[:i]); ok {
+				return value[i:]
+			}
+		}
+		return value
+	}
+	for i := 0; i <= len(value); i++ {
+		if ok, _ := path.Match(pattern, value[:i]); ok {
+			return value[i:]
+		}
+	}
+	return value
+}
+
+// PkgbuildExtractorFactory PKGBUILD/APKBUILD提取器工厂
+type PkgbuildExtractorFactory struct{}
+
+// CreateExtractor 创建PKGBUILD/APKBUILD提取器
+func (f *PkgbuildExtractorFactory) CreateExtractor(path string) Extractor {
+	return NewPkgbuildExtractor(path)
+}
+
+func init() {
+	// 注册PKGBUILD/APKBUILD提取器
+	RegisterExtractor(PkgbuildExtractorType, &PkgbuildExtractorFactory{})
+}
+
+/*
+使用示例:
+
+1. 创建提取器:
+extractor := NewPkgbuildExtractor("PKGBUILD")
+
+2. 提取依赖:
+deps, err := extractor.Extract()
+if err != nil {
+    log.Printf("Failed to extract dependencies: %v\n", err)
+    return
+}
+
+3. 处理依赖信息:
+for _, dep := range deps {
+    fmt.Printf("Found dependency: %s %s (%s)\n", dep.Name, dep.Version, dep.Type)
+    if dep.Checksum != "" {
+        fmt.Printf("  Checksum: %s\n", dep.Checksum)
+    }
+}
+
+示例PKGBUILD文件:
+```
+pkgname=libfoo
+pkgver=1.2.11
+pkgrel=1
+depends=('glibc' 'zlib>=1.2')
+makedepends=('cmake' 'ninja')
+checkdepends=('gtest')
+optdepends=('python: bindings')
+source=("https://github.com/acme/libfoo/archive/v$pkgver/libfoo-$pkgver.tar.gz"
+        "fix-build.patch")
+sha256sums=('0123456789abcdef...'
+            'SKIP')
+```
+
+示例APKBUILD文件:
+```
+pkgname=libfoo
+pkgver=1.2.11
+depends="zlib"
+makedepends="cmake samurai"
+source="https://github.com/acme/libfoo/archive/v$pkgver/libfoo-$pkgver.tar.gz"
+sha512sums="
+abcdef...  libfoo-1.2.11.tar.gz
+"
+```
+*/
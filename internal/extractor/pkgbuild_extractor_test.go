@@ -0,0 +1,148 @@
+package extractor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPkgbuildExtractor_Extract(t *testing.T) {
+	tempDir := t.TempDir()
+	testFile := filepath.Join(tempDir, "PKGBUILD")
+	content := `# Maintainer: someone
+pkgname=libfoo
+pkgver=1.2.11
+pkgrel=1
+_tag="v${pkgver}"
+depends=('glibc' 'zlib>=1.2')
+depends_x86_64=(libunwind)
+makedepends=('cmake'
+             'ninja') # build tools
+checkdepends=(gtest)
+optdepends=('python: for the bindings'
+            'doxygen: documentation')
+source=("https://github.com/acme/libfoo/archive/${_tag}/$pkgname-$pkgver.tar.gz"
+        "bar::git+https://gitlab.com/acme/bar.git#commit=0123abcd"
+        "fix-build.patch")
+sha256sums=('aaaa'
+            'SKIP'
+            'bbbb')
+
+build() {
+  cd "$pkgname-$pkgver"
+  depends=(shouldnotappear)
+  cmake -B build -DVERSION="${pkgver}"
+}
+`
+	require.NoError(t, os.WriteFile(testFile, []byte(content), 0644))
+
+	deps, err := NewPkgbuildExtractor(testFile).Extract()
+	require.NoError(t, err)
+
+	byName := make(map[string]int)
+	for i, dep := range deps {
+		byName[dep.Name] = i
+		assert.Equal(t, "makepkg", dep.BuildSystem)
+		assert.Equal(t, "PKGBUILD", dep.ConfigFileType)
+	}
+	assert.NotContains(t, byName, "shouldnotappear")
+	assert.NotContains(t, byName, "fix-build.patch")
+
+	zlib := deps[byName["zlib"]]
+	assert.Equal(t, "runtime", zlib.Type)
+	require.Len(t, zlib.Constraints, 1)
+	assert.Equal(t, ">=", zlib.Constraints[0].Operator)
+	assert.Equal(t, "1.2", zlib.Constraints[0].Version)
+
+	assert.Equal(t, "runtime", deps[byName["libunwind"]].Type)
+	assert.Equal(t, "build", deps[byName["ninja"]].Type)
+	assert.Equal(t, "test", deps[byName["gtest"]].Type)
+
+	python := deps[byName["python"]]
+	assert.True(t, python.Optional)
+	assert.Equal(t, "for the bindings", python.Description)
+
+	libfoo := deps[byName["libfoo"]]
+	assert.Equal(t, "source", libfoo.Type)
+	assert.Equal(t, "1.2.11", libfoo.Version)
+	assert.Equal(t, "github", libfoo.Source)
+	assert.Equal(t, "https://github.com/acme/libfoo/archive/v1.2.11/libfoo-1.2.11.tar.gz", libfoo.Repository)
+	assert.Equal(t, "sha256:aaaa", libfoo.Checksum)
+	assert.Equal(t, "pkg:generic/libfoo@1.2.11?checksum=sha256:aaaa&download_url=https://github.com/acme/libfoo/archive/v1.2.11/libfoo-1.2.11.tar.gz", libfoo.PURL)
+	assert.Equal(t, "pkg:alpm/arch/ninja", deps[byName["ninja"]].PURL)
+
+	bar := deps[byName["bar"]]
+	assert.Equal(t, "https://gitlab.com/acme/bar.git", bar.Repository)
+	assert.Equal(t, "0123abcd", bar.Commit)
+	assert.Empty(t, bar.Checksum)
+	// 以提交固定的其他上游源码不继承软件包版本
+	assert.Empty(t, bar.Version)
+	assert.Equal(t, "pkg:generic/bar?vcs_url=git+https://gitlab.com/acme/bar.git@0123abcd", bar.PURL)
+}
+
+func TestPkgbuildExtractor_ExtractAPKBUILD(t *testing.T) {
+	tempDir := t.TempDir()
+	testFile := filepath.Join(tempDir, "APKBUILD")
+	content := `pkgname=libfoo
+pkgver=1.2.11
+depends="zlib so:libc.musl-x86_64.so.1"
+makedepends="cmake samurai !libfoo-old"
+source="https://example.org/releases/libfoo-$pkgver.tar.xz
+	musl-fix.patch
+	"
+sha512sums="
+cccc  libfoo-1.2.11.tar.xz
+dddd  musl-fix.patch
+"
+`
+	require.NoError(t, os.WriteFile(testFile, []byte(content), 0644))
+
+	deps, err := NewPkgbuildExtractor(testFile).Extract()
+	require.NoError(t, err)
+
+	names := make([]string, 0, len(deps))
+	for _, dep := range deps {
+		names = append(names, dep.Name)
+		assert.Equal(t, "abuild", dep.BuildSystem)
+	}
+	assert.Equal(t, []string{"zlib", "so:libc.musl-x86_64.so.1", "cmake", "samurai", "libfoo"}, names)
+
+	src := deps[len(deps)-1]
+	assert.Equal(t, "source", src.Type)
+	assert.Equal(t, "1.2.11", src.Version)
+	assert.Equal(t, "sha512:cccc", src.Checksum)
+}
+
+func TestParseShellAssignments_Expansion(t *testing.T) {
+	vars := parseShellAssignments(`pkgver=1.2.3
+_ver=${pkgver//./_}
+_major=${pkgver%%.*}
+_minor=${pkgver%.*}
+arr=(a "b c")
+arr+=(d)
+url='$pkgver'
+`)
+	assert.Equal(t, "1_2_3", vars.scalars["_ver"])
+	assert.Equal(t, "1", vars.scalars["_major"])
+	assert.Equal(t, "1.2", vars.scalars["_minor"])
+	assert.Equal(t, []string{"a", "b c", "d"}, vars.arrays["arr"])
+	assert.Equal(t, "$pkgver", vars.scalars["url"])
+}
+
+func TestURLHasVersion(t *testing.T) {
+	tests := map[string]bool{
+		"https://example.org/libfoo-1.2.11.tar.gz":             true,
+		"https://github.com/acme/libfoo/archive/v1.2.11/x.zip": true,
+		"https://example.org/libfoo-1.2.110.tar.gz":            false,
+		"https://example.org/libfoo-1.2.11.1.tar.gz":           false,
+		"git+https://gitlab.com/acme/bar.git":                  false,
+		"https://example.org/1.2.110/libfoo-1.2.11.tar.gz":     true,
+		"https://example.org/x1.2.11/libfoo.tar.gz":            false,
+	}
+	for url, want := range tests {
+		assert.Equal(t, want, urlHasVersion(url, "1.2.11"), url)
+	}
+}
@@ -3,7 +3,6 @@ package extractor
 import (
 	"bufio"
 	"fmt"
-	"os"
 	"regexp"
 	"strings"
 
@@ -27,7 +26,7 @@ func NewPkgConfigExtractor(path string) *PkgConfigExtractor {
 // Extract 提取PkgConfig依赖
 func (e *PkgConfigExtractor) Extract() ([]models.Dependency, error) {
 	// 读取.pc文件
-	file, err := os.Open(e.FilePath)
+	file, err := e.open(e.FilePath)
 	if err != nil {
 		return nil, NewExtractorError(PkgConfigExtractorType, e.FilePath, err.Error())
 	}
@@ -178,6 +177,7 @@ func (e *PkgConfigExtractor) Extract() ([]models.Dependency, error) {
 		deps = append(deps, *currentDep)
 	}
 
+	e.annotate(PkgConfigExtractorType, deps)
 	return deps, nil
 }
 
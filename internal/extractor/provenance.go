@@ -0,0 +1,195 @@
+package extractor
+
+import (
+	"bytes"
+	"io/fs"
+	"strings"
+
+	"github.com/lkpsg/ccscanner/pkg/models"
+)
+
+// ExtractorVersion 提取规则版本, 规则变化导致结果不同时递增, 便于比较不同时间的扫描结果
+const ExtractorVersion = "1.0.0"
+
+const (
+	maxSnippetLength = 200     // 源码片段最大长度
+	maxLocateSize    = 4 << 20 // 按名称定位时读取的最大文件大小
+)
+
+// lineProvenance 根据单行匹配结果生成来源信息
+// loc为匹配在行内的字节区间, 为空时只记录行号, 片段取整行
+func lineProvenance(typ ExtractorType, file string, lineNum int, line string, loc []int, rule string) *models.Provenance {
+	p := &models.Provenance{
+		File:             file,
+		StartLine:        lineNum,
+		EndLine:          lineNum,
+		Snippet:          snippet(line),
+		Extractor:        string(typ),
+		ExtractorVersion: ExtractorVersion,
+		Rule:             rule,
+	}
+	if len(loc) >= 2 && loc[0] >= 0 && loc[0] <= loc[1] && loc[1] <= len(line) {
+		p.StartColumn, p.EndColumn = loc[0]+1, loc[1]+1
+		p.Snippet = snippet(line[loc[0]:loc[1]])
+	}
+	return p
+}
+
+// setProvenance 补全提取器输出依赖的来源信息
+// 提取器未记录位置时, 在配置文件(从fsys读取, 为nil时读取本地磁盘)中查找依赖名称首次出现的位置
+func setProvenance(fsys fs.FS, typ ExtractorType, path string, deps []models.Dependency) {
+	locator := newLocator(fsys)
+	for i := range deps {
+		locator.fill(typ, path, &deps[i])
+	}
+}
+
+// locator 按文件缓存内容, 避免多个依赖重复读取同一文件
+type locator struct {
+	fsys     fs.FS
+	contents map[string][]byte
+}
+
+// newLocator 创建定位器
+func newLocator(fsys fs.FS) *locator {
+	return &locator{fsys: fsys, contents: make(map[string][]byte)}
+}
+
+// fill 补全单个依赖的来源信息, 已有的字段保持不变
+func (l *locator) fill(typ ExtractorType, path string, dep *models.Dependency) {
+	p := dep.Provenance
+	if p == nil {
+		p = &models.Provenance{}
+		dep.Provenance = p
+	}
+	if p.File == "" {
+		p.File = dep.ConfigFile
+	}
+	if p.File == "" {
+		p.File = path
+	}
+	if p.Extractor == "" {
+		p.Extractor = string(typ)
+	}
+	if p.ExtractorVersion == "" {
+		p.ExtractorVersion = ExtractorVersion
+	}
+	if p.Rule == "" {
+		p.Rule = string(typ)
+		if dep.Type != "" {
+			p.Rule += "." + dep.Type
+		}
+	}
+	if p.StartLine == 0 && p.File != "" {
+		l.locate(p, dep.Name)
+	}
+}
+
+// locate 在文件中查找名称首次出现的位置, 优先匹配完整单词, 忽略大小写
+func (l *locator) locate(p *models.Provenance, name string) {
+	content, ok := l.contents[p.File]
+	if !ok {
+		content = readText(l.fsys, p.File)
+		l.contents[p.File] = content
+	}
+	if len(content) == 0 || name == "" {
+		return
+	}
+
+	lower := bytes.ToLower(content)
+	needle := []byte(strings.ToLower(name))
+	offset := -1
+	for from := 0; from < len(lower); {
+		i := bytes.Index(lower[from:], needle)
+		if i < 0 {
+			break
+		}
+		i += from
+		if offset < 0 {
+			offset = i
+		}
+		if isWordBoundary(lower, i-1) && isWordBoundary(lower, i+len(needle)) {
+			offset = i
+			break
+		}
+		from = i + 1
+	}
+	if offset < 0 {
+		return
+	}
+
+	lineStart := bytes.LastIndexByte(content[:offset], '\n') + 1
+	lineEnd := bytes.IndexByte(content[offset:], '\n')
+	if lineEnd < 0 {
+		lineEnd = len(content)
+	} else {
+		lineEnd += offset
+	}
+	line := 1 + bytes.Count(content[:offset], []byte{'\n'})
+	p.StartLine, p.EndLine = line, line
+	p.StartColumn = offset - lineStart + 1
+	p.EndColumn = p.StartColumn + len(needle)
+	p.Snippet = snippet(string(content[lineStart:lineEnd]))
+}
+
+// sourceFile 取出Source字段中的文件路径
+// npm、Yarn、Maven提取器会在路径后附加 " (解析地址)"、" [校验和]" 或 " (profile: xxx)"
+func sourceFile(source string) string {
+	if i := strings.Index(source, " ("); i >= 0 {
+		source = source[:i]
+	}
+	if i := strings.Index(source, " ["); i >= 0 {
+		source = source[:i]
+	}
+	return source
+}
+
+// readText 读取文本文件, 目录、二进制文件和过大的文件返回空
+func readText(fsys fs.FS, path string) []byte {
+	info, err := statFS(fsys, path)
+	if err != nil || info.IsDir() || info.Size() > maxLocateSize {
+		return nil
+	}
+	content, err := readFileFS(fsys, path)
+	if err != nil {
+		return nil
+	}
+	head := content
+	if len(head) > 8192 {
+		head = head[:8192]
+	}
+	if bytes.IndexByte(head, 0) >= 0 {
+		return nil
+	}
+	return content
+}
+
+// isWordBoundary 判断位置i是否处于标识符之外
+func isWordBoundary(content []byte, i int) bool {
+	if i < 0 || i >= len(content) {
+		return true
+	}
+	c := content[i]
+	return !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '_' || c == '-')
+}
+
+// snippet 截取源码片段
+func snippet(text string) string {
+	text = strings.TrimSpace(text)
+	if len(text) > maxSnippetLength {
+		text = strings.ToValidUTF8(text[:maxSnippetLength], "") + "..."
+	}
+	return text
+}
+
+/*
+使用示例:
+
+line := `find_package(OpenSSL 3.0 REQUIRED)`
+p := lineProvenance(CMakeExtractorType, "CMakeLists.txt", 12, line, []int{0, len(line)}, "cmake.find_package")
+fmt.Println(p.String(), p.Snippet) // CMakeLists.txt:12:1 find_package(OpenSSL 3.0 REQUIRED)
+
+deps := []models.Dependency{{Name: "zlib", ConfigFile: "vcpkg.json"}}
+setProvenance(nil, VcpkgExtractorType, "vcpkg.json", deps)
+fmt.Println(deps[0].Location()) // vcpkg.json:4:7
+*/
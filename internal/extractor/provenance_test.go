@@ -0,0 +1,108 @@
+package extractor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lkpsg/ccscanner/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLineProvenance(t *testing.T) {
+	line := `  find_package(OpenSSL 3.0 REQUIRED)`
+	p := lineProvenance(CMakeExtractorType, "CMakeLists.txt", 7, line, []int{2, 22}, "cmake.find_package")
+	assert.Equal(t, 7, p.StartLine)
+	assert.Equal(t, 7, p.EndLine)
+	assert.Equal(t, 3, p.StartColumn)
+	assert.Equal(t, 23, p.EndColumn)
+	assert.Equal(t, "find_package(OpenSSL", p.Snippet)
+	assert.Equal(t, "cmake", p.Extractor)
+	assert.Equal(t, ExtractorVersion, p.ExtractorVersion)
+	assert.Equal(t, "CMakeLists.txt:7:3", p.String())
+
+	// 没有匹配区间时只记录行号
+	p = lineProvenance(BazelExtractorType, "WORKSPACE", 3, line, nil, "bazel.http_archive")
+	assert.Zero(t, p.StartColumn)
+	assert.Equal(t, "find_package(OpenSSL 3.0 REQUIRED)", p.Snippet)
+	assert.Equal(t, "WORKSPACE:3", p.String())
+}
+
+func TestSetProvenance(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "vcpkg.json")
+	content := `{
+  "name": "demo",
+  "dependencies": [
+    "zlib-ng",
+    "zlib",
+    { "name": "openssl", "version>=": "3.0.8" }
+  ]
+}
+`
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+
+	deps := []models.Dependency{
+		{Name: "zlib", Type: "library", ConfigFile: path},
+		{Name: "OpenSSL", ConfigFile: path},
+		{Name: "boost", ConfigFile: path},
+		{Name: "fmt", Provenance: &models.Provenance{File: "other.txt", StartLine: 2, Rule: "custom"}},
+	}
+	setProvenance(nil, VcpkgExtractorType, path, deps)
+
+	zlib := deps[0].Provenance
+	require.NotNil(t, zlib)
+	assert.Equal(t, path, zlib.File)
+	assert.Equal(t, 5, zlib.StartLine)
+	assert.Equal(t, 6, zlib.StartColumn)
+	assert.Equal(t, 10, zlib.EndColumn)
+	assert.Equal(t, `"zlib",`, zlib.Snippet)
+	assert.Equal(t, "vcpkg", zlib.Extractor)
+	assert.Equal(t, "vcpkg.library", zlib.Rule)
+
+	assert.Equal(t, 6, deps[1].Provenance.StartLine)
+	assert.Equal(t, "vcpkg", deps[1].Provenance.Rule)
+
+	// 文件中找不到名称时只记录文件
+	assert.Zero(t, deps[2].Provenance.StartLine)
+	assert.Equal(t, path, deps[2].Location())
+
+	// 提取器已记录的信息保持不变
+	assert.Equal(t, "other.txt:2", deps[3].Location())
+	assert.Equal(t, "custom", deps[3].Provenance.Rule)
+	assert.Equal(t, "vcpkg", deps[3].Provenance.Extractor)
+}
+
+func TestCMakeExtractor_Provenance(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "CMakeLists.txt")
+	content := "cmake_minimum_required(VERSION 3.16)\n" +
+		"find_package(ZLIB REQUIRED)\n" +
+		"target_link_libraries(app PRIVATE ssl crypto)\n"
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+
+	deps, err := NewCMakeExtractor(path).Extract()
+	require.NoError(t, err)
+
+	found := make(map[string]*models.Provenance)
+	for i := range deps {
+		found[deps[i].Name] = deps[i].Provenance
+	}
+	require.Contains(t, found, "ZLIB")
+	assert.Equal(t, "cmake.find_package", found["ZLIB"].Rule)
+	assert.Equal(t, 2, found["ZLIB"].StartLine)
+	assert.Equal(t, 1, found["ZLIB"].StartColumn)
+
+	require.Contains(t, found, "crypto")
+	assert.Equal(t, "cmake.target_link_libraries", found["crypto"].Rule)
+	assert.Equal(t, 3, found["crypto"].StartLine)
+	assert.Equal(t, 39, found["crypto"].StartColumn)
+	assert.Equal(t, "crypto", found["crypto"].Snippet)
+}
+
+func TestSourceFile(t *testing.T) {
+	assert.Equal(t, "package-lock.json", sourceFile("package-lock.json (https://registry.npmjs.org/a.tgz) [sha512-xx]"))
+	assert.Equal(t, "yarn.lock", sourceFile("yarn.lock [sha1-xx]"))
+	assert.Equal(t, "pom.xml", sourceFile("pom.xml"))
+}
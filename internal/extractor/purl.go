@@ -0,0 +1,225 @@
+package extractor
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/lkpsg/ccscanner/pkg/models"
+	"github.com/lkpsg/ccscanner/pkg/purl"
+)
+
+// npmExactVersionRe npm的确切版本号, 范围(^1.2.0、>=1 <2)、标签(latest)和地址都不匹配
+var npmExactVersionRe = regexp.MustCompile(`^\d+\.\d+\.\d+(?:-[0-9A-Za-z.-]+)?(?:\+[0-9A-Za-z.-]+)?$`)
+
+// setPackageURLs 为提取器输出的依赖填充purl
+func setPackageURLs(typ ExtractorType, deps []models.Dependency) {
+	for i := range deps {
+		if deps[i].PURL == "" {
+			deps[i].PURL = packageURL(typ, &deps[i])
+		}
+	}
+}
+
+// packageURL 根据提取器所属生态生成依赖的purl
+func packageURL(typ ExtractorType, dep *models.Dependency) string {
+	switch typ {
+	case ConanExtractorType:
+		return conanPackageURL(dep).String()
+	case VcpkgExtractorType:
+		return purl.New(purl.TypeGeneric, "vcpkg", dep.Name, dep.Version).String()
+	case ControlExtractorType:
+		return purl.New(purl.TypeDebian, "debian", dep.Name, dep.Version).String()
+	case PkgbuildExtractorType:
+		// source数组中重新打包的RPM按rpm类型处理, 其他源码地址按通用类型处理
+		if dep.Type == "source" {
+			if p := rpmPackageURL(dep); p != nil {
+				return p.String()
+			}
+		} else {
+			if dep.ConfigFileType == "APKBUILD" {
+				return purl.New(purl.TypeApk, "alpine", dep.Name, dep.Version).String()
+			}
+			return purl.New(purl.TypeAlpm, "arch", dep.Name, dep.Version).String()
+		}
+	case NixExtractorType:
+		// buildInputs中的包来自nixpkgs, 仓库地址指向的是nixpkgs本身
+		if dep.Type != "flake-input" {
+			return purl.New(purl.TypeGeneric, "nixpkgs", dep.Name, dep.Version).String()
+		}
+	}
+
+	if owner, repo, ok := githubRepository(dep.Repository); ok {
+		version := dep.Commit
+		if version == "" {
+			version = dep.Version
+		}
+		return purl.New(purl.TypeGitHub, owner, repo, version).String()
+	}
+	return genericPackageURL(dep).String()
+}
+
+// conanPackageURL 生成Conan包的purl, user/channel作为限定符
+func conanPackageURL(dep *models.Dependency) *purl.PackageURL {
+	version, user, _ := strings.Cut(dep.Version, "@")
+	channel := dep.Source
+	if u, c, ok := strings.Cut(channel, "/"); ok {
+		user, channel = u, c
+	}
+	return purl.New(purl.TypeConan, "", dep.Name, version).
+		WithQualifier("user", user).
+		WithQualifier("channel", channel)
+}
+
+// genericPackageURL 生成通用类型的purl
+// 可下载的源码包记录download_url, 其他Git仓库记录vcs_url
+func genericPackageURL(dep *models.Dependency) *purl.PackageURL {
+	p := purl.New(purl.TypeGeneric, "", dep.Name, dep.Version)
+	repository := strings.TrimPrefix(dep.Repository, "git+")
+	if isRemoteURL(repository) {
+		if isGitURL(dep.Repository) || dep.Commit != "" {
+			vcs := "git+" + repository
+			if dep.Commit != "" {
+				vcs += "@" + dep.Commit
+			}
+			p.WithQualifier("vcs_url", vcs)
+		} else {
+			p.WithQualifier("download_url", repository)
+		}
+	}
+	return p.WithQualifier("checksum", checksumQualifier(dep.Checksum))
+}
+
+// rpmPackageURL 根据 <name>-<version>-<release>.<arch>.rpm 形式的下载地址生成RPM包的purl, 不是RPM时返回nil
+func rpmPackageURL(dep *models.Dependency) *purl.PackageURL {
+	base := strings.TrimSuffix(path.Base(dep.Repository), ".rpm")
+	if base == path.Base(dep.Repository) {
+		return nil
+	}
+	dot := strings.LastIndex(base, ".")
+	if dot < 0 {
+		return nil
+	}
+	nvr, arch := base[:dot], base[dot+1:]
+	parts := strings.Split(nvr, "-")
+	if len(parts) < 3 {
+		return nil
+	}
+	name := strings.Join(parts[:len(parts)-2], "-")
+	version := parts[len(parts)-2] + "-" + parts[len(parts)-1]
+	return purl.New(purl.TypeRPM, "", name, version).
+		WithQualifier("arch", arch).
+		WithQualifier("download_url", dep.Repository).
+		WithQualifier("checksum", checksumQualifier(dep.Checksum))
+}
+
+// lockedVersion 返回锁定文件解析出的确切版本
+// package.json中的版本是范围(^1.2.0经cleanVersion后为1.2.0), 不能作为purl的版本, 返回空
+func lockedVersion(dep *models.Dependency) string {
+	if dep.Type != "locked" {
+		return ""
+	}
+	return dep.Version
+}
+
+// npmPackageURL 生成npm包的purl, 带作用域的包名拆分为命名空间
+// 只有确切的版本号写入purl, 版本范围、标签和地址省略版本
+func npmPackageURL(name, version string) string {
+	if !npmExactVersionRe.MatchString(version) {
+		version = ""
+	}
+	namespace := ""
+	if strings.HasPrefix(name, "@") {
+		if scope, rest, ok := strings.Cut(name, "/"); ok {
+			namespace, name = scope, rest
+		}
+	}
+	return purl.New(purl.TypeNPM, namespace, name, version).String()
+}
+
+// mavenPackageURL 生成Maven构件的purl, name为 groupId:artifactId[:classifier]
+func mavenPackageURL(name, version string) string {
+	parts := strings.Split(name, ":")
+	if len(parts) < 2 {
+		return purl.New(purl.TypeMaven, "", name, version).String()
+	}
+	p := purl.New(purl.TypeMaven, parts[0], parts[1], version)
+	if len(parts) > 2 {
+		p.WithQualifier("classifier", parts[2])
+	}
+	return p.String()
+}
+
+// cargoPackageURL 生成Rust crate的purl
+// Git依赖的 branch=xxx/rev=xxx 不是发布版本, 不写入purl
+func cargoPackageURL(name, version string) string {
+	if strings.Contains(version, "=") {
+		version = ""
+	}
+	return purl.New(purl.TypeCargo, "", name, version).String()
+}
+
+// githubRepository 从Git仓库地址中提取GitHub的owner和仓库名
+// 指向归档文件等非仓库地址时返回false
+func githubRepository(url string) (owner, repo string, ok bool) {
+	url = strings.TrimPrefix(url, "git+")
+	var rest string
+	switch {
+	case strings.HasPrefix(url, "git@github.com:"):
+		rest = strings.TrimPrefix(url, "git@github.com:")
+	case strings.HasPrefix(url, "https://github.com/"), strings.HasPrefix(url, "http://github.com/"),
+		strings.HasPrefix(url, "ssh://git@github.com/"), strings.HasPrefix(url, "git://github.com/"):
+		rest = url[strings.Index(url, "github.com/")+len("github.com/"):]
+	default:
+		return "", "", false
+	}
+	parts := strings.Split(strings.TrimSuffix(strings.Trim(rest, "/"), ".git"), "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
+
+// isRemoteURL 判断是否为远程地址
+func isRemoteURL(url string) bool {
+	for _, scheme := range []string{"https://", "http://", "ftp://", "git://", "ssh://"} {
+		if strings.HasPrefix(url, scheme) {
+			return true
+		}
+	}
+	return false
+}
+
+// isGitURL 判断地址是否指向Git仓库而不是归档文件
+func isGitURL(url string) bool {
+	return strings.HasPrefix(url, "git+") || strings.HasPrefix(url, "git://") ||
+		strings.HasPrefix(url, "ssh://") || strings.HasSuffix(url, ".git")
+}
+
+// checksumQualifier 转换为purl的 "算法:十六进制摘要" 格式
+// 支持 sha256:xxx 以及Nix使用的SRI格式(sha256-base64)
+func checksumQualifier(checksum string) string {
+	if algo, sum, ok := strings.Cut(checksum, ":"); ok && algo != "" && sum != "" {
+		return strings.ToLower(algo) + ":" + strings.ToLower(sum)
+	}
+	if algo, sum, ok := strings.Cut(checksum, "-"); ok && strings.HasPrefix(algo, "sha") {
+		if raw, err := base64.StdEncoding.DecodeString(sum); err == nil {
+			return algo + ":" + hex.EncodeToString(raw)
+		}
+	}
+	return ""
+}
+
+/*
+使用示例:
+
+dep := models.NewDependency("zlib")
+dep.Type = "source"
+dep.Version = "1.3"
+dep.Repository = "https://zlib.net/zlib-1.3.tar.gz"
+dep.Checksum = "sha256:ff0ba4c2"
+fmt.Println(packageURL(PkgbuildExtractorType, dep))
+// pkg:generic/zlib@1.3?checksum=sha256:ff0ba4c2&download_url=https://zlib.net/zlib-1.3.tar.gz
+*/
@@ -0,0 +1,83 @@
+package extractor
+
+import (
+	"testing"
+
+	"github.com/lkpsg/ccscanner/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPackageURL(t *testing.T) {
+	newDep := func(name, version string, setup func(*models.Dependency)) *models.Dependency {
+		dep := models.NewDependency(name)
+		dep.Version = version
+		if setup != nil {
+			setup(dep)
+		}
+		return dep
+	}
+
+	tests := []struct {
+		typ  ExtractorType
+		dep  *models.Dependency
+		want string
+	}{
+		{ConanExtractorType, newDep("openssl", "3.0.8", func(d *models.Dependency) { d.Source = "conan/stable" }),
+			"pkg:conan/openssl@3.0.8?channel=stable&user=conan"},
+		{VcpkgExtractorType, newDep("fmt", "10.1.0", nil), "pkg:generic/vcpkg/fmt@10.1.0"},
+		{ControlExtractorType, newDep("libssl-dev", "", nil), "pkg:deb/debian/libssl-dev"},
+		{PkgbuildExtractorType, newDep("openssl", "", func(d *models.Dependency) { d.ConfigFileType = "APKBUILD" }),
+			"pkg:apk/alpine/openssl"},
+		{PkgbuildExtractorType, newDep("zlib", "1.3", func(d *models.Dependency) {
+			d.Type = "source"
+			d.Repository = "https://zlib.net/zlib-1.3.tar.gz"
+			d.Checksum = "sha256:FF0BA4C2"
+		}), "pkg:generic/zlib@1.3?checksum=sha256:ff0ba4c2&download_url=https://zlib.net/zlib-1.3.tar.gz"},
+		{SubmoduleExtractorType, newDep("third_party/zlib", "1.3", func(d *models.Dependency) {
+			d.Repository = "https://github.com/madler/zlib.git"
+			d.Commit = "09155eaa2f9270dc4ed1fa13e2b4b2613e6e4851"
+		}), "pkg:github/madler/zlib@09155eaa2f9270dc4ed1fa13e2b4b2613e6e4851"},
+		{SubmoduleExtractorType, newDep("libfoo", "", func(d *models.Dependency) {
+			d.Repository = "https://git.example.com/libfoo.git"
+			d.Commit = "abc123"
+		}), "pkg:generic/libfoo?vcs_url=git+https://git.example.com/libfoo.git@abc123"},
+		{NixExtractorType, newDep("openssl", "", func(d *models.Dependency) {
+			d.Type = "build"
+			d.Repository = "https://github.com/NixOS/nixpkgs"
+		}), "pkg:generic/nixpkgs/openssl"},
+		{NixExtractorType, newDep("flake-utils", "", func(d *models.Dependency) {
+			d.Type = "flake-input"
+			d.Repository = "https://example.com/flake-utils.tar.gz"
+			d.Checksum = "sha256-AAECAw=="
+		}), "pkg:generic/flake-utils?checksum=sha256:00010203&download_url=https://example.com/flake-utils.tar.gz"},
+		{PkgbuildExtractorType, newDep("google-chrome-stable", "120.0.6099.109", func(d *models.Dependency) {
+			d.Type = "source"
+			d.Repository = "https://dl.google.com/linux/chrome/rpm/stable/x86_64/google-chrome-stable-120.0.6099.109-1.x86_64.rpm"
+			d.Checksum = "sha256:ab12"
+		}), "pkg:rpm/google-chrome-stable@120.0.6099.109-1?arch=x86_64&checksum=sha256:ab12" +
+			"&download_url=https://dl.google.com/linux/chrome/rpm/stable/x86_64/google-chrome-stable-120.0.6099.109-1.x86_64.rpm"},
+		{ElfExtractorType, newDep("glibc", "", nil), "pkg:generic/glibc"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, packageURL(tt.typ, tt.dep), tt.dep.Name)
+	}
+}
+
+func TestEcosystemPackageURLs(t *testing.T) {
+	assert.Equal(t, "pkg:npm/%40types/node@20.1.0", npmPackageURL("@types/node", "20.1.0"))
+	assert.Equal(t, "pkg:npm/lodash@4.17.21", npmPackageURL("lodash", "4.17.21"))
+	// 版本范围和非版本号的写法省略版本
+	assert.Equal(t, "pkg:npm/lodash", npmPackageURL("lodash", "^4.17.0"))
+	assert.Equal(t, "pkg:npm/lodash", npmPackageURL("lodash", ">=4 <5"))
+	assert.Equal(t, "pkg:npm/lodash", npmPackageURL("lodash", "latest"))
+	assert.Equal(t, "pkg:npm/react@19.0.0-rc.1", npmPackageURL("react", "19.0.0-rc.1"))
+	// package.json中经cleanVersion去掉范围前缀的版本不写入purl, 锁定文件的版本写入
+	manifest := models.Dependency{Name: "lodash", Version: "4.17.0", Type: "production"}
+	assert.Equal(t, "pkg:npm/lodash", npmPackageURL(manifest.Name, lockedVersion(&manifest)))
+	locked := models.Dependency{Name: "lodash", Version: "4.17.21", Type: "locked"}
+	assert.Equal(t, "pkg:npm/lodash@4.17.21", npmPackageURL(locked.Name, lockedVersion(&locked)))
+	assert.Equal(t, "pkg:maven/org.slf4j/slf4j-api@2.0.9?classifier=sources",
+		mavenPackageURL("org.slf4j:slf4j-api:sources", "2.0.9"))
+	assert.Equal(t, "pkg:cargo/serde@1.0.188", cargoPackageURL("serde", "1.0.188"))
+	assert.Equal(t, "pkg:cargo/mycrate", cargoPackageURL("mycrate", "branch=main"))
+}
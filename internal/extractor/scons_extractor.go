@@ -73,10 +73,9 @@ func (e *SconsExtractor) Extract(projectPath string, filePath string) ([]models.
 			deps := extractEnvironmentDeps(envConfig)
 			for _, dep := range deps {
 				dependencies = append(dependencies, models.Dependency{
-					Name:     dep,
-					Type:     "scons_env",
-					FilePath: filePath,
-					Line:     lineNum,
+					Name:       dep,
+					Type:       "scons_env",
+					Provenance: lineProvenance(SConsExtractorType, filePath, lineNum, line, nil, "scons.env"),
 				})
 			}
 			continue
@@ -88,10 +87,9 @@ func (e *SconsExtractor) Extract(projectPath string, filePath string) ([]models.
 			deps := extractListItems(matches[2])
 			for _, dep := range deps {
 				dependencies = append(dependencies, models.Dependency{
-					Name:     dep,
-					Type:     "scons_depends",
-					FilePath: filePath,
-					Line:     lineNum,
+					Name:       dep,
+					Type:       "scons_depends",
+					Provenance: lineProvenance(SConsExtractorType, filePath, lineNum, line, nil, "scons.depends"),
 					Parent:   target,
 				})
 			}
@@ -104,10 +102,9 @@ func (e *SconsExtractor) Extract(projectPath string, filePath string) ([]models.
 			deps := extractListItems(matches[2])
 			for _, dep := range deps {
 				dependencies = append(dependencies, models.Dependency{
-					Name:     dep,
-					Type:     "scons_requires",
-					FilePath: filePath,
-					Line:     lineNum,
+					Name:       dep,
+					Type:       "scons_requires",
+					Provenance: lineProvenance(SConsExtractorType, filePath, lineNum, line, nil, "scons.requires"),
 					Parent:   target,
 				})
 			}
@@ -117,10 +114,9 @@ func (e *SconsExtractor) Extract(projectPath string, filePath string) ([]models.
 		// 处理 Import 声明
 		if matches := importRegex.FindStringSubmatch(line); len(matches) > 1 {
 			dep := models.Dependency{
-				Name:     matches[1],
-				Type:     "scons_import",
-				FilePath: filePath,
-				Line:     lineNum,
+				Name:       matches[1],
+				Type:       "scons_import",
+				Provenance: lineProvenance(SConsExtractorType, filePath, lineNum, line, nil, "scons.import"),
 			}
 			dependencies = append(dependencies, dep)
 			continue
@@ -129,10 +125,9 @@ func (e *SconsExtractor) Extract(projectPath string, filePath string) ([]models.
 		// 处理 SConscript 声明
 		if matches := sconscriptRegex.FindStringSubmatch(line); len(matches) > 1 {
 			dep := models.Dependency{
-				Name:     matches[1],
-				Type:     "scons_script",
-				FilePath: filePath,
-				Line:     lineNum,
+				Name:       matches[1],
+				Type:       "scons_script",
+				Provenance: lineProvenance(SConsExtractorType, filePath, lineNum, line, nil, "scons.script"),
 			}
 			dependencies = append(dependencies, dep)
 			continue
@@ -144,10 +139,9 @@ func (e *SconsExtractor) Extract(projectPath string, filePath string) ([]models.
 			sources := extractListItems(matches[2])
 			for _, source := range sources {
 				dep := models.Dependency{
-					Name:     source,
-					Type:     "scons_library",
-					FilePath: filePath,
-					Line:     lineNum,
+					Name:       source,
+					Type:       "scons_library",
+					Provenance: lineProvenance(SConsExtractorType, filePath, lineNum, line, nil, "scons.library"),
 					Parent:   target,
 				}
 				dependencies = append(dependencies, dep)
@@ -161,10 +155,9 @@ func (e *SconsExtractor) Extract(projectPath string, filePath string) ([]models.
 			sources := extractListItems(matches[2])
 			for _, source := range sources {
 				dep := models.Dependency{
-					Name:     source,
-					Type:     "scons_program",
-					FilePath: filePath,
-					Line:     lineNum,
+					Name:       source,
+					Type:       "scons_program",
+					Provenance: lineProvenance(SConsExtractorType, filePath, lineNum, line, nil, "scons.program"),
 					Parent:   target,
 				}
 				dependencies = append(dependencies, dep)
@@ -179,10 +172,9 @@ func (e *SconsExtractor) Extract(projectPath string, filePath string) ([]models.
 				pkgs := extractPkgConfigPackages(command)
 				for _, pkg := range pkgs {
 					dep := models.Dependency{
-						Name:     pkg,
-						Type:     "scons_pkg_config",
-						FilePath: filePath,
-						Line:     lineNum,
+						Name:       pkg,
+						Type:       "scons_pkg_config",
+						Provenance: lineProvenance(SConsExtractorType, filePath, lineNum, line, nil, "scons.pkg_config"),
 					}
 					dependencies = append(dependencies, dep)
 				}
@@ -195,6 +187,8 @@ func (e *SconsExtractor) Extract(projectPath string, filePath string) ([]models.
 		return nil, fmt.Errorf("error scanning file %s: %v", filePath, err)
 	}
 
+	setConfidence(SConsExtractorType, dependencies)
+	setScope(SConsExtractorType, dependencies)
 	return dependencies, nil
 }
 
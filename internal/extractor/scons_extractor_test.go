@@ -111,7 +111,7 @@ env.ParseConfig('pkg-config --cflags --libs openssl')
 		assert.True(t, ok, "Unexpected dependency: %s", dep.Name)
 		assert.Equal(t, expected.Type, dep.Type)
 		assert.Equal(t, expected.Parent, dep.Parent)
-		assert.Equal(t, testFile, dep.FilePath)
+		assert.Equal(t, testFile, dep.Provenance.File)
 	}
 }
 
@@ -153,7 +153,7 @@ env.Library(lib_dir + '/mylib', [src_dir + '/' + s for s in sources])
 		assert.True(t, ok, "Unexpected dependency: %s", dep.Name)
 		assert.Equal(t, expected.Type, dep.Type)
 		assert.Equal(t, expected.Parent, dep.Parent)
-		assert.Equal(t, testFile, dep.FilePath)
+		assert.Equal(t, testFile, dep.Provenance.File)
 	}
 }
 
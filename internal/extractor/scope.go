@@ -0,0 +1,109 @@
+package extractor
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/lkpsg/ccscanner/pkg/models"
+)
+
+// typeScope 按提取器和依赖类型确定的范围, 键为 "提取器/依赖类型", 未收录的类型按运行时依赖处理
+var typeScope = map[string]string{
+	"npm/development":           models.ScopeDev,
+	"npm/optional":              models.ScopeOptional,
+	"yarn/development":          models.ScopeDev,
+	"yarn/optional":             models.ScopeOptional,
+	"maven/parent":              models.ScopeBuild,
+	"vcpkg/feature":             models.ScopeOptional,
+	"nix/build":                 models.ScopeTool,
+	"nix/check":                 models.ScopeTest,
+	"cmake/module":              models.ScopeBuild,
+	"make/include":              models.ScopeBuild,
+	"autoconf/program":          models.ScopeTool,
+	"autoconf/build_system":     models.ScopeTool,
+	"gradle/gradle_plugin":      models.ScopeTool,
+	"gradle/gradle_include_dir": models.ScopeBuild,
+	"ninja/ninja_include":       models.ScopeBuild,
+	"ninja/ninja_subninja":      models.ScopeBuild,
+	"scons/scons_import":        models.ScopeBuild,
+	"scons/scons_script":        models.ScopeBuild,
+}
+
+// testFrameworks 常见的C/C++测试框架, 构建脚本中引用它们通常只为了编译测试
+var testFrameworks = map[string]bool{
+	"gtest":                     true,
+	"gtest_main":                true,
+	"googletest":                true,
+	"gmock":                     true,
+	"gmock_main":                true,
+	"catch2":                    true,
+	"doctest":                   true,
+	"cppunit":                   true,
+	"cpputest":                  true,
+	"cmocka":                    true,
+	"criterion":                 true,
+	"boost_unit_test_framework": true,
+}
+
+// testDirs 测试代码所在目录的常见名称
+var testDirs = map[string]bool{
+	"test":       true,
+	"tests":      true,
+	"testing":    true,
+	"unittest":   true,
+	"unittests":  true,
+	"unit_tests": true,
+}
+
+// setScope 为提取器输出的依赖确定范围, 提取器已标注的范围统一为标准名称
+func setScope(typ ExtractorType, deps []models.Dependency) {
+	for i := range deps {
+		classifyScope(typ, &deps[i])
+	}
+}
+
+// classifyScope 依次根据提取器标注、依赖类型、测试框架/测试目录和可选标记确定依赖范围
+func classifyScope(typ ExtractorType, dep *models.Dependency) {
+	if scope := models.NormalizeScope(dep.Scope); scope != "" {
+		dep.Scope = scope
+		return
+	}
+	if scope, ok := typeScope[string(typ)+"/"+dep.Type]; ok {
+		dep.Scope = scope
+		return
+	}
+
+	switch {
+	case testFrameworks[strings.ToLower(dep.Name)] || inTestDir(dep):
+		dep.Scope = models.ScopeTest
+	case dep.Optional:
+		dep.Scope = models.ScopeOptional
+	default:
+		dep.Scope = models.ScopeRuntime
+	}
+}
+
+// inTestDir 判断依赖是否声明在测试目录下的构建文件中(如 tests/CMakeLists.txt)
+// 只检查文件所在的目录, 避免整个项目位于名为 test 的目录下时全部被当作测试依赖
+func inTestDir(dep *models.Dependency) bool {
+	file := dep.ConfigFile
+	if dep.Provenance != nil && dep.Provenance.File != "" {
+		file = dep.Provenance.File
+	}
+	if file == "" {
+		return false
+	}
+	return testDirs[strings.ToLower(filepath.Base(filepath.Dir(file)))]
+}
+
+/*
+使用示例:
+
+deps := []models.Dependency{
+	{Name: "GTest", Type: "package"},
+	{Name: "OpenSSL", Type: "package", ConfigFile: "CMakeLists.txt"},
+	{Name: "junit:junit", Scope: "provided"},
+}
+setScope(CMakeExtractorType, deps)
+fmt.Println(deps[0].Scope, deps[1].Scope, deps[2].Scope) // test runtime build
+*/
@@ -0,0 +1,110 @@
+package extractor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lkpsg/ccscanner/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetScope(t *testing.T) {
+	deps := []models.Dependency{
+		{Name: "OpenSSL", Type: "package", ConfigFile: "CMakeLists.txt"},
+		{Name: "GTest", Type: "package", ConfigFile: "CMakeLists.txt"},
+		{Name: "fmt", Type: "package", ConfigFile: filepath.Join("src", "tests", "CMakeLists.txt")},
+		{Name: "CTest", Type: "module", ConfigFile: "CMakeLists.txt"},
+		{Name: "zstd", Type: "package", Optional: true},
+		{Name: "junit", Scope: "provided"},
+	}
+	setScope(CMakeExtractorType, deps)
+
+	assert.Equal(t, models.ScopeRuntime, deps[0].Scope)
+	assert.Equal(t, models.ScopeTest, deps[1].Scope)
+	assert.Equal(t, models.ScopeTest, deps[2].Scope)
+	assert.Equal(t, models.ScopeBuild, deps[3].Scope)
+	assert.Equal(t, models.ScopeOptional, deps[4].Scope)
+	assert.Equal(t, models.ScopeBuild, deps[5].Scope)
+
+	npmDeps := []models.Dependency{{Name: "jest", Type: "development"}, {Name: "fsevents", Type: "optional"}}
+	setScope(NPMExtractorType, npmDeps)
+	assert.Equal(t, models.ScopeDev, npmDeps[0].Scope)
+	assert.Equal(t, models.ScopeOptional, npmDeps[1].Scope)
+}
+
+func TestNormalizeScope(t *testing.T) {
+	assert.Equal(t, models.ScopeRuntime, models.NormalizeScope("compile"))
+	assert.Equal(t, models.ScopeBuild, models.NormalizeScope("provided"))
+	assert.Equal(t, models.ScopeDev, models.NormalizeScope("devDependencies"))
+	assert.Equal(t, models.ScopeBuild, models.NormalizeScope("build-dependencies"))
+	assert.Equal(t, models.ScopeTool, models.NormalizeScope("tool_requires"))
+	assert.Equal(t, models.ScopeTest, models.NormalizeScope(" Test "))
+	assert.Empty(t, models.NormalizeScope("dependencies"))
+
+	assert.Equal(t, models.ScopeRuntime, (&models.Dependency{}).EffectiveScope())
+}
+
+func TestConanExtractor_Scope(t *testing.T) {
+	dir := t.TempDir()
+	txt := filepath.Join(dir, "conanfile.txt")
+	require.NoError(t, os.WriteFile(txt, []byte("[requires]\nzlib/1.3\n\n[tool_requires]\ncmake/3.27.7\n\n[test_requires]\ngtest/1.14.0\n\n[generators]\nCMakeDeps\n"), 0644))
+
+	deps, err := NewConanExtractor(txt).Extract()
+	require.NoError(t, err)
+	scopes := make(map[string]string)
+	for _, dep := range deps {
+		scopes[dep.Name] = dep.Scope
+	}
+	assert.Equal(t, map[string]string{
+		"zlib":  models.ScopeRuntime,
+		"cmake": models.ScopeTool,
+		"gtest": models.ScopeTest,
+	}, scopes)
+
+	py := filepath.Join(dir, "conanfile.py")
+	content := `class App(ConanFile):
+    requires = "openssl/3.1.4"
+    tool_requires = "ninja/1.11.1"
+
+    def build_requirements(self):
+        self.test_requires("catch2/3.4.0")
+`
+	require.NoError(t, os.WriteFile(py, []byte(content), 0644))
+
+	deps, err = NewConanExtractor(py).Extract()
+	require.NoError(t, err)
+	scopes = make(map[string]string)
+	for _, dep := range deps {
+		scopes[dep.Name] = dep.Scope
+	}
+	assert.Equal(t, map[string]string{
+		"openssl": models.ScopeRuntime,
+		"ninja":   models.ScopeTool,
+		"catch2":  models.ScopeTest,
+	}, scopes)
+}
+
+func TestControlExtractor_Scope(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "demo.dsc")
+	content := "Source: demo\n" +
+		"Build-Depends: debhelper (>= 9),\n" +
+		" cmake\n" +
+		"\n" +
+		"Package: demo\n" +
+		"Depends: libssl3\n" +
+		"Recommends: ca-certificates\n"
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+
+	deps, err := NewControlExtractor(path).Extract()
+	require.NoError(t, err)
+	scopes := make(map[string]string)
+	for _, dep := range deps {
+		scopes[dep.Name] = dep.Scope
+	}
+	assert.Equal(t, models.ScopeBuild, scopes["debhelper"])
+	assert.Equal(t, models.ScopeBuild, scopes["cmake"])
+	assert.Equal(t, models.ScopeRuntime, scopes["libssl3"])
+	assert.Equal(t, models.ScopeOptional, scopes["ca-certificates"])
+}
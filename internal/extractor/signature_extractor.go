@@ -0,0 +1,562 @@
+package extractor
+
+import (
+	"bytes"
+	"debug/elf"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/lkpsg/ccscanner/pkg/models"
+)
+
+// StaticSignature 静态链接库的特征签名
+type StaticSignature struct {
+	Name                 string   `json:"name"`                           // 库名称
+	Patterns             []string `json:"patterns,omitempty"`             // 单独即可确认的版本字符串正则(第一个分组为版本号)
+	Symbols              []string `json:"symbols,omitempty"`              // 库中定义的特征符号
+	SymbolVersionPattern string   `json:"symbolVersionPattern,omitempty"` // 找到特征符号后用于提取版本号的正则
+	Sonames              []string `json:"sonames,omitempty"`              // 库自身共享库的DT_SONAME前缀(如 libz.so), 扫描到库本身时不算静态链接
+}
+
+// compiledSignature 预编译的签名
+type compiledSignature struct {
+	StaticSignature
+	patterns      []*regexp.Regexp
+	symbolVersion *regexp.Regexp
+}
+
+//go:embed data/static_signatures.json
+var defaultStaticSignatures []byte
+
+var (
+	staticSignatures   []compiledSignature
+	staticSignaturesMu sync.RWMutex
+)
+
+// arMagic ar归档文件魔数
+const arMagic = "!<arch>\n"
+
+func init() {
+	var signatures []StaticSignature
+	if err := json.Unmarshal(defaultStaticSignatures, &signatures); err != nil {
+		panic(fmt.Sprintf("invalid static signature data: %v", err))
+	}
+	for _, sig := range signatures {
+		compiled, err := compileSignature(sig)
+		if err != nil {
+			panic(fmt.Sprintf("invalid static signature %s: %v", sig.Name, err))
+		}
+		staticSignatures = append(staticSignatures, compiled)
+	}
+}
+
+// LoadStaticSignatures 从JSON文件加载额外的签名,同名签名会被替换
+func LoadStaticSignatures(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var signatures []StaticSignature
+	if err := json.Unmarshal(data, &signatures); err != nil {
+		return fmt.Errorf("failed to parse signature file %s: %v", path, err)
+	}
+
+	compiled := make([]compiledSignature, 0, len(signatures))
+	for _, sig := range signatures {
+		c, err := compileSignature(sig)
+		if err != nil {
+			return fmt.Errorf("invalid signature %s in %s: %v", sig.Name, path, err)
+		}
+		compiled = append(compiled, c)
+	}
+
+	staticSignaturesMu.Lock()
+	defer staticSignaturesMu.Unlock()
+	for _, sig := range compiled {
+		replaced := false
+		for i := range staticSignatures {
+			if staticSignatures[i].Name == sig.Name {
+				staticSignatures[i] = sig
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			staticSignatures = append(staticSignatures, sig)
+		}
+	}
+	return nil
+}
+
+// compileSignature 编译签名中的正则表达式
+func compileSignature(sig StaticSignature) (compiledSignature, error) {
+	compiled := compiledSignature{StaticSignature: sig}
+	for _, pattern := range sig.Patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return compiled, err
+		}
+		compiled.patterns = append(compiled.patterns, re)
+	}
+	if sig.SymbolVersionPattern != "" {
+		re, err := regexp.Compile(sig.SymbolVersionPattern)
+		if err != nil {
+			return compiled, err
+		}
+		compiled.symbolVersion = re
+	}
+	return compiled, nil
+}
+
+// SignatureExtractor 静态链接库签名提取器
+//
+// 静态链接的库不会出现在 DT_NEEDED 中, 这里通过扫描ELF文件和.a归档中的
+// 只读数据段(版本字符串)和符号表(特征符号)识别这些库。
+type SignatureExtractor struct {
+	BaseExtractor
+	treeFilter
+	config ExtractorConfig
+}
+
+// NewSignatureExtractor 创建静态链接库签名提取器, path为构建输出目录或单个文件
+func NewSignatureExtractor(path string) *SignatureExtractor {
+	return &SignatureExtractor{
+		BaseExtractor: NewBaseExtractor(path),
+		config:        DefaultConfig,
+	}
+}
+
+// SignatureMatch 一次签名匹配
+type SignatureMatch struct {
+	Name     string // 库名称
+	Version  string // 版本号
+	File     string // 文件路径(归档成员为 path(member))
+	Section  string // 所在段, 符号匹配时为 symtab
+	Offset   int64  // 在文件(或归档成员)中的偏移
+	Evidence string // 匹配内容
+}
+
+// String 返回证据描述
+func (m SignatureMatch) String() string {
+	return fmt.Sprintf("%s:%s+0x%x: %s", m.File, m.Section, m.Offset, m.Evidence)
+}
+
+// Extract 扫描二进制文件中的静态链接库签名
+func (e *SignatureExtractor) Extract() ([]models.Dependency, error) {
+	root := e.FilePath
+	info, err := e.stat(root)
+	if err != nil {
+		return nil, NewExtractorError(SignatureExtractorType, root, err.Error())
+	}
+
+	var matches []SignatureMatch
+	if !info.IsDir() {
+		matches, err = scanStaticSignatures(e.fsys, root)
+		if err != nil {
+			return nil, NewExtractorError(SignatureExtractorType, root, err.Error())
+		}
+		root = filepath.Dir(root)
+	} else {
+		err = walkFS(e.fsys, root, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return nil
+			}
+			if e.skip(root, path, info) {
+				if info.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if info.IsDir() {
+				if path != root && strings.HasPrefix(info.Name(), ".") {
+					return filepath.SkipDir
+				}
+				if e.config.MaxDepth > 0 && pathDepth(root, path) > e.config.MaxDepth {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !(strings.HasSuffix(info.Name(), ".a") && info.Mode().IsRegular()) && !maybeElfFile(info) {
+				return nil
+			}
+			if found, err := scanStaticSignatures(e.fsys, path); err == nil {
+				matches = append(matches, found...)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, NewExtractorError(SignatureExtractorType, root, err.Error())
+		}
+	}
+
+	type signatureUsage struct {
+		version  string
+		files    map[string]bool
+		evidence []string
+	}
+	usages := make(map[string]*signatureUsage)
+	for _, match := range matches {
+		usage, ok := usages[match.Name]
+		if !ok {
+			usage = &signatureUsage{files: make(map[string]bool)}
+			usages[match.Name] = usage
+		}
+		if usage.version == "" {
+			usage.version = match.Version
+		}
+		file := match.File
+		if rel, err := filepath.Rel(root, file); err == nil && !strings.HasPrefix(rel, "..") {
+			file = filepath.ToSlash(rel)
+		}
+		usage.files[file] = true
+		usage.evidence = append(usage.evidence, match.String())
+	}
+
+	names := make([]string, 0, len(usages))
+	for name := range usages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	deps := make([]models.Dependency, 0, len(names))
+	for _, name := range names {
+		usage := usages[name]
+		dep := models.NewDependency(name)
+		dep.Version = usage.version
+		dep.Type = "static"
+		dep.DetectedBy = "SignatureExtractor"
+		dep.ConfigFile = root
+		dep.ConfigFileType = "binary"
+		dep.Metadata = map[string]interface{}{
+			"linkage":  "static",
+			"found_in": sortedKeys(usage.files),
+			"evidence": truncateStrings(usage.evidence, maxIncludeEvidence),
+		}
+		deps = append(deps, *dep)
+	}
+
+	e.annotate(SignatureExtractorType, deps)
+	return deps, nil
+}
+
+// ScanStaticSignatures 扫描单个ELF文件或.a归档中的静态库签名
+func ScanStaticSignatures(path string) ([]SignatureMatch, error) {
+	return scanStaticSignatures(nil, path)
+}
+
+// scanStaticSignatures 扫描fsys中的单个ELF文件或.a归档, fsys为nil时读取本地磁盘
+func scanStaticSignatures(fsys fs.FS, path string) ([]SignatureMatch, error) {
+	file, err := openRandomAccess(fsys, path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	magic := make([]byte, len(arMagic))
+	n, _ := io.ReadFull(file, magic)
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	staticSignaturesMu.RLock()
+	signatures := append([]compiledSignature(nil), staticSignatures...)
+	staticSignaturesMu.RUnlock()
+
+	switch {
+	case n == len(arMagic) && string(magic) == arMagic:
+		members, err := readArMembers(file)
+		if err != nil {
+			return nil, err
+		}
+		var matches []SignatureMatch
+		for _, member := range members {
+			f, err := elf.NewFile(bytes.NewReader(member.data))
+			if err != nil {
+				continue
+			}
+			matches = append(matches, scanElfSignatures(f, path+"("+member.name+")", signatures)...)
+		}
+		return dedupSignatureMatches(matches), nil
+	case n >= 4 && string(magic[:4]) == elf.ELFMAG:
+		f, err := elf.NewFile(file)
+		if err != nil {
+			return nil, err
+		}
+		return dedupSignatureMatches(scanElfSignatures(f, path, signatures)), nil
+	}
+	return nil, fmt.Errorf("not an ELF file or ar archive")
+}
+
+// scanElfSignatures 在ELF的数据段和符号表中匹配签名
+func scanElfSignatures(f *elf.File, name string, signatures []compiledSignature) []SignatureMatch {
+	type section struct {
+		name   string
+		offset int64
+		data   []byte
+	}
+	var sections []section
+	for _, s := range f.Sections {
+		if s.Type != elf.SHT_PROGBITS || s.Flags&elf.SHF_EXECINSTR != 0 {
+			continue
+		}
+		if !strings.HasPrefix(s.Name, ".rodata") && !strings.HasPrefix(s.Name, ".data") {
+			continue
+		}
+		data, err := s.Data()
+		if err != nil {
+			continue
+		}
+		sections = append(sections, section{name: s.Name, offset: int64(s.Offset), data: data})
+	}
+
+	defined := make(map[string]bool)
+	if symbols, err := f.Symbols(); err == nil {
+		for _, sym := range symbols {
+			if sym.Section != elf.SHN_UNDEF && sym.Name != "" {
+				defined[sym.Name] = true
+			}
+		}
+	}
+
+	// 共享库本身(如 libz.so.1)定义了库的全部符号, 不是静态链接了该库
+	soname := ""
+	if names, err := f.DynString(elf.DT_SONAME); err == nil && len(names) > 0 {
+		soname = names[0]
+	}
+
+	var matches []SignatureMatch
+	for _, sig := range signatures {
+		if sig.isLibrary(soname) {
+			continue
+		}
+		found := false
+		for _, re := range sig.patterns {
+			for _, s := range sections {
+				loc := re.FindSubmatchIndex(s.data)
+				if loc == nil {
+					continue
+				}
+				match := SignatureMatch{
+					Name:     sig.Name,
+					File:     name,
+					Section:  s.name,
+					Offset:   s.offset + int64(loc[0]),
+					Evidence: printableString(s.data[loc[0]:loc[1]]),
+				}
+				if len(loc) >= 4 && loc[2] >= 0 {
+					match.Version = string(s.data[loc[2]:loc[3]])
+				}
+				matches = append(matches, match)
+				found = true
+				break
+			}
+			if found {
+				break
+			}
+		}
+		if found {
+			continue
+		}
+
+		for _, symbol := range sig.Symbols {
+			if !defined[symbol] {
+				continue
+			}
+			match := SignatureMatch{
+				Name:     sig.Name,
+				File:     name,
+				Section:  "symtab",
+				Evidence: symbol,
+			}
+			if sig.symbolVersion != nil {
+				for _, s := range sections {
+					if loc := sig.symbolVersion.FindSubmatchIndex(s.data); loc != nil && len(loc) >= 4 && loc[2] >= 0 {
+						match.Version = string(s.data[loc[2]:loc[3]])
+						match.Section = s.name
+						match.Offset = s.offset + int64(loc[2])
+						match.Evidence = symbol + " " + match.Version
+						break
+					}
+				}
+			}
+			matches = append(matches, match)
+			break
+		}
+	}
+	return matches
+}
+
+// isLibrary 判断DT_SONAME是否为签名对应的库自身
+func (sig compiledSignature) isLibrary(soname string) bool {
+	if soname == "" {
+		return false
+	}
+	for _, prefix := range sig.Sonames {
+		if strings.HasPrefix(soname, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// dedupSignatureMatches 同一库在同一文件中只保留一个匹配, 优先保留带版本的匹配
+func dedupSignatureMatches(matches []SignatureMatch) []SignatureMatch {
+	result := make([]SignatureMatch, 0, len(matches))
+	index := make(map[string]int)
+	for _, match := range matches {
+		key := match.Name + "\x00" + match.File
+		if i, ok := index[key]; ok {
+			if result[i].Version == "" && match.Version != "" {
+				result[i] = match
+			}
+			continue
+		}
+		index[key] = len(result)
+		result = append(result, match)
+	}
+	return result
+}
+
+// printableString 将匹配内容中的不可打印字符替换为空格
+func printableString(data []byte) string {
+	return strings.TrimSpace(strings.Map(func(r rune) rune {
+		if r < 0x20 || r > 0x7e {
+			return ' '
+		}
+		return r
+	}, string(data)))
+}
+
+// arMember ar归档中的成员文件
+type arMember struct {
+	name string
+	data []byte
+}
+
+// readArMembers 读取ar归档中的目标文件(支持GNU和BSD长文件名)
+func readArMembers(r io.Reader) ([]arMember, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.HasPrefix(data, []byte(arMagic)) {
+		return nil, fmt.Errorf("not an ar archive")
+	}
+
+	var members []arMember
+	var longNames []byte
+	pos := len(arMagic)
+	for pos+60 <= len(data) {
+		header := data[pos : pos+60]
+		name := strings.TrimRight(string(header[0:16]), " ")
+		size, err := strconv.ParseInt(strings.TrimSpace(string(header[48:58])), 10, 64)
+		if err != nil || size < 0 {
+			return members, fmt.Errorf("invalid ar header at offset %d", pos)
+		}
+		pos += 60
+		if pos+int(size) > len(data) {
+			return members, fmt.Errorf("truncated ar member %s", name)
+		}
+		body := data[pos : pos+int(size)]
+		pos += int(size)
+		if pos%2 == 1 {
+			pos++ // 成员按2字节对齐
+		}
+
+		switch {
+		case name == "/" || name == "/SYM64/" || name == "__.SYMDEF" || name == "__.SYMDEF SORTED":
+			continue // 符号表
+		case name == "//":
+			longNames = body
+			continue
+		case strings.HasPrefix(name, "#1/"):
+			// BSD: 文件名紧跟在头部之后
+			n, err := strconv.Atoi(strings.TrimPrefix(name, "#1/"))
+			if err != nil || n > len(body) {
+				continue
+			}
+			name = strings.TrimRight(string(body[:n]), "\x00")
+			body = body[n:]
+		case strings.HasPrefix(name, "/") && longNames != nil:
+			// GNU: /offset 指向长文件名表
+			offset, err := strconv.Atoi(strings.TrimPrefix(name, "/"))
+			if err != nil || offset >= len(longNames) {
+				continue
+			}
+			end := bytes.IndexByte(longNames[offset:], '\n')
+			if end < 0 {
+				end = len(longNames) - offset
+			}
+			name = strings.TrimSuffix(string(longNames[offset:offset+end]), "/")
+		default:
+			name = strings.TrimSuffix(name, "/")
+		}
+		members = append(members, arMember{name: name, data: body})
+	}
+	return members, nil
+}
+
+// SignatureExtractorFactory 静态链接库签名提取器工厂
+type SignatureExtractorFactory struct{}
+
+// CreateExtractor 创建静态链接库签名提取器
+func (f *SignatureExtractorFactory) CreateExtractor(path string) Extractor {
+	return NewSignatureExtractor(path)
+}
+
+func init() {
+	// 注册静态链接库签名提取器
+	RegisterExtractor(SignatureExtractorType, &SignatureExtractorFactory{})
+}
+
+/*
+使用示例:
+
+1. 创建提取器(参数为构建输出目录或单个二进制/.a文件):
+extractor := NewSignatureExtractor("/path/to/build")
+
+2. 加载自定义签名(可选):
+if err := LoadStaticSignatures("signatures.json"); err != nil {
+    log.Printf("Failed to load signatures: %v\n", err)
+}
+
+3. 提取静态链接的依赖:
+deps, err := extractor.Extract()
+if err != nil {
+    log.Printf("Failed to scan binaries: %v\n", err)
+    return
+}
+
+4. 处理依赖信息:
+for _, dep := range deps {
+    fmt.Printf("%s %s statically linked, evidence: %v\n", dep.Name, dep.Version, dep.Metadata["evidence"])
+}
+
+签名文件格式:
+```json
+[
+  {
+    "name": "zlib",
+    "patterns": [" (?:inflate|deflate) (\\d+\\.\\d+\\.\\d+(?:\\.\\d+)?) Copyright"],
+    "symbols": ["zlibVersion", "inflateInit_"],
+    "sonames": ["libz.so"]
+  },
+  {
+    "name": "sqlite",
+    "symbols": ["sqlite3_libversion"],
+    "symbolVersionPattern": "SQLite version (3\\.\\d+\\.\\d+)",
+    "sonames": ["libsqlite3.so"]
+  }
+]
+```
+*/
@@ -0,0 +1,124 @@
+package extractor
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeArchive 使用GNU格式将目标文件打包为.a归档
+func writeArchive(t *testing.T, path string, members map[string]string) {
+	t.Helper()
+	var buf bytes.Buffer
+	buf.WriteString(arMagic)
+
+	var longNames bytes.Buffer
+	names := make(map[string]string)
+	for member := range members {
+		names[member] = fmt.Sprintf("/%d", longNames.Len())
+		longNames.WriteString(member + "/\n")
+	}
+	writeMember := func(name string, data []byte) {
+		fmt.Fprintf(&buf, "%-16s%-12s%-6s%-6s%-8s%-10d`\n", name, "0", "0", "0", "644", len(data))
+		buf.Write(data)
+		if len(data)%2 == 1 {
+			buf.WriteByte('\n')
+		}
+	}
+	writeMember("//", longNames.Bytes())
+	for member, file := range members {
+		data, err := os.ReadFile(file)
+		require.NoError(t, err)
+		writeMember(names[member], data)
+	}
+	require.NoError(t, os.WriteFile(path, buf.Bytes(), 0644))
+}
+
+func TestSignatureExtractor_Extract(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"zutil.c": "const char inflate_copyright[] = \" inflate 1.2.11 Copyright 1995-2017 Mark Adler \";\n" +
+			"const char *zlibVersion(void) { return inflate_copyright; }\n",
+		"sqlite3.c": "const char sqlite3_data[] = \"SQLite version 3.42.0\";\n" +
+			"const char *sqlite3_libversion(void) { return sqlite3_data + 15; }\n",
+		"main.c": "extern const char *zlibVersion(void);\nextern const char *sqlite3_libversion(void);\n" +
+			"int main(void) { return zlibVersion()[0] + sqlite3_libversion()[0]; }\n",
+		"dynamic.c": "extern const char *sqlite3_libversion(void);\nint use(void) { return sqlite3_libversion()[0]; }\n",
+	})
+	buildC(t, root, "-c", "-o", "zutil.o", "zutil.c")
+	buildC(t, root, "-c", "-o", "sqlite3.o", "sqlite3.c")
+	buildC(t, root, "-c", "-o", "dynamic.o", "dynamic.c")
+
+	out := filepath.Join(root, "out")
+	require.NoError(t, os.MkdirAll(out, 0755))
+	writeArchive(t, filepath.Join(out, "libthird_party_bundle.a"), map[string]string{
+		"zutil_with_a_long_member_name.o": filepath.Join(root, "zutil.o"),
+	})
+	buildC(t, root, "-o", "out/app", "main.c", "zutil.o", "sqlite3.o")
+	// 只引用(未定义)sqlite符号的目标文件不算静态链接
+	writeArchive(t, filepath.Join(out, "libuser.a"), map[string]string{
+		"dynamic.o": filepath.Join(root, "dynamic.o"),
+	})
+
+	matches, err := ScanStaticSignatures(filepath.Join(out, "libthird_party_bundle.a"))
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+	assert.Equal(t, "zlib", matches[0].Name)
+	assert.Equal(t, "1.2.11", matches[0].Version)
+	assert.Contains(t, matches[0].File, "(zutil_with_a_long_member_name.o)")
+	assert.Contains(t, matches[0].Section, ".rodata")
+
+	deps, err := NewSignatureExtractor(out).Extract()
+	require.NoError(t, err)
+	require.Len(t, deps, 2)
+
+	sqlite := deps[0]
+	assert.Equal(t, "sqlite", sqlite.Name)
+	assert.Equal(t, "3.42.0", sqlite.Version)
+	assert.Equal(t, []string{"app"}, sqlite.Metadata["found_in"])
+	assert.Equal(t, "static", sqlite.Type)
+
+	zlib := deps[1]
+	assert.Equal(t, "zlib", zlib.Name)
+	assert.Equal(t, "1.2.11", zlib.Version)
+	assert.Len(t, zlib.Metadata["found_in"], 2)
+	assert.NotEmpty(t, zlib.Metadata["evidence"])
+}
+
+func TestScanStaticSignatures_Anchored(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"zutil.c": "const char deflate_copyright[] = \" deflate 1.2.13 Copyright 1995-2022 Jean-loup Gailly and Mark Adler \";\n" +
+			"const char *zlibVersion(void) { return deflate_copyright; }\n",
+		// 没有版本横幅时, 其他恰好形如版本号的字符串不作为版本
+		"sqlite3.c": "const char sqlite3_data[] = \"pad\\0\" \"3.42.0\";\n" +
+			"const char *sqlite3_libversion(void) { return sqlite3_data + 4; }\n",
+	})
+	buildC(t, root, "-shared", "-fPIC", "-Wl,-soname,libz.so.1", "-o", "libz.so.1", "zutil.c")
+	buildC(t, root, "-shared", "-fPIC", "-Wl,-soname,libbundle.so", "-o", "libbundle.so", "zutil.c", "sqlite3.c")
+
+	// zlib自身的共享库不是静态链接
+	matches, err := ScanStaticSignatures(filepath.Join(root, "libz.so.1"))
+	require.NoError(t, err)
+	assert.Empty(t, matches)
+
+	// 静态链入其他共享库时仍然识别
+	matches, err = ScanStaticSignatures(filepath.Join(root, "libbundle.so"))
+	require.NoError(t, err)
+	versions := make(map[string]string)
+	for _, match := range matches {
+		versions[match.Name] = match.Version
+	}
+	assert.Equal(t, map[string]string{"zlib": "1.2.13", "sqlite": ""}, versions)
+}
+
+func TestLoadStaticSignatures_InvalidPattern(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "signatures.json")
+	require.NoError(t, os.WriteFile(file, []byte(`[{"name": "broken", "patterns": ["("]}]`), 0644))
+	assert.Error(t, LoadStaticSignatures(file))
+}
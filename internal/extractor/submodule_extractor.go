@@ -2,13 +2,19 @@ package extractor
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
 	"github.com/lkpsg/ccscanner/pkg/models"
 )
 
@@ -16,6 +22,7 @@ import (
 type SubmoduleExtractor struct {
 	BaseExtractor
 	config ExtractorConfig
+	parent string // 嵌套子模块所属的上级子模块名
 }
 
 // NewSubmoduleExtractor 创建Git子模块提取器
@@ -26,146 +33,686 @@ func NewSubmoduleExtractor(path string) *SubmoduleExtractor {
 	}
 }
 
+// SetConfig 设置提取器配置, 扫描器通过它传入本地镜像目录(GitMirrorDirs)等扫描配置
+// MaxDepth同时限制嵌套子模块的递归层数, 为0(不限制扫描深度)时使用默认层数
+func (e *SubmoduleExtractor) SetConfig(config ExtractorConfig) {
+	if config.MaxDepth <= 0 {
+		config.MaxDepth = DefaultConfig.MaxDepth
+	}
+	e.config = config
+}
+
+// gitmoduleEntry .gitmodules中的一个子模块声明
+type gitmoduleEntry struct {
+	name   string
+	path   string
+	url    string
+	branch string
+}
+
+// superproject 包含子模块的上级Git仓库
+type superproject struct {
+	repo *git.Repository
+	root string         // 工作区根目录
+	cfg  *config.Config // .git/config
+}
+
+// gitlinkFS 能够读取子模块固定提交的文件系统(如Git提交的文件树)
+type gitlinkFS interface {
+	Gitlink(name string) (string, error)
+}
+
 // Extract 提取Git子模块依赖
 func (e *SubmoduleExtractor) Extract() ([]models.Dependency, error) {
 	// 获取.gitmodules文件路径
 	gitmodulesPath := filepath.Join(filepath.Dir(e.FilePath), ".gitmodules")
-	if _, err := os.Stat(gitmodulesPath); os.IsNotExist(err) {
+	if _, err := e.stat(gitmodulesPath); errors.Is(err, fs.ErrNotExist) {
 		return nil, NewExtractorError(SubmoduleExtractorType, e.FilePath, ".gitmodules file not found")
 	}
 
-	// 读取.gitmodules文件
-	file, err := os.Open(gitmodulesPath)
+	entries, err := e.parseGitmodules(gitmodulesPath)
 	if err != nil {
 		return nil, NewExtractorError(SubmoduleExtractorType, e.FilePath, err.Error())
 	}
+
+	// 打开上级仓库,失败时仍然输出.gitmodules中声明的信息(从虚拟文件系统读取时没有上级仓库)
+	var super *superproject
+	var superErr error
+	if e.fsys == nil {
+		super, superErr = openSuperproject(filepath.Dir(gitmodulesPath))
+	}
+
+	deps := make([]models.Dependency, 0, len(entries))
+	for _, entry := range entries {
+		dep := models.NewDependency(entry.name)
+		dep.Type = "submodule"
+		dep.BuildSystem = "git"
+		dep.DetectedBy = "SubmoduleExtractor"
+		dep.ConfigFile = gitmodulesPath
+		dep.ConfigFileType = ".gitmodules"
+		dep.Parent = e.parent
+		if e.parent != "" {
+			// 嵌套子模块由上级子模块引入
+			dep.Relationship = models.RelationshipTransitive
+		}
+		dep.Branch = entry.branch
+		dep.Description = fmt.Sprintf("Git submodule at %s", entry.path)
+
+		url := entry.url
+		if super != nil {
+			url = super.resolveURL(entry)
+		}
+		dep.Repository = url
+		dep.Source = sourceHost(url)
+
+		// 从上级仓库的索引/HEAD树读取固定的提交
+		if super == nil {
+			if gitlinks, ok := e.fsys.(gitlinkFS); ok {
+				// 从Git提交读取时, 固定的提交取自提交树中的gitlink
+				if commit, err := gitlinks.Gitlink(fsPath(filepath.Join(filepath.Dir(gitmodulesPath), entry.path))); err == nil {
+					dep.Commit = commit
+				} else {
+					dep.Description += fmt.Sprintf(" (Error: %v)", err)
+				}
+			} else if superErr != nil {
+				dep.Description += fmt.Sprintf(" (Error: %v)", superErr)
+			}
+		} else if err := e.extractSubmoduleInfo(super, filepath.Dir(gitmodulesPath), entry.path, dep); err != nil {
+			// 记录错误但继续处理
+			dep.Description += fmt.Sprintf(" (Error: %v)", err)
+		}
+
+		// 子模块仓库(已检出、.git/modules或本地镜像)可用时根据标签推断版本,
+		// 设置了跟踪分支时计算与分支最新提交的差距
+		if dep.Commit != "" {
+			if repo := e.openSubmoduleRepo(super, filepath.Dir(gitmodulesPath), entry, url); repo != nil {
+				e.describeVersion(repo, dep)
+				if entry.branch != "" {
+					e.computeDrift(super, repo, entry, dep)
+				}
+			} else if entry.branch != "" {
+				if dep.Metadata == nil {
+					dep.Metadata = make(map[string]interface{})
+				}
+				dep.Metadata["drift_unavailable"] = "submodule repository not found locally, configure GitMirrorDirs"
+			}
+		}
+
+		deps = append(deps, *dep)
+		deps = append(deps, e.extractNested(filepath.Dir(gitmodulesPath), entry.path, &deps[len(deps)-1])...)
+	}
+
+	e.annotate(SubmoduleExtractorType, deps)
+	return deps, nil
+}
+
+// SubmodulePaths 返回.gitmodules中声明的子模块路径(相对.gitmodules所在目录)
+func (e *SubmoduleExtractor) SubmodulePaths() ([]string, error) {
+	entries, err := e.parseGitmodules(filepath.Join(filepath.Dir(e.FilePath), ".gitmodules"))
+	if err != nil {
+		return nil, err
+	}
+	paths := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.path != "" {
+			paths = append(paths, entry.path)
+		}
+	}
+	return paths, nil
+}
+
+// NestedDepth 返回递归提取嵌套子模块的最大层数, 第n层子模块中的.gitmodules在n不超过该值时被递归提取
+func (e *SubmoduleExtractor) NestedDepth() int {
+	return e.config.MaxDepth
+}
+
+// parseGitmodules 解析.gitmodules文件
+func (e *SubmoduleExtractor) parseGitmodules(gitmodulesPath string) ([]gitmoduleEntry, error) {
+	file, err := e.open(gitmodulesPath)
+	if err != nil {
+		return nil, err
+	}
 	defer file.Close()
 
-	deps := make([]models.Dependency, 0)
+	entries := make([]gitmoduleEntry, 0)
 	scanner := bufio.NewScanner(file)
 
 	// 正则表达式
 	submoduleRe := regexp.MustCompile(`\[submodule "([^"]+)"\]`)
-	pathRe := regexp.MustCompile(`\s*path\s*=\s*(.+)`)
-	urlRe := regexp.MustCompile(`\s*url\s*=\s*(.+)`)
-	branchRe := regexp.MustCompile(`\s*branch\s*=\s*(.+)`)
-
-	var currentDep *models.Dependency
-	lineNum := 0
+	pathRe := regexp.MustCompile(`^path\s*=\s*(.+)`)
+	urlRe := regexp.MustCompile(`^url\s*=\s*(.+)`)
+	branchRe := regexp.MustCompile(`^branch\s*=\s*(.+)`)
 
+	var current *gitmoduleEntry
 	for scanner.Scan() {
 		line := strings.TrimSpace(scanner.Text())
-		lineNum++
 
 		// 忽略空行和注释
-		if line == "" || strings.HasPrefix(line, "#") {
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
 			continue
 		}
 
 		// 检查子模块声明
 		if matches := submoduleRe.FindStringSubmatch(line); len(matches) > 1 {
-			// 保存前一个子模块
-			if currentDep != nil {
-				deps = append(deps, *currentDep)
-			}
-
-			// 创建新的子模块依赖
-			currentDep = models.NewDependency(matches[1])
-			currentDep.Type = "submodule"
-			currentDep.BuildSystem = "git"
-			currentDep.DetectedBy = "SubmoduleExtractor"
-			currentDep.ConfigFile = gitmodulesPath
-			currentDep.ConfigFileType = ".gitmodules"
+			entries = append(entries, gitmoduleEntry{name: matches[1]})
+			current = &entries[len(entries)-1]
 			continue
 		}
 
-		if currentDep == nil {
+		if current == nil {
 			continue
 		}
 
-		// 提取路径
 		if matches := pathRe.FindStringSubmatch(line); len(matches) > 1 {
-			path := strings.TrimSpace(matches[1])
-			currentDep.Description = fmt.Sprintf("Git submodule at %s", path)
-			
-			// 尝试获取子模块的提交信息
-			if err := e.extractSubmoduleInfo(path, currentDep); err != nil {
-				// 记录错误但继续处理
-				currentDep.Description += fmt.Sprintf(" (Error: %v)", err)
-			}
-			continue
+			current.path = strings.TrimSpace(matches[1])
+		} else if matches := urlRe.FindStringSubmatch(line); len(matches) > 1 {
+			current.url = strings.TrimSpace(matches[1])
+		} else if matches := branchRe.FindStringSubmatch(line); len(matches) > 1 {
+			current.branch = strings.TrimSpace(matches[1])
 		}
+	}
 
-		// 提取URL
-		if matches := urlRe.FindStringSubmatch(line); len(matches) > 1 {
-			url := strings.TrimSpace(matches[1])
-			currentDep.Repository = url
-			
-			// 从URL提取来源信息
-			if strings.Contains(url, "github.com") {
-				currentDep.Source = "github"
-			} else if strings.Contains(url, "gitlab.com") {
-				currentDep.Source = "gitlab"
-			} else if strings.Contains(url, "bitbucket.org") {
-				currentDep.Source = "bitbucket"
-			}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// openSuperproject 打开dir所在的Git仓库
+func openSuperproject(dir string) (*superproject, error) {
+	repo, err := git.PlainOpenWithOptions(dir, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open git repository: %v", err)
+	}
+
+	super := &superproject{repo: repo, root: dir}
+	if wt, err := repo.Worktree(); err == nil {
+		super.root = wt.Filesystem.Root()
+	}
+	if cfg, err := repo.Config(); err == nil {
+		super.cfg = cfg
+	}
+	return super, nil
+}
+
+// extractSubmoduleInfo 提取子模块的Git信息
+//
+// 固定的提交(gitlink)优先从上级仓库的索引读取,索引中没有时回退到HEAD树,
+// 因此即使克隆时没有使用--recursive也能得到提交哈希。
+func (e *SubmoduleExtractor) extractSubmoduleInfo(super *superproject, dir, path string, dep *models.Dependency) error {
+	relPath, err := filepath.Rel(super.root, filepath.Join(dir, path))
+	if err != nil {
+		return fmt.Errorf("failed to resolve submodule path: %v", err)
+	}
+	relPath = filepath.ToSlash(relPath)
+
+	// 读取索引
+	if idx, err := super.repo.Storer.Index(); err == nil {
+		if entry, err := idx.Entry(relPath); err == nil && entry.Mode == filemode.Submodule {
+			dep.Commit = entry.Hash.String()
+			return nil
+		}
+	}
+
+	// 回退到HEAD树
+	ref, err := super.repo.Head()
+	if err != nil {
+		return fmt.Errorf("failed to get HEAD reference: %v", err)
+	}
+	commit, err := super.repo.CommitObject(ref.Hash())
+	if err != nil {
+		return fmt.Errorf("failed to get HEAD commit: %v", err)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return fmt.Errorf("failed to get HEAD tree: %v", err)
+	}
+	entry, err := tree.FindEntry(relPath)
+	if err != nil || entry.Mode != filemode.Submodule {
+		return fmt.Errorf("no gitlink found for %s", relPath)
+	}
+
+	// 设置提交哈希
+	dep.Commit = entry.Hash.String()
+	return nil
+}
+
+// openSubmoduleRepo 打开子模块仓库
+//
+// 依次尝试已检出的工作区、上级仓库的.git/modules/<name>以及配置的本地镜像目录。
+// 没有上级仓库(如扫描Git版本)时只查找本地镜像。
+func (e *SubmoduleExtractor) openSubmoduleRepo(super *superproject, dir string, entry gitmoduleEntry, url string) *git.Repository {
+	var candidates []string
+	if super != nil {
+		candidates = append(candidates,
+			filepath.Join(dir, entry.path),
+			filepath.Join(super.root, ".git", "modules", entry.name),
+		)
+	}
+	repoName := strings.TrimSuffix(filepath.Base(strings.TrimSuffix(url, "/")), ".git")
+	if idx := strings.LastIndex(repoName, ":"); idx >= 0 {
+		repoName = repoName[idx+1:]
+	}
+	for _, mirror := range e.config.GitMirrorDirs {
+		candidates = append(candidates,
+			filepath.Join(mirror, repoName+".git"),
+			filepath.Join(mirror, repoName),
+		)
+	}
+
+	for _, candidate := range candidates {
+		if info, err := os.Stat(candidate); err != nil || !info.IsDir() {
 			continue
 		}
+		// 不向上查找.git,避免打开上级仓库
+		repo, err := git.PlainOpen(candidate)
+		if err == nil {
+			return repo
+		}
+	}
+	return nil
+}
+
+// gitDescription 与git describe --tags等价的描述信息
+type gitDescription struct {
+	Tag      string // 最近的可达标签
+	Distance int    // 从标签到提交的提交数
+	Abbrev   string // 缩写的提交哈希
+}
+
+// String 返回git describe格式的输出
+func (d gitDescription) String() string {
+	if d.Distance == 0 {
+		return d.Tag
+	}
+	return fmt.Sprintf("%s-%d-g%s", d.Tag, d.Distance, d.Abbrev)
+}
+
+// describeVersion 根据最近的可达标签推断子模块版本
+func (e *SubmoduleExtractor) describeVersion(repo *git.Repository, dep *models.Dependency) {
+	desc, err := describeCommit(repo, plumbing.NewHash(dep.Commit))
+	if err != nil {
+		return
+	}
+
+	if dep.Metadata == nil {
+		dep.Metadata = make(map[string]interface{})
+	}
+	dep.Metadata["git_describe"] = desc.String()
+	dep.Metadata["git_tag"] = desc.Tag
+	dep.Metadata["git_tag_distance"] = desc.Distance
+
+	version := normalizeTagVersion(desc.Tag)
+	if version == "" {
+		return
+	}
+	dep.Metadata["inferred_version"] = version
+	if desc.Distance == 0 {
+		dep.Metadata["version_confidence"] = "exact"
+	} else {
+		dep.Metadata["version_confidence"] = "approximate"
+	}
+	if dep.Version == "" {
+		dep.Version = version
+	}
+}
 
-		// 提取分支
-		if matches := branchRe.FindStringSubmatch(line); len(matches) > 1 {
-			currentDep.Branch = strings.TrimSpace(matches[1])
+// describeCommit 查找提交最近的可达标签及距离
+//
+// 按提交图广度优先找到最近的带标签提交,距离为从目标提交可达
+// 但从标签不可达的提交数,与git describe的计算方式一致。
+func describeCommit(repo *git.Repository, hash plumbing.Hash) (*gitDescription, error) {
+	tags, err := commitTags(repo)
+	if err != nil {
+		return nil, err
+	}
+	if len(tags) == 0 {
+		return nil, fmt.Errorf("no tags found")
+	}
+	if _, err := repo.CommitObject(hash); err != nil {
+		return nil, fmt.Errorf("commit %s not found: %v", hash, err)
+	}
+
+	// 广度优先查找最近的带标签提交
+	var tagged plumbing.Hash
+	visited := map[plumbing.Hash]bool{hash: true}
+	queue := []plumbing.Hash{hash}
+	for len(queue) > 0 && tagged.IsZero() {
+		current := queue[0]
+		queue = queue[1:]
+		if _, ok := tags[current]; ok {
+			tagged = current
+			break
+		}
+		commit, err := repo.CommitObject(current)
+		if err != nil {
 			continue
 		}
+		for _, parent := range commit.ParentHashes {
+			if !visited[parent] {
+				visited[parent] = true
+				queue = append(queue, parent)
+			}
+		}
+	}
+	if tagged.IsZero() {
+		return nil, fmt.Errorf("no reachable tag")
 	}
 
-	// 保存最后一个子模块
-	if currentDep != nil {
-		deps = append(deps, *currentDep)
+	distance, err := countExclusiveCommits(repo, hash, tagged)
+	if err != nil {
+		return nil, err
 	}
+	return &gitDescription{
+		Tag:      tags[tagged],
+		Distance: distance,
+		Abbrev:   hash.String()[:7],
+	}, nil
+}
 
-	if err := scanner.Err(); err != nil {
-		return nil, NewExtractorError(SubmoduleExtractorType, e.FilePath, err.Error())
+// commitTags 返回提交到标签名的映射(附注标签会解析到其指向的提交)
+func commitTags(repo *git.Repository) (map[plumbing.Hash]string, error) {
+	iter, err := repo.Tags()
+	if err != nil {
+		return nil, err
 	}
+	tags := make(map[plumbing.Hash]string)
+	err = iter.ForEach(func(ref *plumbing.Reference) error {
+		target := ref.Hash()
+		if tag, err := repo.TagObject(target); err == nil {
+			commit, err := tag.Commit()
+			if err != nil {
+				return nil
+			}
+			target = commit.Hash
+		}
+		name := ref.Name().Short()
+		// 同一提交上有多个标签时取名称较大的,保证结果稳定
+		if existing, ok := tags[target]; !ok || existing < name {
+			tags[target] = name
+		}
+		return nil
+	})
+	return tags, err
+}
 
-	return deps, nil
+// countExclusiveCommits 统计从from可达但从exclude不可达的提交数
+func countExclusiveCommits(repo *git.Repository, from, exclude plumbing.Hash) (int, error) {
+	excluded := reachableCommits(repo, exclude)
+
+	count := 0
+	seen := make(map[plumbing.Hash]bool)
+	stack := []plumbing.Hash{from}
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if seen[current] || excluded[current] {
+			continue
+		}
+		seen[current] = true
+		count++
+		commit, err := repo.CommitObject(current)
+		if err != nil {
+			return 0, err
+		}
+		stack = append(stack, commit.ParentHashes...)
+	}
+	return count, nil
 }
 
-// extractSubmoduleInfo 提取子模块的Git信息
-func (e *SubmoduleExtractor) extractSubmoduleInfo(path string, dep *models.Dependency) error {
-	// 获取子模块的完整路径
-	fullPath := filepath.Join(filepath.Dir(e.FilePath), path)
+// tagVersionRe 标签中的版本号部分(如 1.2.11、7_88_1、1.1.1k、2.0.0-rc1)
+var tagVersionRe = regexp.MustCompile(`(\d+(?:[._]\d+)+[a-z]?(?:-?(?:rc|alpha|beta|pre)[._]?\d*)?)`)
 
-	// 打开Git仓库
-	repo, err := git.PlainOpen(fullPath)
+// normalizeTagVersion 将标签名规范化为版本号
+// 例如 v1.2.11 → 1.2.11、curl-7_88_1 → 7.88.1、OpenSSL_1_1_1k → 1.1.1k
+func normalizeTagVersion(tag string) string {
+	match := tagVersionRe.FindString(tag)
+	if match == "" {
+		return ""
+	}
+	return strings.ReplaceAll(match, "_", ".")
+}
+
+// computeDrift 比较固定提交与跟踪分支在本地仓库中的最新提交
+func (e *SubmoduleExtractor) computeDrift(super *superproject, repo *git.Repository, entry gitmoduleEntry, dep *models.Dependency) {
+	branch := entry.branch
+	if branch == "." {
+		// branch = . 表示与上级仓库当前分支同名
+		if super == nil {
+			return
+		}
+		ref, err := super.repo.Head()
+		if err != nil || !ref.Name().IsBranch() {
+			return
+		}
+		branch = ref.Name().Short()
+	}
+
+	tip, err := resolveBranchTip(repo, branch)
 	if err != nil {
-		return fmt.Errorf("failed to open git repository: %v", err)
+		return
+	}
+	pinned, err := repo.CommitObject(plumbing.NewHash(dep.Commit))
+	if err != nil {
+		return
+	}
+	tipCommit, err := repo.CommitObject(tip)
+	if err != nil {
+		return
+	}
+
+	drift := &models.SubmoduleDrift{
+		Name:         entry.name,
+		Path:         entry.path,
+		Branch:       branch,
+		PinnedCommit: pinned.Hash.String(),
+		BranchCommit: tip.String(),
+		PinnedDate:   pinned.Committer.When,
+		BranchDate:   tipCommit.Committer.When,
+		NewerTags:    make([]string, 0),
 	}
+	if drift.CommitsBehind, err = countExclusiveCommits(repo, tip, pinned.Hash); err != nil {
+		return
+	}
+	if drift.CommitsAhead, err = countExclusiveCommits(repo, pinned.Hash, tip); err != nil {
+		return
+	}
+	if drift.CommitsBehind > 0 && tipCommit.Committer.When.After(pinned.Committer.When) {
+		drift.DaysBehind = int(tipCommit.Committer.When.Sub(pinned.Committer.When).Hours() / 24)
+	}
+
+	// 不包含在固定提交中、且提交时间更晚的标签视为新标签
+	if tags, err := commitTags(repo); err == nil {
+		ancestors := reachableCommits(repo, pinned.Hash)
+		for hash, name := range tags {
+			if ancestors[hash] {
+				continue
+			}
+			if commit, err := repo.CommitObject(hash); err == nil && commit.Committer.When.After(pinned.Committer.When) {
+				drift.NewerTags = append(drift.NewerTags, name)
+			}
+		}
+		sort.Strings(drift.NewerTags)
+	}
+
+	dep.Drift = drift
+}
 
-	// 获取HEAD引用
-	ref, err := repo.Head()
+// resolveBranchTip 在本地仓库中查找分支的最新提交
+//
+// 优先使用远程跟踪分支, 依次尝试origin和其他配置的远程(按名称排序), 都没有时使用本地分支。
+func resolveBranchTip(repo *git.Repository, branch string) (plumbing.Hash, error) {
+	names := make([]plumbing.ReferenceName, 0)
+	for _, remote := range remoteNames(repo) {
+		names = append(names, plumbing.NewRemoteReferenceName(remote, branch))
+	}
+	names = append(names, plumbing.NewBranchReferenceName(branch))
+	for _, name := range names {
+		if ref, err := repo.Reference(name, true); err == nil {
+			return ref.Hash(), nil
+		}
+	}
+	return plumbing.ZeroHash, fmt.Errorf("branch %s not found", branch)
+}
+
+// remoteNames 返回仓库配置的远程名称, origin排在最前, 其余按名称排序
+func remoteNames(repo *git.Repository) []string {
+	names := []string{"origin"}
+	cfg, err := repo.Config()
 	if err != nil {
-		return fmt.Errorf("failed to get HEAD reference: %v", err)
+		return names
+	}
+	others := make([]string, 0, len(cfg.Remotes))
+	for name := range cfg.Remotes {
+		if name != "origin" {
+			others = append(others, name)
+		}
 	}
+	sort.Strings(others)
+	return append(names, others...)
+}
 
-	// 设置提交哈希
-	dep.Commit = ref.Hash().String()
+// reachableCommits 返回从hash可达的所有提交
+func reachableCommits(repo *git.Repository, hash plumbing.Hash) map[plumbing.Hash]bool {
+	reachable := make(map[plumbing.Hash]bool)
+	stack := []plumbing.Hash{hash}
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if reachable[current] {
+			continue
+		}
+		reachable[current] = true
+		if commit, err := repo.CommitObject(current); err == nil {
+			stack = append(stack, commit.ParentHashes...)
+		}
+	}
+	return reachable
+}
+
+// extractNested 递归提取已检出子模块中的嵌套子模块
+func (e *SubmoduleExtractor) extractNested(dir, path string, parent *models.Dependency) []models.Dependency {
+	if e.config.MaxDepth <= 0 {
+		return nil
+	}
+	nestedPath := filepath.Join(dir, path, ".gitmodules")
+	if _, err := e.stat(nestedPath); err != nil {
+		return nil
+	}
+
+	nested := NewSubmoduleExtractor(nestedPath)
+	nested.fsys = e.fsys
+	nested.config = e.config
+	nested.config.MaxDepth--
+	nested.parent = parent.Name
 
-	// 获取远程信息
-	remotes, err := repo.Remotes()
+	deps, err := nested.Extract()
 	if err != nil {
-		return fmt.Errorf("failed to get remotes: %v", err)
+		parent.Description += fmt.Sprintf(" (Error: %v)", err)
+		return nil
+	}
+	for _, dep := range deps {
+		if dep.Parent == parent.Name {
+			parent.Dependencies = append(parent.Dependencies, dep.Name)
+		}
 	}
+	return deps
+}
 
-	// 从远程URL更新仓库信息
-	if len(remotes) > 0 {
-		urls := remotes[0].Config().URLs
-		if len(urls) > 0 {
-			dep.Repository = urls[0]
+// resolveURL 计算子模块的实际地址
+//
+// 依次应用 .git/config 中的 submodule.<name>.url 覆盖、相对地址解析
+// 以及 url.<base>.insteadOf 重写。
+func (s *superproject) resolveURL(entry gitmoduleEntry) string {
+	url := entry.url
+	if s.cfg != nil {
+		if sub, ok := s.cfg.Submodules[entry.name]; ok && sub.URL != "" {
+			url = sub.URL
 		}
 	}
+	if strings.HasPrefix(url, "./") || strings.HasPrefix(url, "../") {
+		url = resolveRelativeURL(s.remoteURL(), url)
+	}
+	return s.rewriteURL(url)
+}
 
-	return nil
+// remoteURL 返回上级仓库默认远程的地址,没有远程时返回工作区路径
+//
+// 默认远程为当前分支配置的远程或origin, 二者都未配置时依次尝试其他远程(按名称排序)。
+func (s *superproject) remoteURL() string {
+	if s.cfg != nil {
+		remotes := remoteNames(s.repo)
+		if ref, err := s.repo.Head(); err == nil && ref.Name().IsBranch() {
+			if branch, ok := s.cfg.Branches[ref.Name().Short()]; ok && branch.Remote != "" {
+				remotes = append([]string{branch.Remote}, remotes...)
+			}
+		}
+		for _, remote := range remotes {
+			if r, ok := s.cfg.Remotes[remote]; ok && len(r.URLs) > 0 {
+				return r.URLs[0]
+			}
+		}
+	}
+	return s.root
+}
+
+// rewriteURL 应用url.<base>.insteadOf规则,多条匹配时取最长前缀
+func (s *superproject) rewriteURL(url string) string {
+	if s.cfg == nil || s.cfg.Raw == nil {
+		return url
+	}
+	var base, prefix string
+	for _, sub := range s.cfg.Raw.Section("url").Subsections {
+		for _, insteadOf := range sub.Options.GetAll("insteadOf") {
+			if strings.HasPrefix(url, insteadOf) && len(insteadOf) > len(prefix) {
+				base, prefix = sub.Name, insteadOf
+			}
+		}
+	}
+	if prefix == "" {
+		return url
+	}
+	return base + strings.TrimPrefix(url, prefix)
+}
+
+// resolveRelativeURL 按git的规则将 ./foo、../foo 形式的地址解析为相对base的地址
+func resolveRelativeURL(base, rel string) string {
+	base = strings.TrimSuffix(base, "/")
+
+	// scp风格地址(git@host:org/repo.git)的路径部分在冒号之后
+	prefix := ""
+	if !strings.Contains(base, "://") {
+		if idx := strings.Index(base, ":"); idx > 1 && !strings.ContainsAny(base[:idx], "/\\") {
+			prefix, base = base[:idx+1], base[idx+1:]
+		}
+	}
+
+	for {
+		switch {
+		case strings.HasPrefix(rel, "./"):
+			rel = rel[2:]
+			continue
+		case strings.HasPrefix(rel, "../"):
+			rel = rel[3:]
+			idx := strings.LastIndex(base, "/")
+			switch {
+			case idx == 0:
+				base, prefix = "", prefix+"/"
+			case idx < 0:
+				base = ""
+			case !strings.HasSuffix(base[:idx], "/"):
+				// 已经到达主机名时不再向上
+				base = base[:idx]
+			}
+			continue
+		}
+		break
+	}
+
+	if base == "" {
+		return prefix + rel
+	}
+	return prefix + base + "/" + rel
 }
 
 // SubmoduleExtractorFactory Git子模块提取器工厂
@@ -200,6 +747,7 @@ for _, dep := range deps {
     fmt.Printf("  Repository: %s\n", dep.Repository)
     fmt.Printf("  Branch: %s\n", dep.Branch)
     fmt.Printf("  Commit: %s\n", dep.Commit)
+    fmt.Printf("  Parent: %s\n", dep.Parent)
     fmt.Printf("  Description: %s\n", dep.Description)
 }
 
@@ -0,0 +1,285 @@
+package extractor
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/lkpsg/ccscanner/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// initRepoWithGitlinks 初始化仓库并在索引中写入子模块gitlink
+func initRepoWithGitlinks(t *testing.T, dir string, gitlinks map[string]string) *git.Repository {
+	t.Helper()
+	repo, err := git.PlainInit(dir, false)
+	require.NoError(t, err)
+
+	idx, err := repo.Storer.Index()
+	require.NoError(t, err)
+	for path, hash := range gitlinks {
+		entry := idx.Add(path)
+		entry.Mode = filemode.Submodule
+		entry.Hash = plumbing.NewHash(hash)
+	}
+	require.NoError(t, repo.Storer.SetIndex(idx))
+	return repo
+}
+
+func TestSubmoduleExtractor_Extract(t *testing.T) {
+	root := t.TempDir()
+	repo := initRepoWithGitlinks(t, root, map[string]string{
+		"libs/zlib": "1111111111111111111111111111111111111111",
+		"libs/fmt":  "2222222222222222222222222222222222222222",
+	})
+
+	cfg, err := repo.Config()
+	require.NoError(t, err)
+	cfg.Remotes["origin"] = &config.RemoteConfig{
+		Name: "origin",
+		URLs: []string{"git@github.com:acme/super.git"},
+	}
+	cfg.URLs["https://github.com/"] = &config.URL{Name: "https://github.com/", InsteadOf: "gh:"}
+	require.NoError(t, repo.Storer.SetConfig(cfg))
+
+	gitmodules := `[submodule "libs/zlib"]
+	path = libs/zlib
+	url = gh:madler/zlib.git
+[submodule "libs/fmt"]
+	path = libs/fmt
+	url = ../fmt.git
+	branch = master
+`
+	require.NoError(t, os.WriteFile(filepath.Join(root, ".gitmodules"), []byte(gitmodules), 0644))
+
+	// 已检出的zlib子模块中还有嵌套子模块
+	zlibDir := filepath.Join(root, "libs", "zlib")
+	initRepoWithGitlinks(t, zlibDir, map[string]string{
+		"contrib/minizip": "3333333333333333333333333333333333333333",
+	})
+	nested := `[submodule "minizip"]
+	path = contrib/minizip
+	url = https://github.com/zlib-ng/minizip-ng.git
+`
+	require.NoError(t, os.WriteFile(filepath.Join(zlibDir, ".gitmodules"), []byte(nested), 0644))
+
+	deps, err := NewSubmoduleExtractor(filepath.Join(root, ".gitmodules")).Extract()
+	require.NoError(t, err)
+	require.Len(t, deps, 3)
+
+	zlib := deps[0]
+	assert.Equal(t, "libs/zlib", zlib.Name)
+	assert.Equal(t, "https://github.com/madler/zlib.git", zlib.Repository)
+	assert.Equal(t, "github", zlib.Source)
+	assert.Equal(t, "1111111111111111111111111111111111111111", zlib.Commit)
+	assert.Equal(t, []string{"minizip"}, zlib.Dependencies)
+
+	minizip := deps[1]
+	assert.Equal(t, "minizip", minizip.Name)
+	assert.Equal(t, "libs/zlib", minizip.Parent)
+	assert.Equal(t, "3333333333333333333333333333333333333333", minizip.Commit)
+
+	fmtDep := deps[2]
+	assert.Equal(t, "git@github.com:acme/fmt.git", fmtDep.Repository)
+	assert.Equal(t, "2222222222222222222222222222222222222222", fmtDep.Commit)
+	assert.Equal(t, "master", fmtDep.Branch)
+	assert.Empty(t, fmtDep.Parent)
+}
+
+func TestSubmoduleExtractor_MissingGitlink(t *testing.T) {
+	root := t.TempDir()
+	initRepoWithGitlinks(t, root, nil)
+	gitmodules := `[submodule "vendor/json"]
+	path = vendor/json
+	url = https://github.com/nlohmann/json.git
+`
+	require.NoError(t, os.WriteFile(filepath.Join(root, ".gitmodules"), []byte(gitmodules), 0644))
+
+	deps, err := NewSubmoduleExtractor(filepath.Join(root, ".gitmodules")).Extract()
+	require.NoError(t, err)
+	require.Len(t, deps, 1)
+	assert.Empty(t, deps[0].Commit)
+	assert.Contains(t, deps[0].Description, "Error:")
+}
+
+// commitN 在仓库中创建n个提交并返回最后一个提交
+func commitN(t *testing.T, repo *git.Repository, dir string, n int) plumbing.Hash {
+	t.Helper()
+	return commitAt(t, repo, dir, n, time.Now(), 0)
+}
+
+// commitAt 从when开始每隔step创建一个提交并返回最后一个提交
+func commitAt(t *testing.T, repo *git.Repository, dir string, n int, when time.Time, step time.Duration) plumbing.Hash {
+	t.Helper()
+	wt, err := repo.Worktree()
+	require.NoError(t, err)
+
+	var hash plumbing.Hash
+	for i := 0; i < n; i++ {
+		file := filepath.Join(dir, "file.txt")
+		require.NoError(t, os.WriteFile(file, []byte(time.Now().String()+string(rune('a'+i))), 0644))
+		_, err = wt.Add("file.txt")
+		require.NoError(t, err)
+		sig := &object.Signature{Name: "test", Email: "test@example.com", When: when.Add(time.Duration(i) * step)}
+		hash, err = wt.Commit("change", &git.CommitOptions{Author: sig, Committer: sig})
+		require.NoError(t, err)
+	}
+	return hash
+}
+
+func TestSubmoduleExtractor_DescribeVersion(t *testing.T) {
+	root := t.TempDir()
+
+	// 子模块仓库: v1.2.11标签之后还有3个提交
+	subDir := filepath.Join(root, "third_party", "zlib")
+	sub, err := git.PlainInit(subDir, false)
+	require.NoError(t, err)
+	tagged := commitN(t, sub, subDir, 2)
+	_, err = sub.CreateTag("v1.2.11", tagged, &git.CreateTagOptions{
+		Tagger:  &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+		Message: "release",
+	})
+	require.NoError(t, err)
+	pinned := commitN(t, sub, subDir, 3)
+
+	initRepoWithGitlinks(t, root, map[string]string{
+		"third_party/zlib": pinned.String(),
+		"third_party/json": tagged.String(),
+	})
+	gitmodules := `[submodule "zlib"]
+	path = third_party/zlib
+	url = https://github.com/madler/zlib.git
+[submodule "json"]
+	path = third_party/json
+	url = https://github.com/madler/zlib.git
+`
+	require.NoError(t, os.WriteFile(filepath.Join(root, ".gitmodules"), []byte(gitmodules), 0644))
+
+	// json子模块未检出,但本地镜像中有对应仓库
+	extractor := NewSubmoduleExtractor(filepath.Join(root, ".gitmodules"))
+	extractor.SetConfig(ExtractorConfig{GitMirrorDirs: []string{filepath.Join(root, "third_party")}})
+	assert.Equal(t, DefaultConfig.MaxDepth, extractor.NestedDepth())
+
+	deps, err := extractor.Extract()
+	require.NoError(t, err)
+	require.Len(t, deps, 2)
+
+	zlib := deps[0]
+	assert.Equal(t, "1.2.11", zlib.Version)
+	assert.Equal(t, "v1.2.11-3-g"+pinned.String()[:7], zlib.Metadata["git_describe"])
+	assert.Equal(t, 3, zlib.Metadata["git_tag_distance"])
+	assert.Equal(t, "approximate", zlib.Metadata["version_confidence"])
+
+	json := deps[1]
+	assert.Equal(t, "1.2.11", json.Version)
+	assert.Equal(t, "v1.2.11", json.Metadata["git_describe"])
+	assert.Equal(t, "exact", json.Metadata["version_confidence"])
+}
+
+func TestSubmoduleExtractor_Drift(t *testing.T) {
+	root := t.TempDir()
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	subDir := filepath.Join(root, "deps", "fmt")
+	sub, err := git.PlainInit(subDir, false)
+	require.NoError(t, err)
+	pinned := commitAt(t, sub, subDir, 1, start, 0)
+	tip := commitAt(t, sub, subDir, 4, start.Add(24*time.Hour), 24*time.Hour)
+	_, err = sub.CreateTag("10.2.0", tip, nil)
+	require.NoError(t, err)
+
+	initRepoWithGitlinks(t, root, map[string]string{"deps/fmt": pinned.String()})
+	gitmodules := `[submodule "fmt"]
+	path = deps/fmt
+	url = https://github.com/fmtlib/fmt.git
+	branch = master
+`
+	require.NoError(t, os.WriteFile(filepath.Join(root, ".gitmodules"), []byte(gitmodules), 0644))
+
+	deps, err := NewSubmoduleExtractor(filepath.Join(root, ".gitmodules")).Extract()
+	require.NoError(t, err)
+
+	// 漂移信息是依赖的类型化字段, 经过JSON序列化(如结果缓存)后仍然保留
+	data, err := json.Marshal(deps)
+	require.NoError(t, err)
+	var decoded []models.Dependency
+	require.NoError(t, json.Unmarshal(data, &decoded))
+
+	drifts := models.CollectSubmoduleDrift(decoded)
+	require.Len(t, drifts, 1)
+	drift := drifts[0]
+	assert.Equal(t, "master", drift.Branch)
+	assert.Equal(t, pinned.String(), drift.PinnedCommit)
+	assert.Equal(t, tip.String(), drift.BranchCommit)
+	assert.Equal(t, 4, drift.CommitsBehind)
+	assert.Equal(t, 0, drift.CommitsAhead)
+	assert.Equal(t, 4, drift.DaysBehind)
+	assert.Equal(t, []string{"10.2.0"}, drift.NewerTags)
+	assert.Contains(t, drift.String(), "4 commits / 4 days behind master")
+}
+
+func TestResolveBranchTip_Remotes(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	require.NoError(t, err)
+	first := commitN(t, repo, dir, 1)
+	second := commitN(t, repo, dir, 1)
+
+	cfg, err := repo.Config()
+	require.NoError(t, err)
+	cfg.Remotes["upstream"] = &config.RemoteConfig{Name: "upstream", URLs: []string{"https://github.com/acme/lib.git"}}
+	require.NoError(t, repo.Storer.SetConfig(cfg))
+
+	// 没有origin时使用其他远程的跟踪分支, 优先于本地分支
+	require.NoError(t, repo.Storer.SetReference(plumbing.NewHashReference(plumbing.NewRemoteReferenceName("upstream", "release"), first)))
+	require.NoError(t, repo.Storer.SetReference(plumbing.NewHashReference(plumbing.NewBranchReferenceName("release"), second)))
+	tip, err := resolveBranchTip(repo, "release")
+	require.NoError(t, err)
+	assert.Equal(t, first, tip)
+
+	_, err = resolveBranchTip(repo, "missing")
+	assert.Error(t, err)
+}
+
+func TestNormalizeTagVersion(t *testing.T) {
+	tests := map[string]string{
+		"v1.2.11":        "1.2.11",
+		"zlib-1.2.13":    "1.2.13",
+		"curl-7_88_1":    "7.88.1",
+		"OpenSSL_1_1_1k": "1.1.1k",
+		"v2.0.0-rc1":     "2.0.0-rc1",
+		"release":        "",
+	}
+	for tag, want := range tests {
+		assert.Equal(t, want, normalizeTagVersion(tag), tag)
+	}
+}
+
+func TestResolveRelativeURL(t *testing.T) {
+	tests := []struct {
+		base string
+		rel  string
+		want string
+	}{
+		{"https://github.com/acme/super.git", "../lib.git", "https://github.com/acme/lib.git"},
+		{"https://github.com/acme/super.git/", "../../other/lib.git", "https://github.com/other/lib.git"},
+		{"https://github.com/acme/super.git", "./lib.git", "https://github.com/acme/super.git/lib.git"},
+		{"git@github.com:acme/super.git", "../lib.git", "git@github.com:acme/lib.git"},
+		{"git@github.com:super.git", "../lib.git", "git@github.com:lib.git"},
+		{"/srv/git/super", "../lib", "/srv/git/lib"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.base+" "+tt.rel, func(t *testing.T) {
+			assert.Equal(t, tt.want, resolveRelativeURL(tt.base, tt.rel))
+		})
+	}
+}
@@ -3,7 +3,6 @@ package extractor
 import (
 	"encoding/json"
 	"fmt"
-	"os"
 	"path/filepath"
 
 	"github.com/lkpsg/ccscanner/pkg/models"
@@ -19,6 +18,7 @@ type VcpkgDependency struct {
 	Features     []string          `json:"features,omitempty"`
 	Default      []string          `json:"default-features,omitempty"`
 	Platform     string            `json:"platform,omitempty"`
+	Host         bool              `json:"host,omitempty"`
 	Overrides    []VcpkgOverride   `json:"overrides,omitempty"`
 }
 
@@ -61,7 +61,7 @@ func NewVcpkgExtractor(path string) *VcpkgExtractor {
 // Extract 提取Vcpkg依赖
 func (e *VcpkgExtractor) Extract() ([]models.Dependency, error) {
 	// 读取vcpkg.json文件
-	data, err := os.ReadFile(e.FilePath)
+	data, err := e.readFile(e.FilePath)
 	if err != nil {
 		return nil, NewExtractorError(VcpkgExtractorType, e.FilePath, err.Error())
 	}
@@ -97,6 +97,7 @@ func (e *VcpkgExtractor) Extract() ([]models.Dependency, error) {
 		deps = append(deps, *dep)
 	}
 
+	e.annotate(VcpkgExtractorType, deps)
 	return deps, nil
 }
 
@@ -138,6 +139,11 @@ func (e *VcpkgExtractor) convertVcpkgDependency(vcpkgDep VcpkgDependency) *model
 		dep.BuildFlags = append(dep.BuildFlags, fmt.Sprintf("port:%s", vcpkgDep.Port))
 	}
 
+	// host依赖为构建机器上运行的工具(如vcpkg-cmake)
+	if vcpkgDep.Host {
+		dep.Scope = models.ScopeTool
+	}
+
 	return dep
 }
 
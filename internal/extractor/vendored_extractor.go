@@ -0,0 +1,406 @@
+package extractor
+
+import (
+	"crypto/sha256"
+	_ "embed"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/lkpsg/ccscanner/pkg/models"
+)
+
+// VendoredFingerprint 内置第三方库的指纹规则
+type VendoredFingerprint struct {
+	Name     string            `json:"name"`               // 库名称
+	Homepage string            `json:"homepage,omitempty"` // 主页
+	License  string            `json:"license,omitempty"`  // 许可证
+	Files    []string          `json:"files"`              // 必须同时存在的特征文件
+	AnyFiles []string          `json:"anyFiles,omitempty"` // 至少存在一个的辅助文件
+	Guards   []string          `json:"guards,omitempty"`   // 特征文件中的头文件保护宏
+	Markers  []string          `json:"markers,omitempty"`  // 特征文件中的特征字符串
+	Hashes   map[string]string `json:"hashes,omitempty"`   // 已知文件的sha256 -> 版本号
+}
+
+//go:embed data/vendored_fingerprints.json
+var defaultVendoredFingerprints []byte
+
+var (
+	vendoredFingerprints   []VendoredFingerprint
+	vendoredFingerprintsMu sync.RWMutex
+)
+
+// vendorDirNames 常用于存放第三方源码的目录名
+var vendorDirNames = map[string]bool{
+	"third_party": true,
+	"thirdparty":  true,
+	"3rdparty":    true,
+	"external":    true,
+	"extern":      true,
+	"deps":        true,
+	"vendor":      true,
+	"contrib":     true,
+}
+
+// maxFingerprintBytes 检查头文件保护宏时读取的最大字节数
+const maxFingerprintBytes = 256 * 1024
+
+func init() {
+	if err := json.Unmarshal(defaultVendoredFingerprints, &vendoredFingerprints); err != nil {
+		panic(fmt.Sprintf("invalid vendored fingerprint data: %v", err))
+	}
+}
+
+// LoadVendoredFingerprints 从JSON文件加载额外的指纹规则,同名规则会被替换
+func LoadVendoredFingerprints(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var rules []VendoredFingerprint
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return fmt.Errorf("failed to parse fingerprint file %s: %v", path, err)
+	}
+
+	vendoredFingerprintsMu.Lock()
+	defer vendoredFingerprintsMu.Unlock()
+	for _, rule := range rules {
+		replaced := false
+		for i := range vendoredFingerprints {
+			if vendoredFingerprints[i].Name == rule.Name {
+				vendoredFingerprints[i] = rule
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			vendoredFingerprints = append(vendoredFingerprints, rule)
+		}
+	}
+	return nil
+}
+
+// VendoredExtractor 内置(拷贝进仓库的)第三方源码检测器
+type VendoredExtractor struct {
+	BaseExtractor
+	treeFilter
+	config ExtractorConfig
+}
+
+// NewVendoredExtractor 创建内置第三方源码检测器, path为要扫描的源码根目录
+func NewVendoredExtractor(path string) *VendoredExtractor {
+	return &VendoredExtractor{
+		BaseExtractor: NewBaseExtractor(path),
+		config:        DefaultConfig,
+	}
+}
+
+// vendoredMatch 一次指纹匹配结果
+type vendoredMatch struct {
+	rule     VendoredFingerprint
+	dir      string   // 匹配到特征文件的目录
+	evidence []string // 匹配依据
+	version  string   // 通过内容哈希确定的版本
+}
+
+// Extract 遍历源码树并识别内置的第三方库
+func (e *VendoredExtractor) Extract() ([]models.Dependency, error) {
+	root := e.FilePath
+	if info, err := e.stat(root); err != nil {
+		return nil, NewExtractorError(VendoredExtractorType, root, err.Error())
+	} else if !info.IsDir() {
+		root = filepath.Dir(root)
+	}
+
+	vendoredFingerprintsMu.RLock()
+	rules := append([]VendoredFingerprint(nil), vendoredFingerprints...)
+	vendoredFingerprintsMu.RUnlock()
+
+	deps := make([]models.Dependency, 0)
+	seen := make(map[string]bool)
+
+	err := walkFS(e.fsys, root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if e.skip(root, path, info) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.IsDir() {
+			return nil
+		}
+		if path != root && strings.HasPrefix(info.Name(), ".") {
+			return filepath.SkipDir
+		}
+		if e.config.MaxDepth > 0 && pathDepth(root, path) > e.config.MaxDepth {
+			return filepath.SkipDir
+		}
+
+		for _, rule := range rules {
+			match, ok := matchVendoredFingerprint(e.fsys, rule, path)
+			if !ok {
+				continue
+			}
+			vendoredDir := vendoredRoot(root, match.dir)
+			// 扫描根目录本身就是该库时不属于内置依赖
+			if vendoredDir == root {
+				continue
+			}
+			key := rule.Name + "\x00" + vendoredDir
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			deps = append(deps, *e.newDependency(match, vendoredDir))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, NewExtractorError(VendoredExtractorType, root, err.Error())
+	}
+
+	e.annotate(VendoredExtractorType, deps)
+	return deps, nil
+}
+
+// newDependency 根据匹配结果创建依赖
+func (e *VendoredExtractor) newDependency(match vendoredMatch, vendoredDir string) *models.Dependency {
+	dep := models.NewDependency(match.rule.Name)
+	dep.Type = "vendored"
+	dep.Version = match.version
+	dep.Homepage = match.rule.Homepage
+	dep.License = match.rule.License
+	dep.Source = "vendored"
+	dep.DetectedBy = "VendoredExtractor"
+	dep.ConfigFile = vendoredDir
+	dep.ConfigFileType = "directory"
+	dep.Description = fmt.Sprintf("Vendored copy of %s", match.rule.Name)
+	dep.Metadata = map[string]interface{}{
+		"vendored_files":    match.dir,
+		"fingerprint_match": match.evidence,
+	}
+	if match.version != "" {
+		dep.Metadata["version_source"] = "sha256"
+	} else if version, source := detectLibraryVersion(e.fsys, vendoredDir, match.rule.Name); version != "" {
+		dep.Version = version
+		dep.Metadata["version_source"] = source
+	}
+	return dep
+}
+
+// matchVendoredFingerprint 检查目录是否匹配指纹规则
+//
+// 所有特征文件必须存在,且至少满足以下之一: 头文件保护宏、特征字符串或已知内容哈希。
+func matchVendoredFingerprint(fsys fs.FS, rule VendoredFingerprint, dir string) (vendoredMatch, bool) {
+	match := vendoredMatch{rule: rule, dir: dir}
+	if len(rule.Files) == 0 {
+		return match, false
+	}
+	for _, name := range rule.Files {
+		if !isRegularFile(fsys, filepath.Join(dir, name)) {
+			return match, false
+		}
+	}
+	if len(rule.AnyFiles) > 0 {
+		found := false
+		for _, name := range rule.AnyFiles {
+			if isRegularFile(fsys, filepath.Join(dir, name)) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return match, false
+		}
+	}
+	match.evidence = append(match.evidence, "files:"+strings.Join(rule.Files, ","))
+
+	confirmed := len(rule.Guards) == 0 && len(rule.Markers) == 0 && len(rule.Hashes) == 0
+	for _, name := range rule.Files {
+		path := filepath.Join(dir, name)
+
+		if len(rule.Hashes) > 0 {
+			if sum, err := fileSHA256(fsys, path); err == nil {
+				if version, ok := rule.Hashes[sum]; ok {
+					match.version = version
+					match.evidence = append(match.evidence, fmt.Sprintf("sha256:%s=%s", name, sum))
+					confirmed = true
+				}
+			}
+		}
+
+		if len(rule.Guards) == 0 && len(rule.Markers) == 0 {
+			continue
+		}
+		content, err := readFilePrefix(fsys, path, maxFingerprintBytes)
+		if err != nil {
+			continue
+		}
+		for _, guard := range rule.Guards {
+			if headerGuardRe(guard).MatchString(content) {
+				match.evidence = append(match.evidence, "guard:"+guard)
+				confirmed = true
+				break
+			}
+		}
+		for _, marker := range rule.Markers {
+			if strings.Contains(content, marker) {
+				match.evidence = append(match.evidence, "marker:"+marker)
+				confirmed = true
+				break
+			}
+		}
+	}
+
+	return match, confirmed
+}
+
+var (
+	headerGuardCache   = make(map[string]*regexp.Regexp)
+	headerGuardCacheMu sync.Mutex
+)
+
+// headerGuardRe 返回匹配 #ifndef GUARD / #define GUARD / #if !defined(GUARD) 的正则
+func headerGuardRe(guard string) *regexp.Regexp {
+	headerGuardCacheMu.Lock()
+	defer headerGuardCacheMu.Unlock()
+	if re, ok := headerGuardCache[guard]; ok {
+		return re
+	}
+	re := regexp.MustCompile(`(?m)^\s*#\s*(?:ifndef|define|if\s+!\s*defined)\s*\(?\s*` + regexp.QuoteMeta(guard) + `\b`)
+	headerGuardCache[guard] = re
+	return re
+}
+
+// vendoredRoot 计算内置库的目录: 位于third_party等目录下时取其下一级目录
+func vendoredRoot(root, dir string) string {
+	rel, err := filepath.Rel(root, dir)
+	if err != nil || rel == "." {
+		return dir
+	}
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+	for i := 0; i < len(parts)-1; i++ {
+		if vendorDirNames[strings.ToLower(parts[i])] {
+			return filepath.Join(root, filepath.FromSlash(strings.Join(parts[:i+2], "/")))
+		}
+	}
+	return dir
+}
+
+// pathDepth 计算path相对root的目录深度
+func pathDepth(root, path string) int {
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == "." {
+		return 0
+	}
+	return len(strings.Split(filepath.ToSlash(rel), "/"))
+}
+
+// isRegularFile 检查路径是否为普通文件
+func isRegularFile(fsys fs.FS, path string) bool {
+	info, err := statFS(fsys, path)
+	return err == nil && info.Mode().IsRegular()
+}
+
+// fileSHA256 计算文件内容的sha256
+func fileSHA256(fsys fs.FS, path string) (string, error) {
+	file, err := openFS(fsys, path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// readFilePrefix 读取文件开头最多limit字节
+func readFilePrefix(fsys fs.FS, path string, limit int64) (string, error) {
+	file, err := openFS(fsys, path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, limit))
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// VendoredFingerprintNames 返回当前已加载的指纹规则名称
+func VendoredFingerprintNames() []string {
+	vendoredFingerprintsMu.RLock()
+	defer vendoredFingerprintsMu.RUnlock()
+	names := make([]string, 0, len(vendoredFingerprints))
+	for _, rule := range vendoredFingerprints {
+		names = append(names, rule.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// VendoredExtractorFactory 内置第三方源码检测器工厂
+type VendoredExtractorFactory struct{}
+
+// CreateExtractor 创建内置第三方源码检测器
+func (f *VendoredExtractorFactory) CreateExtractor(path string) Extractor {
+	return NewVendoredExtractor(path)
+}
+
+func init() {
+	// 注册内置第三方源码检测器
+	RegisterExtractor(VendoredExtractorType, &VendoredExtractorFactory{})
+}
+
+/*
+使用示例:
+
+1. 创建检测器(参数为源码根目录):
+extractor := NewVendoredExtractor("/path/to/project")
+
+2. 加载自定义指纹(可选):
+if err := LoadVendoredFingerprints("fingerprints.json"); err != nil {
+    log.Printf("Failed to load fingerprints: %v\n", err)
+}
+
+3. 提取依赖:
+deps, err := extractor.Extract()
+if err != nil {
+    log.Printf("Failed to detect vendored libraries: %v\n", err)
+    return
+}
+
+4. 处理依赖信息:
+for _, dep := range deps {
+    fmt.Printf("Found vendored %s in %s\n", dep.Name, dep.ConfigFile)
+}
+
+指纹文件格式:
+```json
+[
+  {
+    "name": "zlib",
+    "files": ["zlib.h", "zconf.h"],
+    "anyFiles": ["inflate.c", "deflate.c"],
+    "guards": ["ZLIB_H"],
+    "hashes": {"<sha256 of zlib.h>": "1.2.11"}
+  }
+]
+```
+*/
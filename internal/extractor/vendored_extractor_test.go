@@ -0,0 +1,144 @@
+package extractor
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeTree 按相对路径写入一组文件
+func writeTree(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+}
+
+func TestVendoredExtractor_Extract(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"third_party/zlib/zlib.h":          "#ifndef ZLIB_H\n#define ZLIB_H\n#endif\n",
+		"third_party/zlib/zconf.h":         "#ifndef ZCONF_H\n#define ZCONF_H\n#endif\n",
+		"third_party/zlib/inflate.c":       "",
+		"src/external/stb/stb_image.h":     "#ifndef STBI_INCLUDE_STB_IMAGE_H\n#define STBI_INCLUDE_STB_IMAGE_H\n",
+		"src/compat/zlib.h":                "/* not the real zlib */\n",
+		"src/compat/zconf.h":               "",
+		"src/compat/inflate.c":             "",
+		".cache/zlib/zlib.h":               "#ifndef ZLIB_H\n",
+		".cache/zlib/zconf.h":              "",
+		".cache/zlib/deflate.c":            "",
+		"third_party/sqlite/sqlite3.h":     "#ifndef SQLITE3_H\n#define SQLITE3_H\n",
+		"third_party/sqlite/sqlite3.c":     "",
+		"third_party/sqlite/sqlite3ext.h":  "",
+		"third_party/sqlite/unrelated.txt": "",
+	})
+
+	deps, err := NewVendoredExtractor(root).Extract()
+	require.NoError(t, err)
+
+	found := make(map[string]string)
+	for _, dep := range deps {
+		rel, err := filepath.Rel(root, dep.ConfigFile)
+		require.NoError(t, err)
+		found[dep.Name] = filepath.ToSlash(rel)
+		assert.Equal(t, "vendored", dep.Type)
+		assert.Equal(t, "directory", dep.ConfigFileType)
+		assert.NotEmpty(t, dep.Metadata["fingerprint_match"])
+	}
+
+	assert.Equal(t, "third_party/zlib", found["zlib"])
+	assert.Equal(t, "src/external/stb", found["stb_image"])
+	assert.Equal(t, "third_party/sqlite", found["sqlite"])
+	assert.Len(t, found, 3)
+}
+
+func TestVendoredExtractor_SkipsScanRoot(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"zlib.h":    "#ifndef ZLIB_H\n#define ZLIB_H\n",
+		"zconf.h":   "",
+		"deflate.c": "",
+	})
+
+	deps, err := NewVendoredExtractor(root).Extract()
+	require.NoError(t, err)
+	assert.Empty(t, deps)
+}
+
+func TestLoadVendoredFingerprints(t *testing.T) {
+	vendoredFingerprintsMu.RLock()
+	saved := append([]VendoredFingerprint(nil), vendoredFingerprints...)
+	vendoredFingerprintsMu.RUnlock()
+	t.Cleanup(func() {
+		vendoredFingerprintsMu.Lock()
+		vendoredFingerprints = saved
+		vendoredFingerprintsMu.Unlock()
+	})
+
+	root := t.TempDir()
+	rules := filepath.Join(root, "rules.json")
+	writeTree(t, root, map[string]string{
+		"rules.json":               `[{"name": "mylib", "files": ["mylib.h"], "markers": ["MYLIB_MAGIC"]}]`,
+		"vendor/mylib/mylib.h":     "/* MYLIB_MAGIC */\n",
+		"vendor/mylib/src/other.c": "",
+	})
+	require.NoError(t, LoadVendoredFingerprints(rules))
+	assert.Contains(t, VendoredFingerprintNames(), "mylib")
+
+	deps, err := NewVendoredExtractor(root).Extract()
+	require.NoError(t, err)
+	require.Len(t, deps, 1)
+	assert.Equal(t, "mylib", deps[0].Name)
+	assert.Equal(t, []string{"files:mylib.h", "marker:MYLIB_MAGIC"}, deps[0].Metadata["fingerprint_match"])
+}
+
+func TestDefaultVendoredFingerprints_Hashes(t *testing.T) {
+	var rules []VendoredFingerprint
+	require.NoError(t, json.Unmarshal(defaultVendoredFingerprints, &rules))
+
+	hashed := make(map[string]bool)
+	for _, rule := range rules {
+		for sum, version := range rule.Hashes {
+			assert.Regexp(t, `^[0-9a-f]{64}$`, sum, rule.Name)
+			assert.NotEmpty(t, version, rule.Name)
+			hashed[rule.Name] = true
+		}
+	}
+	for _, name := range []string{"zlib", "libpng", "sqlite", "nlohmann_json"} {
+		assert.True(t, hashed[name], name)
+	}
+
+	// 内置哈希与发布版本的原始文件一致: testdata中是zlib 1.2.13发布的zlib.h
+	fixture := filepath.Join("testdata", "zlib-1.2.13", "zlib.h")
+	sum, err := fileSHA256(nil, fixture)
+	require.NoError(t, err)
+	found := false
+	for _, rule := range rules {
+		if version, ok := rule.Hashes[sum]; ok {
+			assert.Equal(t, "zlib", rule.Name)
+			assert.Equal(t, "1.2.13", version)
+			found = true
+		}
+	}
+	assert.True(t, found, "no shipped hash matches %s", fixture)
+
+	content, err := os.ReadFile(fixture)
+	require.NoError(t, err)
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"third_party/zlib/zlib.h":    string(content),
+		"third_party/zlib/zconf.h":   "",
+		"third_party/zlib/inflate.c": "",
+	})
+	deps, err := NewVendoredExtractor(root).Extract()
+	require.NoError(t, err)
+	require.Len(t, deps, 1)
+	assert.Equal(t, "1.2.13", deps[0].Version)
+	assert.Equal(t, "sha256", deps[0].Metadata["version_source"])
+}
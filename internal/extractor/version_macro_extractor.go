@@ -0,0 +1,449 @@
+package extractor
+
+import (
+	"bufio"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/lkpsg/ccscanner/pkg/models"
+)
+
+// VersionMacroRule 从头文件版本宏中提取库版本的规则
+type VersionMacroRule struct {
+	Name    string   `json:"name"`              // 库名称
+	Headers []string `json:"headers"`           // 定义版本宏的头文件(按路径后缀匹配)
+	Macros  []string `json:"macros,omitempty"`  // 直接给出版本的宏,按顺序尝试
+	Pattern string   `json:"pattern,omitempty"` // 从宏值中提取版本号的正则(取第一个分组)
+	Parts   []string `json:"parts,omitempty"`   // 按 MAJOR/MINOR/PATCH 拆分的宏
+	Packed  []int    `json:"packed,omitempty"`  // 数值型版本宏各段的除数, 如 FMT_VERSION 100201 对应 [10000, 100, 1]
+}
+
+// versionMacroData 版本规则数据文件
+type versionMacroData struct {
+	VersionFiles []string           `json:"versionFiles"` // 记录版本号的文件名
+	Rules        []VersionMacroRule `json:"rules"`        // 版本宏规则
+}
+
+//go:embed data/version_macros.json
+var defaultVersionMacros []byte
+
+var (
+	versionMacros   versionMacroData
+	versionMacrosMu sync.RWMutex
+)
+
+// defineRe 匹配 #define NAME VALUE
+var defineRe = regexp.MustCompile(`(?m)^[ \t]*#[ \t]*define[ \t]+(\w+)[ \t]+(.*)$`)
+
+// versionValueRe 合法的版本号
+var versionValueRe = regexp.MustCompile(`^\d+(?:\.\d+)*[\w.+-]*$`)
+
+func init() {
+	if err := json.Unmarshal(defaultVersionMacros, &versionMacros); err != nil {
+		panic(fmt.Sprintf("invalid version macro data: %v", err))
+	}
+}
+
+// LoadVersionMacroRules 从JSON文件加载额外的版本规则,同名规则会被替换
+func LoadVersionMacroRules(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var extra versionMacroData
+	if err := json.Unmarshal(data, &extra); err != nil {
+		return fmt.Errorf("failed to parse version rule file %s: %v", path, err)
+	}
+
+	versionMacrosMu.Lock()
+	defer versionMacrosMu.Unlock()
+	for _, rule := range extra.Rules {
+		replaced := false
+		for i := range versionMacros.Rules {
+			if versionMacros.Rules[i].Name == rule.Name {
+				versionMacros.Rules[i] = rule
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			versionMacros.Rules = append(versionMacros.Rules, rule)
+		}
+	}
+	for _, name := range extra.VersionFiles {
+		if !containsString(versionMacros.VersionFiles, name) {
+			versionMacros.VersionFiles = append(versionMacros.VersionFiles, name)
+		}
+	}
+	return nil
+}
+
+// VersionMacroExtractor 头文件版本宏提取器
+type VersionMacroExtractor struct {
+	BaseExtractor
+	treeFilter
+	config ExtractorConfig
+}
+
+// NewVersionMacroExtractor 创建头文件版本宏提取器, path为要扫描的目录
+func NewVersionMacroExtractor(path string) *VersionMacroExtractor {
+	return &VersionMacroExtractor{
+		BaseExtractor: NewBaseExtractor(path),
+		config:        DefaultConfig,
+	}
+}
+
+// Extract 扫描目录中的头文件并根据版本宏识别库及其版本
+func (e *VersionMacroExtractor) Extract() ([]models.Dependency, error) {
+	root := e.FilePath
+	if info, err := e.stat(root); err != nil {
+		return nil, NewExtractorError(VersionMacroExtractorType, root, err.Error())
+	} else if !info.IsDir() {
+		root = filepath.Dir(root)
+	}
+
+	rules := currentVersionMacroRules()
+	headers, err := collectVersionHeaders(e.fsys, root, rules, e.config.MaxDepth, e.skip)
+	if err != nil {
+		return nil, NewExtractorError(VersionMacroExtractorType, root, err.Error())
+	}
+
+	deps := make([]models.Dependency, 0)
+	for _, rule := range rules {
+		for _, header := range matchRuleHeaders(rule, headers) {
+			file := filepath.Join(root, filepath.FromSlash(header))
+			version, macro := versionFromHeader(e.fsys, rule, file)
+			if version == "" {
+				continue
+			}
+
+			dep := models.NewDependency(rule.Name)
+			dep.Version = version
+			dep.Type = "library"
+			dep.Source = "header"
+			dep.DetectedBy = "VersionMacroExtractor"
+			dep.ConfigFile = file
+			dep.ConfigFileType = "header"
+			dep.Metadata = map[string]interface{}{
+				"version_source": header + ":" + macro,
+			}
+			deps = append(deps, *dep)
+		}
+	}
+
+	e.annotate(VersionMacroExtractorType, deps)
+	return deps, nil
+}
+
+// DetectLibraryVersion 在库目录中查找版本信息
+//
+// 先按规则读取头文件中的版本宏,找不到时再读取 VERSION/version.txt 等版本文件。
+// 返回版本号以及版本来源(如 "zlib.h:ZLIB_VERSION"),未找到时均为空。
+func DetectLibraryVersion(dir, name string) (version, source string) {
+	return detectLibraryVersion(nil, dir, name)
+}
+
+// detectLibraryVersion 在fsys中的库目录查找版本信息, fsys为nil时读取本地磁盘
+func detectLibraryVersion(fsys fs.FS, dir, name string) (version, source string) {
+	versionMacrosMu.RLock()
+	var rules []VersionMacroRule
+	for _, rule := range versionMacros.Rules {
+		if rule.Name == name {
+			rules = append(rules, rule)
+		}
+	}
+	versionFiles := append([]string(nil), versionMacros.VersionFiles...)
+	versionMacrosMu.RUnlock()
+
+	if len(rules) > 0 {
+		headers, err := collectVersionHeaders(fsys, dir, rules, 4, nil)
+		if err == nil {
+			for _, rule := range rules {
+				for _, header := range matchRuleHeaders(rule, headers) {
+					file := filepath.Join(dir, filepath.FromSlash(header))
+					if version, macro := versionFromHeader(fsys, rule, file); version != "" {
+						return version, header + ":" + macro
+					}
+				}
+			}
+		}
+	}
+
+	for _, name := range versionFiles {
+		if version := readVersionFile(fsys, filepath.Join(dir, name)); version != "" {
+			return version, name
+		}
+	}
+	return "", ""
+}
+
+// currentVersionMacroRules 返回当前规则的副本
+func currentVersionMacroRules() []VersionMacroRule {
+	versionMacrosMu.RLock()
+	defer versionMacrosMu.RUnlock()
+	return append([]VersionMacroRule(nil), versionMacros.Rules...)
+}
+
+// collectVersionHeaders 收集目录下文件名出现在规则中的头文件(相对路径,使用/分隔), skip不为nil时跳过其排除的路径
+func collectVersionHeaders(fsys fs.FS, root string, rules []VersionMacroRule, maxDepth int, skip func(root, path string, info os.FileInfo) bool) ([]string, error) {
+	names := make(map[string]bool)
+	for _, rule := range rules {
+		for _, header := range rule.Headers {
+			names[path.Base(header)] = true
+		}
+	}
+
+	var headers []string
+	err := walkFS(fsys, root, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if skip != nil && skip(root, file, info) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.IsDir() {
+			if file != root && strings.HasPrefix(info.Name(), ".") {
+				return filepath.SkipDir
+			}
+			if maxDepth > 0 && pathDepth(root, file) > maxDepth {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if names[info.Name()] {
+			if rel, err := filepath.Rel(root, file); err == nil {
+				headers = append(headers, filepath.ToSlash(rel))
+			}
+		}
+		return nil
+	})
+	return headers, err
+}
+
+// matchRuleHeaders 返回与规则头文件路径后缀匹配的文件,按规则中头文件的顺序排列
+func matchRuleHeaders(rule VersionMacroRule, headers []string) []string {
+	var matched []string
+	seen := make(map[string]bool)
+	for _, want := range rule.Headers {
+		for _, header := range headers {
+			if seen[header] {
+				continue
+			}
+			if header == want || strings.HasSuffix(header, "/"+want) {
+				matched = append(matched, header)
+				seen[header] = true
+			}
+		}
+	}
+	return matched
+}
+
+// versionFromHeader 按规则从头文件中读取版本, 返回版本号和所用的宏
+func versionFromHeader(fsys fs.FS, rule VersionMacroRule, file string) (string, string) {
+	content, err := readFilePrefix(fsys, file, maxFingerprintBytes)
+	if err != nil {
+		return "", ""
+	}
+	return ruleVersion(rule, parseDefines(content))
+}
+
+// ruleVersion 根据宏定义计算版本号
+func ruleVersion(rule VersionMacroRule, defines map[string]string) (string, string) {
+	var pattern *regexp.Regexp
+	if rule.Pattern != "" {
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return "", ""
+		}
+		pattern = re
+	}
+
+	for _, macro := range rule.Macros {
+		value, ok := defines[macro]
+		if !ok {
+			continue
+		}
+		value = macroValue(value)
+		if len(rule.Packed) > 0 {
+			if n, err := strconv.Atoi(value); err == nil {
+				value = unpackVersion(n, rule.Packed)
+			}
+		}
+		if pattern != nil {
+			match := pattern.FindStringSubmatch(value)
+			if len(match) < 2 {
+				continue
+			}
+			value = match[1]
+		}
+		if versionValueRe.MatchString(value) {
+			return value, macro
+		}
+	}
+
+	if len(rule.Parts) >= 2 {
+		parts := make([]string, 0, len(rule.Parts))
+		for _, macro := range rule.Parts {
+			value, ok := defines[macro]
+			if !ok {
+				break
+			}
+			value = macroValue(value)
+			if _, err := strconv.Atoi(value); err != nil {
+				break
+			}
+			parts = append(parts, value)
+		}
+		if len(parts) >= 2 {
+			return strings.Join(parts, "."), strings.Join(rule.Parts[:len(parts)], "+")
+		}
+	}
+
+	return "", ""
+}
+
+// parseDefines 解析头文件中的宏定义, 同名宏以第一次定义为准
+func parseDefines(content string) map[string]string {
+	defines := make(map[string]string)
+	for _, match := range defineRe.FindAllStringSubmatch(content, -1) {
+		if _, ok := defines[match[1]]; !ok {
+			defines[match[1]] = match[2]
+		}
+	}
+	return defines
+}
+
+// macroValue 规范化宏的值: 去掉注释、取第一个字符串字面量或去掉整数后缀
+func macroValue(value string) string {
+	if i := strings.Index(value, "/*"); i >= 0 {
+		value = value[:i]
+	}
+	if i := strings.Index(value, "//"); i >= 0 && !strings.Contains(value[:i], `"`) {
+		value = value[:i]
+	}
+	value = strings.TrimSpace(value)
+
+	if start := strings.Index(value, `"`); start >= 0 {
+		if end := strings.Index(value[start+1:], `"`); end >= 0 {
+			return value[start+1 : start+1+end]
+		}
+	}
+
+	value = strings.Trim(value, "()")
+	return strings.TrimRight(value, "uUlL")
+}
+
+// unpackVersion 将数值型版本拆分为点分形式, 如 100201 按 [10000, 100, 1] 拆分为 10.2.1
+func unpackVersion(n int, divisors []int) string {
+	parts := make([]string, 0, len(divisors))
+	for _, d := range divisors {
+		if d <= 0 {
+			break
+		}
+		parts = append(parts, strconv.Itoa(n/d))
+		n %= d
+	}
+	return strings.Join(parts, ".")
+}
+
+// readVersionFile 读取版本文件中第一个非空行的版本号
+func readVersionFile(fsys fs.FS, file string) string {
+	f, err := openFS(fsys, file)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		return normalizeTagVersion(line)
+	}
+	return ""
+}
+
+// containsString 检查切片中是否包含字符串
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// VersionMacroExtractorFactory 头文件版本宏提取器工厂
+type VersionMacroExtractorFactory struct{}
+
+// CreateExtractor 创建头文件版本宏提取器
+func (f *VersionMacroExtractorFactory) CreateExtractor(path string) Extractor {
+	return NewVersionMacroExtractor(path)
+}
+
+func init() {
+	// 注册头文件版本宏提取器
+	RegisterExtractor(VersionMacroExtractorType, &VersionMacroExtractorFactory{})
+}
+
+/*
+使用示例:
+
+1. 创建提取器(参数为要扫描的目录):
+extractor := NewVersionMacroExtractor("/path/to/third_party/zlib")
+
+2. 加载自定义规则(可选):
+if err := LoadVersionMacroRules("version_rules.json"); err != nil {
+    log.Printf("Failed to load version rules: %v\n", err)
+}
+
+3. 提取依赖:
+deps, err := extractor.Extract()
+if err != nil {
+    log.Printf("Failed to extract versions: %v\n", err)
+    return
+}
+
+4. 处理依赖信息:
+for _, dep := range deps {
+    fmt.Printf("%s %s (%s)\n", dep.Name, dep.Version, dep.Metadata["version_source"])
+}
+
+5. 查找指定库目录的版本:
+version, source := DetectLibraryVersion("/path/to/third_party/zlib", "zlib")
+
+规则文件格式:
+```json
+{
+  "versionFiles": ["VERSION", "version.txt"],
+  "rules": [
+    {
+      "name": "zlib",
+      "headers": ["zlib.h"],
+      "macros": ["ZLIB_VERSION"],
+      "parts": ["ZLIB_VER_MAJOR", "ZLIB_VER_MINOR", "ZLIB_VER_REVISION"]
+    },
+    {
+      "name": "fmt",
+      "headers": ["fmt/core.h"],
+      "macros": ["FMT_VERSION"],
+      "packed": [10000, 100, 1]
+    }
+  ]
+}
+```
+*/
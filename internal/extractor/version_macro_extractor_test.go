@@ -0,0 +1,148 @@
+package extractor
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVersionMacroExtractor_Extract(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"zlib/zlib.h": "#ifndef ZLIB_H\n#define ZLIB_VERSION \"1.2.11\"\n#define ZLIB_VERNUM 0x12b0\n",
+		"sqlite/sqlite3.h": "#define SQLITE_VERSION        \"3.42.0\"\n" +
+			"#define SQLITE_VERSION_NUMBER 3042000\n",
+		"openssl/include/openssl/opensslv.h": "# define OPENSSL_VERSION_TEXT    \"OpenSSL 1.1.1k  25 Mar 2021\"\n",
+		"curl/include/curl/curlver.h": "#define LIBCURL_VERSION_MAJOR 8\n" +
+			"#define LIBCURL_VERSION_MINOR 4\n" +
+			"#define LIBCURL_VERSION_PATCH 0\n",
+		"fmt/include/fmt/core.h": "// The fmt library version in the form major * 10000 + minor * 100 + patch.\n#define FMT_VERSION 100201\n",
+		"lua/lua.h":              "#define LUA_VERSION_MAJOR\t\"5\"\n#define LUA_VERSION_MINOR\t\"4\"\n#define LUA_VERSION_RELEASE\t\"6\"\n#define LUA_RELEASE\tLUA_VERSION \".\" LUA_VERSION_RELEASE\n",
+		"src/core.h":             "#define CORE_H\n",
+		"unknown/zlib.h":         "/* stripped copy without version macros */\n",
+	})
+
+	deps, err := NewVersionMacroExtractor(root).Extract()
+	require.NoError(t, err)
+
+	versions := make(map[string]string)
+	for _, dep := range deps {
+		versions[dep.Name] = dep.Version
+		assert.Equal(t, "header", dep.ConfigFileType)
+	}
+	assert.Equal(t, map[string]string{
+		"zlib":    "1.2.11",
+		"sqlite":  "3.42.0",
+		"openssl": "1.1.1k",
+		"curl":    "8.4.0",
+		"fmt":     "10.2.1",
+		"lua":     "5.4.6",
+	}, versions)
+
+	// 扫描器设置的过滤规则同样作用于版本宏遍历
+	extractor := NewVersionMacroExtractor(root)
+	extractor.SetSkip(func(rel string, d fs.DirEntry) bool { return rel != "zlib" && d.IsDir() })
+	deps, err = extractor.Extract()
+	require.NoError(t, err)
+	require.Len(t, deps, 1)
+	assert.Equal(t, "zlib", deps[0].Name)
+}
+
+func TestLoadVersionMacroRules(t *testing.T) {
+	versionMacrosMu.RLock()
+	saved := versionMacroData{
+		VersionFiles: append([]string(nil), versionMacros.VersionFiles...),
+		Rules:        append([]VersionMacroRule(nil), versionMacros.Rules...),
+	}
+	versionMacrosMu.RUnlock()
+	t.Cleanup(func() {
+		versionMacrosMu.Lock()
+		versionMacros = saved
+		versionMacrosMu.Unlock()
+	})
+
+	root := t.TempDir()
+	rules := `{
+  "versionFiles": ["RELEASE"],
+  "rules": [
+    {"name": "zlib", "headers": ["zutil_version.h"], "macros": ["ZV"]},
+    {"name": "acme", "headers": ["acme/acme.h"], "parts": ["ACME_MAJOR", "ACME_MINOR"]}
+  ]
+}`
+	writeTree(t, root, map[string]string{
+		"rules.json":               rules,
+		"src/zlib/zlib.h":          "#define ZLIB_VERSION \"1.2.11\"\n",
+		"src/zlib/zutil_version.h": "#define ZV \"1.3.1\"\n",
+		"src/acme/acme.h":          "#define ACME_MAJOR 2\n#define ACME_MINOR 7\n",
+		"src/other/RELEASE":        "4.0.1\n",
+	})
+	require.NoError(t, LoadVersionMacroRules(filepath.Join(root, "rules.json")))
+
+	deps, err := NewVersionMacroExtractor(filepath.Join(root, "src")).Extract()
+	require.NoError(t, err)
+	versions := make(map[string]string)
+	for _, dep := range deps {
+		versions[dep.Name] = dep.Version
+	}
+	// 同名规则被替换, 新规则追加
+	assert.Equal(t, map[string]string{"zlib": "1.3.1", "acme": "2.7"}, versions)
+
+	version, source := DetectLibraryVersion(filepath.Join(root, "src", "other"), "other")
+	assert.Equal(t, "4.0.1", version)
+	assert.Equal(t, "RELEASE", source)
+
+	// 再次加载不会重复添加规则和版本文件
+	require.NoError(t, LoadVersionMacroRules(filepath.Join(root, "rules.json")))
+	assert.Len(t, currentVersionMacroRules(), len(saved.Rules)+1)
+
+	require.NoError(t, os.WriteFile(filepath.Join(root, "bad.json"), []byte("{"), 0644))
+	assert.Error(t, LoadVersionMacroRules(filepath.Join(root, "bad.json")))
+	assert.Error(t, LoadVersionMacroRules(filepath.Join(root, "missing.json")))
+}
+
+func TestDetectLibraryVersion(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"openssl3/include/openssl/opensslv.h": "# define OPENSSL_VERSION_MAJOR  3\n" +
+			"# define OPENSSL_VERSION_STR \"3.0.2\"\n" +
+			"# define OPENSSL_VERSION_TEXT \"OpenSSL \" OPENSSL_FULL_VERSION_STR\n",
+		"stb/stb_image.h": "",
+		"stb/VERSION":     "\n# release\nv2.28\n",
+	})
+
+	version, source := DetectLibraryVersion(filepath.Join(root, "openssl3"), "openssl")
+	assert.Equal(t, "3.0.2", version)
+	assert.Equal(t, "include/openssl/opensslv.h:OPENSSL_VERSION_STR", source)
+
+	version, source = DetectLibraryVersion(filepath.Join(root, "stb"), "stb_image")
+	assert.Equal(t, "2.28", version)
+	assert.Equal(t, "VERSION", source)
+
+	version, _ = DetectLibraryVersion(filepath.Join(root, "missing"), "zlib")
+	assert.Empty(t, version)
+}
+
+func TestVendoredExtractor_DetectsVersion(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"third_party/zlib/zlib.h":    "#ifndef ZLIB_H\n#define ZLIB_H\n#define ZLIB_VERSION \"1.3.1\"\n",
+		"third_party/zlib/zconf.h":   "",
+		"third_party/zlib/deflate.c": "",
+	})
+
+	deps, err := NewVendoredExtractor(root).Extract()
+	require.NoError(t, err)
+	require.Len(t, deps, 1)
+	assert.Equal(t, "1.3.1", deps[0].Version)
+	assert.Equal(t, "zlib.h:ZLIB_VERSION", deps[0].Metadata["version_source"])
+}
+
+func TestUnpackVersion(t *testing.T) {
+	assert.Equal(t, "10.2.1", unpackVersion(100201, []int{10000, 100, 1}))
+	assert.Equal(t, "1.14", unpackVersion(1140, []int{1000, 10}))
+	assert.Equal(t, "1.82.0", unpackVersion(108200, []int{100000, 100, 1}))
+}
@@ -97,6 +97,13 @@ func (e *YarnExtractor) Extract(dir string) ([]models.Dependency, error) {
 
 	e.logger.Info("Completed Yarn dependency extraction",
 		zap.Int("total_deps", len(allDeps)))
+	locator := newLocator(nil)
+	for i := range allDeps {
+		allDeps[i].PURL = npmPackageURL(allDeps[i].Name, lockedVersion(&allDeps[i]))
+		locator.fill(YarnExtractorType, sourceFile(allDeps[i].Source), &allDeps[i])
+		scoreConfidence(YarnExtractorType, &allDeps[i])
+		classifyScope(YarnExtractorType, &allDeps[i])
+	}
 	return allDeps, nil
 }
 
@@ -220,12 +227,13 @@ func (e *YarnExtractor) parseLockfile(file string) ([]models.Dependency, error)
 		}
 
 		dep := models.Dependency{
-			Name:        parts[0],
-			Version:     entry.Version,
-			Type:        "locked",
-			Required:    true,
-			BuildSystem: "yarn",
-			Source:      file,
+			Name:         parts[0],
+			Version:      entry.Version,
+			Type:         "locked",
+			Relationship: models.RelationshipTransitive,
+			Required:     true,
+			BuildSystem:  "yarn",
+			Source:       file,
 		}
 
 		// 添加解析URL
@@ -242,24 +250,27 @@ func (e *YarnExtractor) parseLockfile(file string) ([]models.Dependency, error)
 		var subDeps []models.Dependency
 		for subName, subVersion := range entry.Dependencies {
 			subDeps = append(subDeps, models.Dependency{
-				Name:        subName,
-				Version:     subVersion,
-				Type:        "transitive",
-				Required:    true,
-				BuildSystem: "yarn",
-				Source:      file,
+				Name:         subName,
+				Version:      subVersion,
+				Type:         "transitive",
+				Relationship: models.RelationshipTransitive,
+				Required:     true,
+				BuildSystem:  "yarn",
+				Source:       file,
 			})
 		}
 
 		// 处理可选子依赖
 		for subName, subVersion := range entry.OptionalDependencies {
 			subDeps = append(subDeps, models.Dependency{
-				Name:        subName,
-				Version:     subVersion,
-				Type:        "transitive",
-				Required:    false,
-				BuildSystem: "yarn",
-				Source:      file,
+				Name:         subName,
+				Version:      subVersion,
+				Type:         "transitive",
+				Relationship: models.RelationshipTransitive,
+				Required:     false,
+				Scope:        models.ScopeOptional,
+				BuildSystem:  "yarn",
+				Source:       file,
 			})
 		}
 
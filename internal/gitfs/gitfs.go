@@ -0,0 +1,385 @@
+package gitfs
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// errIsDir 对目录执行文件操作
+var errIsDir = errors.New("is a directory")
+
+// FS Git提交的文件树构成的只读文件系统, 文件内容直接从对象库读取, 不需要检出工作区
+//
+// 实现 fs.ReadDirFS、fs.ReadFileFS 和 fs.StatFS。符号链接不出现在文件系统中,
+// 子模块(gitlink)显示为空目录, 与未递归克隆时的工作区一致。
+type FS struct {
+	mu       sync.Mutex // go-git的对象存储不支持并发读取, 保护以下字段的访问
+	repo     *git.Repository
+	revision string
+	commit   *object.Commit
+	tree     *object.Tree // 扫描的目录对应的树, path为仓库子目录时是提交树的子树
+	prefix   string       // 扫描的目录相对仓库根目录的路径, 扫描整个仓库时为"."
+}
+
+// Open 打开path所在的Git仓库, 将revision(标签、分支、提交哈希或 HEAD~1 等写法)解析为提交
+//
+// path为仓库中的子目录时, 文件系统的根目录为该子目录在提交中的内容。
+func Open(path, revision string) (*FS, error) {
+	repo, err := git.PlainOpenWithOptions(path, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open git repository: %v", err)
+	}
+
+	hash, err := repo.ResolveRevision(plumbing.Revision(revision))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve revision %s: %v", revision, err)
+	}
+	commit, err := repo.CommitObject(*hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit %s: %v", hash, err)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tree of commit %s: %v", hash, err)
+	}
+
+	// 裸仓库没有工作区, 总是扫描整个提交
+	prefix := "."
+	if wt, err := repo.Worktree(); err == nil {
+		dir, err := subdir(wt.Filesystem.Root(), path)
+		if err != nil {
+			return nil, err
+		}
+		if dir != "." {
+			if tree, err = tree.Tree(dir); err != nil {
+				return nil, fmt.Errorf("directory %s not found in %s: %v", dir, revision, err)
+			}
+		}
+		prefix = dir
+	}
+
+	return &FS{repo: repo, revision: revision, commit: commit, tree: tree, prefix: prefix}, nil
+}
+
+// subdir 返回path相对工作区根目录的路径
+func subdir(root, path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve path: %v", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		abs = resolved
+	}
+	if resolved, err := filepath.EvalSymlinks(root); err == nil {
+		root = resolved
+	}
+	rel, err := filepath.Rel(root, abs)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve path: %v", err)
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// Revision 返回打开时指定的版本
+func (f *FS) Revision() string {
+	return f.revision
+}
+
+// Commit 返回版本解析得到的提交哈希
+func (f *FS) Commit() string {
+	return f.commit.Hash.String()
+}
+
+// DisplayPath 返回文件在提交中的位置, 使用git的 <revision>:<path> 写法, 如 v2.3.0:src/vcpkg.json
+// path相对仓库根目录, 打开的是子目录时包含该子目录
+func (f *FS) DisplayPath(name string) string {
+	if name = f.repoPath(name); name == "." {
+		return f.revision + ":"
+	}
+	return f.revision + ":" + name
+}
+
+// Gitlink 返回子模块目录在提交中固定的提交哈希, 错误中的路径相对仓库根目录
+func (f *FS) Gitlink(name string) (string, error) {
+	entry, err := f.find("gitlink", name)
+	if err != nil {
+		return "", &fs.PathError{Op: "gitlink", Path: f.repoPath(name), Err: errors.Unwrap(err)}
+	}
+	if entry.Mode != filemode.Submodule {
+		return "", &fs.PathError{Op: "gitlink", Path: f.repoPath(name), Err: errors.New("not a submodule")}
+	}
+	return entry.Hash.String(), nil
+}
+
+// repoPath 将文件系统中的路径转换为相对仓库根目录的路径
+func (f *FS) repoPath(name string) string {
+	return path.Join(f.prefix, name)
+}
+
+// Open 实现 fs.FS
+func (f *FS) Open(name string) (fs.File, error) {
+	entry, err := f.find("open", name)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.info(entry)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	if info.IsDir() {
+		entries, err := f.entries(name, entry)
+		if err != nil {
+			return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+		}
+		return &openDir{path: name, info: info, entries: entries}, nil
+	}
+	data, err := f.read(entry)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	return &openFile{Reader: bytes.NewReader(data), info: info}, nil
+}
+
+// ReadFile 实现 fs.ReadFileFS
+func (f *FS) ReadFile(name string) ([]byte, error) {
+	entry, err := f.find("read", name)
+	if err != nil {
+		return nil, err
+	}
+	if isDir(entry.Mode) {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: errIsDir}
+	}
+	data, err := f.read(entry)
+	if err != nil {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: err}
+	}
+	return data, nil
+}
+
+// Stat 实现 fs.StatFS
+func (f *FS) Stat(name string) (fs.FileInfo, error) {
+	entry, err := f.find("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.info(entry)
+	if err != nil {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: err}
+	}
+	return info, nil
+}
+
+// ReadDir 实现 fs.ReadDirFS, 条目按名称排序
+func (f *FS) ReadDir(name string) ([]fs.DirEntry, error) {
+	entry, err := f.find("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+	if !isDir(entry.Mode) {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: errors.New("not a directory")}
+	}
+	entries, err := f.entries(name, entry)
+	if err != nil {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: err}
+	}
+	return entries, nil
+}
+
+// find 查找路径对应的树条目, 根目录返回名为"."的目录条目
+func (f *FS) find(op, name string) (*object.TreeEntry, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	if name == "." {
+		return &object.TreeEntry{Name: ".", Mode: filemode.Dir, Hash: f.tree.Hash}, nil
+	}
+
+	f.mu.Lock()
+	entry, err := f.tree.FindEntry(name)
+	f.mu.Unlock()
+	if err != nil || entry.Mode == filemode.Symlink {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+	}
+	return entry, nil
+}
+
+// entries 返回目录中按名称排序的条目, 子模块没有条目
+func (f *FS) entries(name string, dir *object.TreeEntry) ([]fs.DirEntry, error) {
+	if dir.Mode == filemode.Submodule {
+		return []fs.DirEntry{}, nil
+	}
+
+	f.mu.Lock()
+	tree := f.tree
+	var err error
+	if name != "." {
+		tree, err = f.tree.Tree(name)
+	}
+	f.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]fs.DirEntry, 0, len(tree.Entries))
+	for i := range tree.Entries {
+		if tree.Entries[i].Mode == filemode.Symlink {
+			continue
+		}
+		entries = append(entries, &dirEntry{fsys: f, entry: tree.Entries[i]})
+	}
+	// git按目录名加"/"排序, 与 fs.ReadDir 要求的按名称排序不同
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+	return entries, nil
+}
+
+// info 返回条目信息, 文件大小从blob对象读取
+func (f *FS) info(entry *object.TreeEntry) (fileInfo, error) {
+	info := fileInfo{name: path.Base(entry.Name), modTime: f.commit.Committer.When}
+	switch {
+	case isDir(entry.Mode):
+		info.mode = fs.ModeDir | 0o555
+		return info, nil
+	case entry.Mode == filemode.Executable:
+		info.mode = 0o755
+	default:
+		info.mode = 0o644
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	blob, err := f.repo.BlobObject(entry.Hash)
+	if err != nil {
+		return fileInfo{}, err
+	}
+	info.size = blob.Size
+	return info, nil
+}
+
+// read 读取文件内容
+func (f *FS) read(entry *object.TreeEntry) ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	blob, err := f.repo.BlobObject(entry.Hash)
+	if err != nil {
+		return nil, err
+	}
+	r, err := blob.Reader()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+// isDir 判断条目是否显示为目录
+func isDir(mode filemode.FileMode) bool {
+	return mode == filemode.Dir || mode == filemode.Submodule
+}
+
+// dirEntry 目录条目, 文件大小在调用Info时读取
+type dirEntry struct {
+	fsys  *FS
+	entry object.TreeEntry
+}
+
+func (d *dirEntry) Name() string { return d.entry.Name }
+func (d *dirEntry) IsDir() bool  { return isDir(d.entry.Mode) }
+
+func (d *dirEntry) Type() fs.FileMode {
+	if d.IsDir() {
+		return fs.ModeDir
+	}
+	return 0
+}
+
+func (d *dirEntry) Info() (fs.FileInfo, error) {
+	return d.fsys.info(&d.entry)
+}
+
+// fileInfo 条目信息, 修改时间为提交时间
+type fileInfo struct {
+	name    string
+	size    int64
+	mode    fs.FileMode
+	modTime time.Time
+}
+
+func (i fileInfo) Name() string       { return i.name }
+func (i fileInfo) Size() int64        { return i.size }
+func (i fileInfo) Mode() fs.FileMode  { return i.mode }
+func (i fileInfo) ModTime() time.Time { return i.modTime }
+func (i fileInfo) IsDir() bool        { return i.mode.IsDir() }
+func (i fileInfo) Sys() interface{}   { return nil }
+
+// openFile 打开的文件, 内容已读入内存, 支持 io.Seeker 和 io.ReaderAt
+type openFile struct {
+	*bytes.Reader
+	info fileInfo
+}
+
+func (f *openFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *openFile) Close() error               { return nil }
+
+// openDir 打开的目录
+type openDir struct {
+	path    string
+	info    fileInfo
+	entries []fs.DirEntry
+	offset  int
+}
+
+func (d *openDir) Stat() (fs.FileInfo, error) { return d.info, nil }
+func (d *openDir) Close() error               { return nil }
+
+func (d *openDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.path, Err: errIsDir}
+}
+
+// ReadDir 实现 fs.ReadDirFile
+func (d *openDir) ReadDir(count int) ([]fs.DirEntry, error) {
+	rest := d.entries[d.offset:]
+	if count <= 0 {
+		d.offset = len(d.entries)
+		return rest, nil
+	}
+	if len(rest) == 0 {
+		return nil, io.EOF
+	}
+	if count > len(rest) {
+		count = len(rest)
+	}
+	d.offset += count
+	return rest[:count], nil
+}
+
+/*
+使用示例:
+
+fsys, err := gitfs.Open("/src/product", "v2.3.0")
+if err != nil {
+	log.Fatal(err)
+}
+fmt.Println(fsys.Commit())
+fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+	if err == nil && !d.IsDir() {
+		fmt.Println(fsys.DisplayPath(name)) // v2.3.0:src/vcpkg.json
+	}
+	return err
+})
+*/
@@ -0,0 +1,165 @@
+package gitfs
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/format/index"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testSignature = &object.Signature{Name: "test", Email: "test@example.com", When: time.Unix(1700000000, 0)}
+
+// commitFiles 写入文件并提交全部改动
+func commitFiles(t *testing.T, repo *git.Repository, dir string, files map[string]string) plumbing.Hash {
+	t.Helper()
+	wt, err := repo.Worktree()
+	require.NoError(t, err)
+	for name, content := range files {
+		file := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(file), 0755))
+		require.NoError(t, os.WriteFile(file, []byte(content), 0644))
+	}
+	require.NoError(t, wt.AddWithOptions(&git.AddOptions{All: true}))
+	hash, err := wt.Commit("update", &git.CommitOptions{Author: testSignature})
+	require.NoError(t, err)
+	return hash
+}
+
+// newTestRepo 创建两个提交的仓库, 第一个提交打上附注标签v1.0.0, 工作区停留在第二个提交
+func newTestRepo(t *testing.T) (string, plumbing.Hash) {
+	t.Helper()
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	require.NoError(t, err)
+
+	release := commitFiles(t, repo, dir, map[string]string{
+		"src/vcpkg.json":     `{"name": "app", "dependencies": [{"name": "zlib"}]}`,
+		"src/CMakeLists.txt": "find_package(OpenSSL REQUIRED)\n",
+		"README":             "v1\n",
+	})
+	_, err = repo.CreateTag("v1.0.0", release, &git.CreateTagOptions{Tagger: testSignature, Message: "release"})
+	require.NoError(t, err)
+
+	commitFiles(t, repo, dir, map[string]string{
+		"src/vcpkg.json": `{"name": "app", "dependencies": [{"name": "fmt"}]}`,
+		"docs/guide.md":  "guide\n",
+	})
+	return dir, release
+}
+
+func TestOpen(t *testing.T) {
+	dir, release := newTestRepo(t)
+
+	fsys, err := Open(dir, "v1.0.0")
+	require.NoError(t, err)
+	assert.Equal(t, "v1.0.0", fsys.Revision())
+	assert.Equal(t, release.String(), fsys.Commit())
+	require.NoError(t, fstest.TestFS(fsys, "src/vcpkg.json", "src/CMakeLists.txt", "README"))
+
+	// 读取标签处的内容, 不受工作区影响
+	content, err := fs.ReadFile(fsys, "src/vcpkg.json")
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "zlib")
+	_, err = fsys.Stat("docs/guide.md")
+	assert.True(t, errors.Is(err, fs.ErrNotExist))
+	_, err = fsys.Open("../etc/passwd")
+	assert.True(t, errors.Is(err, fs.ErrInvalid))
+	_, err = fsys.ReadFile("src")
+	assert.Error(t, err)
+
+	info, err := fsys.Stat("README")
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), info.Size())
+	assert.True(t, info.ModTime().Equal(testSignature.When))
+	assert.Equal(t, "v1.0.0:src/vcpkg.json", fsys.DisplayPath("src/vcpkg.json"))
+
+	// 分支、提交哈希和相对写法
+	head, err := Open(dir, "HEAD~1")
+	require.NoError(t, err)
+	assert.Equal(t, release.String(), head.Commit())
+	_, err = Open(dir, release.String()[:12])
+	require.NoError(t, err)
+	_, err = Open(dir, "v9.9.9")
+	assert.ErrorContains(t, err, "failed to resolve revision v9.9.9")
+	_, err = Open(t.TempDir(), "HEAD")
+	assert.ErrorContains(t, err, "failed to open git repository")
+}
+
+func TestOpen_Subdir(t *testing.T) {
+	dir, _ := newTestRepo(t)
+
+	// 子目录作为根目录
+	fsys, err := Open(filepath.Join(dir, "src"), "v1.0.0")
+	require.NoError(t, err)
+	entries, err := fs.ReadDir(fsys, ".")
+	require.NoError(t, err)
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	assert.Equal(t, []string{"CMakeLists.txt", "vcpkg.json"}, names)
+	// 显示的路径仍相对仓库根目录
+	assert.Equal(t, "v1.0.0:src/vcpkg.json", fsys.DisplayPath("vcpkg.json"))
+	assert.Equal(t, "v1.0.0:src", fsys.DisplayPath("."))
+
+	// 子目录在该版本中不存在
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "docs"), 0755))
+	_, err = Open(filepath.Join(dir, "docs"), "v1.0.0")
+	assert.ErrorContains(t, err, "directory docs not found in v1.0.0")
+}
+
+func TestGitlink(t *testing.T) {
+	dir, _ := newTestRepo(t)
+	repo, err := git.PlainOpen(dir)
+	require.NoError(t, err)
+
+	// 直接在索引中添加gitlink, 模拟未递归克隆的子模块
+	pinned := plumbing.NewHash("0123456789abcdef0123456789abcdef01234567")
+	idx, err := repo.Storer.Index()
+	require.NoError(t, err)
+	idx.Entries = append(idx.Entries, &index.Entry{Name: "third_party/zlib", Mode: filemode.Submodule, Hash: pinned})
+	require.NoError(t, repo.Storer.SetIndex(idx))
+	wt, err := repo.Worktree()
+	require.NoError(t, err)
+	_, err = wt.Commit("add submodule", &git.CommitOptions{Author: testSignature})
+	require.NoError(t, err)
+
+	fsys, err := Open(dir, "HEAD")
+	require.NoError(t, err)
+	commit, err := fsys.Gitlink("third_party/zlib")
+	require.NoError(t, err)
+	assert.Equal(t, pinned.String(), commit)
+	_, err = fsys.Gitlink("README")
+	assert.Error(t, err)
+
+	// 子模块显示为空目录
+	entries, err := fs.ReadDir(fsys, "third_party/zlib")
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+	require.NoError(t, fstest.TestFS(fsys, "third_party/zlib", "docs/guide.md"))
+
+	// 打开子目录时子模块路径相对该子目录, 错误中报告仓库中的完整路径
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "third_party"), 0755))
+	sub, err := Open(filepath.Join(dir, "third_party"), "HEAD")
+	require.NoError(t, err)
+	commit, err = sub.Gitlink("zlib")
+	require.NoError(t, err)
+	assert.Equal(t, pinned.String(), commit)
+	assert.Equal(t, "HEAD:third_party/zlib", sub.DisplayPath("zlib"))
+	_, err = sub.Gitlink("missing")
+	var pathErr *fs.PathError
+	require.ErrorAs(t, err, &pathErr)
+	assert.Equal(t, "third_party/missing", pathErr.Path)
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+}
@@ -0,0 +1,157 @@
+package graph
+
+import (
+	"strings"
+
+	"github.com/lkpsg/ccscanner/internal/identity"
+	"github.com/lkpsg/ccscanner/internal/merger"
+	"github.com/lkpsg/ccscanner/pkg/models"
+)
+
+// maxPaths 每个依赖最多记录的引入路径数
+const maxPaths = 5
+
+// Classify 根据依赖关系图区分直接依赖和传递依赖, 并记录每个依赖的引入路径
+//
+// 项目自身声明的依赖(提取器未标记为传递依赖)是直接依赖, 其余只出现在锁定文件、
+// 嵌套子模块等位置的依赖是传递依赖。提取器未标记、但被其他依赖引入且没有清单或构建脚本
+// 证据的依赖(如锁定文件、启发式检测的结果)同样是传递依赖。引入路径从直接依赖出发,
+// 沿 Dependencies 中列出的子依赖以及 Parent 指向的上级依赖广度优先搜索, 较短的路径排在前面。
+func Classify(deps []models.Dependency) {
+	children := buildEdges(deps)
+	introduced := make([]bool, len(deps))
+	for i := range children {
+		for _, j := range children[i] {
+			introduced[j] = true
+		}
+	}
+
+	paths := make([][][]int, len(deps))
+	var queue [][]int
+	for i := range deps {
+		if deps[i].IsDirect() && (!introduced[i] || declaredByProject(&deps[i])) {
+			deps[i].Relationship = models.RelationshipDirect
+			paths[i] = [][]int{{i}}
+			queue = append(queue, paths[i][0])
+		} else {
+			deps[i].Relationship = models.RelationshipTransitive
+		}
+	}
+
+	// 按路径广度优先扩展, 每个依赖最多保留 maxPaths 条路径, 保证搜索有界
+	for len(queue) > 0 {
+		path := queue[0]
+		queue = queue[1:]
+		for _, j := range children[path[len(path)-1]] {
+			if len(paths[j]) >= maxPaths || containsIndex(path, j) {
+				continue
+			}
+			extended := make([]int, len(path), len(path)+1)
+			copy(extended, path)
+			extended = append(extended, j)
+			paths[j] = append(paths[j], extended)
+			queue = append(queue, extended)
+		}
+	}
+
+	for i := range deps {
+		deps[i].IntroducedBy = nil
+		for _, path := range paths[i] {
+			names := make([]string, len(path))
+			for k, j := range path {
+				names[k] = deps[j].Name
+			}
+			deps[i].IntroducedBy = append(deps[i].IntroducedBy, names)
+		}
+	}
+}
+
+// declaredByProject 判断依赖是否有项目自身声明的证据(包管理清单或构建脚本), 合并后的依赖检查每条证据
+func declaredByProject(dep *models.Dependency) bool {
+	if len(dep.Evidence) == 0 {
+		kind := merger.ClassifySource(dep)
+		return kind == merger.SourceManifest || kind == merger.SourceBuildScript
+	}
+	for _, evidence := range dep.Evidence {
+		if evidence.Kind == merger.SourceManifest.String() || evidence.Kind == merger.SourceBuildScript.String() {
+			return true
+		}
+	}
+	return false
+}
+
+// buildEdges 建立父依赖到子依赖的边, 依赖名称先按规范ID和原始名称匹配, 再通过身份数据库解析别名
+// 子依赖与父依赖属于同一生态
+func buildEdges(deps []models.Dependency) [][]int {
+	index := make(map[string]int, len(deps)*2)
+	for i := range deps {
+		index[strings.ToLower(deps[i].Key())] = i
+	}
+	// 语言生态中的包只按带生态前缀的规范ID匹配, 避免与同名的C/C++库相连
+	for i := range deps {
+		if identity.Ecosystem(&deps[i]) != "" {
+			continue
+		}
+		if _, exists := index[strings.ToLower(deps[i].Name)]; !exists {
+			index[strings.ToLower(deps[i].Name)] = i
+		}
+	}
+	resolve := func(name, ecosystem string) (int, bool) {
+		if ecosystem == "" {
+			if i, ok := index[strings.ToLower(name)]; ok {
+				return i, true
+			}
+		}
+		i, ok := index[strings.ToLower(identity.Default().ResolveName(name, ecosystem))]
+		return i, ok
+	}
+
+	children := make([][]int, len(deps))
+	addEdge := func(parent, child int) {
+		if parent == child {
+			return
+		}
+		for _, existing := range children[parent] {
+			if existing == child {
+				return
+			}
+		}
+		children[parent] = append(children[parent], child)
+	}
+	for i := range deps {
+		ecosystem := identity.Ecosystem(&deps[i])
+		for _, name := range deps[i].Dependencies {
+			if j, ok := resolve(name, ecosystem); ok {
+				addEdge(i, j)
+			}
+		}
+		// Parent只在指向扫描结果中的依赖时才构成边(构建系统中的目标名不算)
+		if deps[i].Parent != "" {
+			if j, ok := resolve(deps[i].Parent, ecosystem); ok {
+				addEdge(j, i)
+			}
+		}
+	}
+	return children
+}
+
+// containsIndex 判断路径中是否已包含指定依赖, 用于跳过环
+func containsIndex(path []int, i int) bool {
+	for _, existing := range path {
+		if existing == i {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+使用示例:
+
+deps := []models.Dependency{
+	{Name: "curl", Dependencies: []string{"openssl"}},
+	{Name: "openssl", Relationship: models.RelationshipTransitive},
+}
+graph.Classify(deps)
+fmt.Println(deps[1].Relationship, deps[1].IntroducedBy) // transitive [[curl openssl]]
+*/
@@ -0,0 +1,115 @@
+package graph
+
+import (
+	"testing"
+
+	"github.com/lkpsg/ccscanner/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassify(t *testing.T) {
+	deps := []models.Dependency{
+		// 项目直接声明, 同时也被 fmt 引入
+		{Name: "curl", Dependencies: []string{"openssl", "zlib"}},
+		{Name: "openssl", Relationship: models.RelationshipTransitive, Dependencies: []string{"zlib"}},
+		{Name: "zlib", Relationship: models.RelationshipTransitive},
+		// 只出现在锁定文件中, 没有任何依赖引用
+		{Name: "left-pad", Relationship: models.RelationshipTransitive},
+		{Name: "fmt", Dependencies: []string{"curl"}},
+	}
+	Classify(deps)
+
+	assert.Equal(t, models.RelationshipDirect, deps[0].Relationship)
+	assert.Equal(t, [][]string{{"curl"}, {"fmt", "curl"}}, deps[0].IntroducedBy)
+
+	assert.Equal(t, models.RelationshipTransitive, deps[1].Relationship)
+	assert.Equal(t, [][]string{{"curl", "openssl"}, {"fmt", "curl", "openssl"}}, deps[1].IntroducedBy)
+
+	// 较短的路径排在前面
+	assert.False(t, deps[2].IsDirect())
+	assert.Equal(t, [][]string{
+		{"curl", "zlib"},
+		{"curl", "openssl", "zlib"},
+		{"fmt", "curl", "zlib"},
+		{"fmt", "curl", "openssl", "zlib"},
+	}, deps[2].IntroducedBy)
+
+	assert.Equal(t, models.RelationshipTransitive, deps[3].Relationship)
+	assert.Empty(t, deps[3].IntroducedBy)
+
+	assert.True(t, deps[4].IsDirect())
+	assert.Equal(t, [][]string{{"fmt"}}, deps[4].IntroducedBy)
+}
+
+func TestClassify_Parent(t *testing.T) {
+	deps := []models.Dependency{
+		{Name: "grpc", CanonicalID: "grpc/grpc"},
+		{Name: "abseil-cpp", Relationship: models.RelationshipTransitive, Parent: "grpc/grpc"},
+		// Parent 是构建目标而不是依赖时不构成边
+		{Name: "ssl", Parent: "app"},
+	}
+	Classify(deps)
+
+	assert.Equal(t, models.RelationshipTransitive, deps[1].Relationship)
+	assert.Equal(t, [][]string{{"grpc", "abseil-cpp"}}, deps[1].IntroducedBy)
+	assert.True(t, deps[2].IsDirect())
+	assert.Equal(t, [][]string{{"ssl"}}, deps[2].IntroducedBy)
+}
+
+func TestClassify_Unmarked(t *testing.T) {
+	deps := []models.Dependency{
+		{Name: "app", ConfigFileType: "vcpkg.json", Dependencies: []string{"openssl", "fmt", "curl"}},
+		// 提取器没有标记为传递依赖, 但只出现在锁定文件中且被其他依赖引入
+		{Name: "openssl", ConfigFileType: "conan.lock", Dependencies: []string{"zlib"}},
+		{Name: "zlib", ConfigFileType: "header"},
+		// 被引入但同时由构建脚本或清单声明
+		{Name: "fmt", ConfigFileType: "CMakeLists.txt"},
+		{Name: "curl", ConfigFileType: "flake.lock", Evidence: []models.Evidence{{Kind: "lockfile"}, {Kind: "manifest"}}},
+		// 没有被引入的启发式结果仍按直接依赖处理
+		{Name: "stb", ConfigFileType: "directory"},
+	}
+	Classify(deps)
+
+	assert.Equal(t, models.RelationshipTransitive, deps[1].Relationship)
+	assert.Equal(t, [][]string{{"app", "openssl"}}, deps[1].IntroducedBy)
+	assert.Equal(t, models.RelationshipTransitive, deps[2].Relationship)
+	assert.Equal(t, [][]string{{"app", "openssl", "zlib"}}, deps[2].IntroducedBy)
+	assert.True(t, deps[3].IsDirect())
+	assert.True(t, deps[4].IsDirect())
+	assert.True(t, deps[5].IsDirect())
+}
+
+func TestClassify_Cycle(t *testing.T) {
+	deps := []models.Dependency{
+		{Name: "a", Dependencies: []string{"b"}},
+		{Name: "b", Relationship: models.RelationshipTransitive, Dependencies: []string{"a"}},
+	}
+	Classify(deps)
+
+	assert.Equal(t, [][]string{{"a"}}, deps[0].IntroducedBy)
+	assert.Equal(t, [][]string{{"a", "b"}}, deps[1].IntroducedBy)
+}
+
+func TestClassify_MaxPaths(t *testing.T) {
+	deps := []models.Dependency{{Name: "zlib", Relationship: models.RelationshipTransitive}}
+	for _, name := range []string{"a", "b", "c", "d", "e", "f", "g"} {
+		deps = append(deps, models.Dependency{Name: name, Dependencies: []string{"zlib"}})
+	}
+	Classify(deps)
+
+	assert.Len(t, deps[0].IntroducedBy, maxPaths)
+	assert.Equal(t, []string{"a", "zlib"}, deps[0].IntroducedBy[0])
+}
+
+func TestClassify_Ecosystems(t *testing.T) {
+	deps := []models.Dependency{
+		{Name: "zlib", CanonicalID: "zlib", ConfigFileType: "CMakeLists.txt"},
+		{Name: "app", CanonicalID: "npm:app", PURL: "pkg:npm/app@1.0.0", Dependencies: []string{"zlib"}},
+		{Name: "zlib", CanonicalID: "npm:zlib", PURL: "pkg:npm/zlib@1.0.5", Relationship: models.RelationshipTransitive},
+	}
+	Classify(deps)
+
+	// npm包的子依赖只匹配npm包, 不与同名的C库相连
+	assert.Equal(t, [][]string{{"zlib"}}, deps[0].IntroducedBy)
+	assert.Equal(t, [][]string{{"app", "zlib"}}, deps[2].IntroducedBy)
+}
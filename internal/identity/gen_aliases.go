@@ -0,0 +1,153 @@
+//go:build ignore
+
+// gen_aliases 根据 ccscanner/data/submods.json 生成 data/repositories.json
+//
+// 用法(在 internal/identity 目录下执行):
+//
+//	go run gen_aliases.go ../../ccscanner/data/submods.json
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// cLanguages 需要收录的C/C++家族语言
+var cLanguages = map[string]bool{
+	"C": true, "C++": true, "Objective-C": true, "Objective-C++": true,
+	"CMake": true, "Assembly": true, "Cuda": true, "Makefile": true,
+}
+
+// genericNames 过于通用、不能作为包名索引的仓库名
+var genericNames = map[string]bool{
+	"build": true, "cmake": true, "common": true, "config": true, "core": true,
+	"data": true, "deps": true, "docs": true, "example": true, "examples": true,
+	"external": true, "extern": true, "lib": true, "libs": true, "modules": true,
+	"scripts": true, "src": true, "test": true, "tests": true, "third_party": true,
+	"thirdparty": true, "tools": true, "utils": true, "vendor": true, "json": true,
+}
+
+type curatedPackage struct {
+	ID         string   `json:"id"`
+	Repository string   `json:"repository"`
+	Aliases    []string `json:"aliases"`
+}
+
+type repositoryIndex struct {
+	Repositories map[string]string `json:"repositories"`
+	Names        map[string]string `json:"names"`
+}
+
+func main() {
+	if len(os.Args) != 2 {
+		fmt.Fprintln(os.Stderr, "usage: go run gen_aliases.go <submods.json>")
+		os.Exit(2)
+	}
+	if err := run(os.Args[1]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(submodsPath string) error {
+	var submods map[string]string
+	if err := readJSON(submodsPath, &submods); err != nil {
+		return err
+	}
+	var curated []curatedPackage
+	if err := readJSON("data/packages.json", &curated); err != nil {
+		return err
+	}
+
+	// 精选包的ID与别名都可用于识别fork仓库
+	known := make(map[string]string)
+	curatedRepos := make(map[string]bool)
+	for _, pkg := range curated {
+		known[strings.ToLower(pkg.ID)] = pkg.ID
+		for _, alias := range pkg.Aliases {
+			if len(alias) > 2 {
+				known[strings.ToLower(alias)] = pkg.ID
+			}
+		}
+		if pkg.Repository != "" {
+			curatedRepos[strings.ToLower(pkg.Repository)] = true
+			_, repo, _ := strings.Cut(pkg.Repository, "/")
+			known[strings.ToLower(repo)] = pkg.ID
+		}
+	}
+
+	index := repositoryIndex{
+		Repositories: make(map[string]string),
+		Names:        make(map[string]string),
+	}
+	owners := make(map[string][]string)
+	for key, language := range submods {
+		if !cLanguages[language] {
+			continue
+		}
+		owner, repo, ok := strings.Cut(strings.ToLower(key), "@@")
+		if !ok || owner == "" || repo == "" {
+			continue
+		}
+		full := owner + "/" + repo
+		if curatedRepos[full] {
+			continue
+		}
+		if id, ok := known[repo]; ok {
+			index.Repositories[full] = id
+			continue
+		}
+		owners[repo] = append(owners[repo], full)
+	}
+
+	// 只有唯一拥有者的仓库名才能安全地映射回仓库
+	for repo, fulls := range owners {
+		if len(fulls) != 1 || len(repo) < 3 || genericNames[repo] {
+			continue
+		}
+		index.Names[repo] = fulls[0]
+	}
+
+	return writeJSON("data/repositories.json", index)
+}
+
+func readJSON(path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// writeJSON 按键排序、每行一项输出, 便于审阅差异
+func writeJSON(path string, index repositoryIndex) error {
+	var b strings.Builder
+	b.WriteString("{\n")
+	writeSection(&b, "repositories", index.Repositories)
+	b.WriteString(",\n")
+	writeSection(&b, "names", index.Names)
+	b.WriteString("\n}\n")
+	return os.WriteFile(path, []byte(b.String()), 0644)
+}
+
+func writeSection(b *strings.Builder, name string, entries map[string]string) {
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	fmt.Fprintf(b, "  %q: {", name)
+	for i, key := range keys {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		k, _ := json.Marshal(key)
+		v, _ := json.Marshal(entries[key])
+		fmt.Fprintf(b, "\n    %s: %s", k, v)
+	}
+	b.WriteString("\n  }")
+}
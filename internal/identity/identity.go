@@ -0,0 +1,299 @@
+package identity
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/lkpsg/ccscanner/pkg/models"
+)
+
+// dataFS 内置的包标识数据
+// packages.json 为人工维护的规范包及其在各生态中的别名,
+// repositories.json 由 gen_aliases.go 根据 ccscanner/data/submods.json 生成
+//
+//go:generate go run gen_aliases.go ../../ccscanner/data/submods.json
+//go:embed data/*.json
+var dataFS embed.FS
+
+// Package 规范包定义
+type Package struct {
+	ID         string   `json:"id"`         // 规范ID
+	Repository string   `json:"repository"` // 上游仓库(owner/repo)
+	Aliases    []string `json:"aliases"`    // 别名(find_package名、链接名、包管理器包名等)
+}
+
+// repositoryIndex repositories.json 的结构
+type repositoryIndex struct {
+	Repositories map[string]string `json:"repositories"` // owner/repo -> 规范ID(已知库的fork)
+	Names        map[string]string `json:"names"`        // 仓库名 -> owner/repo(仅收录唯一的仓库名)
+}
+
+// Database 包标识数据库, 负责把各种来源的依赖名映射到同一个规范ID
+type Database struct {
+	mu           sync.RWMutex
+	aliases      map[string]string // 规范化别名 -> 规范ID
+	repositories map[string]string // owner/repo -> 规范ID
+	names        map[string]string // 仓库名 -> owner/repo
+}
+
+var (
+	defaultDB   *Database
+	defaultOnce sync.Once
+)
+
+// NewDatabase 创建空的包标识数据库
+func NewDatabase() *Database {
+	return &Database{
+		aliases:      make(map[string]string),
+		repositories: make(map[string]string),
+		names:        make(map[string]string),
+	}
+}
+
+// Default 返回加载了内置数据的包标识数据库
+func Default() *Database {
+	defaultOnce.Do(func() {
+		defaultDB = NewDatabase()
+		if err := defaultDB.loadEmbedded(); err != nil {
+			panic(fmt.Sprintf("identity: invalid embedded data: %v", err))
+		}
+	})
+	return defaultDB
+}
+
+// loadEmbedded 加载内置数据文件
+func (db *Database) loadEmbedded() error {
+	data, err := dataFS.ReadFile("data/packages.json")
+	if err != nil {
+		return err
+	}
+	if err := db.loadPackages(data); err != nil {
+		return err
+	}
+
+	data, err = dataFS.ReadFile("data/repositories.json")
+	if err != nil {
+		return err
+	}
+	var index repositoryIndex
+	if err := json.Unmarshal(data, &index); err != nil {
+		return err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	for repo, id := range index.Repositories {
+		db.repositories[normalize(repo)] = id
+	}
+	for name, repo := range index.Names {
+		db.names[normalize(name)] = normalize(repo)
+	}
+	return nil
+}
+
+// Load 从文件加载额外的包定义, 格式与内置的 packages.json 相同
+// 同名别名会覆盖已有映射
+func (db *Database) Load(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("读取包标识数据失败: %v", err)
+	}
+	if err := db.loadPackages(data); err != nil {
+		return fmt.Errorf("解析包标识数据失败: %v", err)
+	}
+	return nil
+}
+
+// loadPackages 解析包定义列表
+func (db *Database) loadPackages(data []byte) error {
+	var pkgs []Package
+	if err := json.Unmarshal(data, &pkgs); err != nil {
+		return err
+	}
+	for _, pkg := range pkgs {
+		if pkg.ID == "" {
+			return fmt.Errorf("package without id")
+		}
+		db.AddPackage(pkg)
+	}
+	return nil
+}
+
+// AddPackage 注册规范包及其别名
+func (db *Database) AddPackage(pkg Package) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	db.aliases[normalize(pkg.ID)] = pkg.ID
+	for _, alias := range pkg.Aliases {
+		db.aliases[normalize(alias)] = pkg.ID
+	}
+	if pkg.Repository != "" {
+		db.repositories[normalize(pkg.Repository)] = pkg.ID
+	}
+}
+
+// CanonicalID 返回名称或仓库地址对应的规范ID, 未知时返回空字符串
+func (db *Database) CanonicalID(name string) string {
+	key := normalize(name)
+	if key == "" {
+		return ""
+	}
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if owner, repo, ok := strings.Cut(key, "/"); ok && owner != "" && !strings.Contains(repo, "/") {
+		if id, ok := db.repositories[key]; ok {
+			return id
+		}
+		// 已知库的fork(如 luvit/zlib)归并到原库, 过短的别名(如 c、z)容易误判
+		if id, ok := db.aliases[repo]; ok && len(repo) > 2 {
+			return id
+		}
+		if db.names[repo] == key {
+			return key
+		}
+		return ""
+	}
+
+	if id, ok := db.aliases[key]; ok {
+		return id
+	}
+	if repo, ok := db.names[key]; ok {
+		return repo
+	}
+	return ""
+}
+
+// Resolve 计算依赖的规范ID
+// 依次尝试仓库地址、名称和名称的最后一段, 都未命中时使用小写名称。
+// npm、PyPI等语言生态的包名与C/C++库名无关(如npm的crypto不是OpenSSL), 不查找别名,
+// 规范ID为带生态前缀的小写名称, 如 npm:crypto
+func (db *Database) Resolve(dep *models.Dependency) string {
+	if dep.CanonicalID != "" {
+		return dep.CanonicalID
+	}
+	ecosystem := Ecosystem(dep)
+	if ecosystem == "" {
+		if repo := repositoryPath(dep.Repository); repo != "" {
+			if id := db.CanonicalID(repo); id != "" {
+				return id
+			}
+		}
+	}
+	return db.ResolveName(dep.Name, ecosystem)
+}
+
+// ResolveName 计算生态中名称的规范ID, ecosystem为空表示C/C++构建系统中的名称
+// 用于只知道名称的子依赖, 与 Resolve 的结果一致
+func (db *Database) ResolveName(name, ecosystem string) string {
+	lower := strings.ToLower(strings.TrimSpace(name))
+	if ecosystem != "" {
+		return ecosystem + ":" + lower
+	}
+	if id := db.CanonicalID(name); id != "" {
+		return id
+	}
+	// third_party/zlib 这样的路径取最后一段, @scope/name 形式的包名不拆分
+	if !strings.HasPrefix(lower, "@") {
+		if base := path.Base(normalize(name)); base != "." && base != "/" {
+			if id := db.CanonicalID(base); id != "" {
+				return id
+			}
+		}
+	}
+	return lower
+}
+
+// foreignEcosystems 有独立命名空间的语言生态(purl类型), 其中的包不按C/C++库名归并
+var foreignEcosystems = map[string]bool{
+	"npm":      true,
+	"pypi":     true,
+	"cargo":    true,
+	"maven":    true,
+	"nuget":    true,
+	"composer": true,
+	"gem":      true,
+	"golang":   true,
+}
+
+// ecosystemFiles 语言生态的清单和锁定文件, 依赖没有purl时按配置文件类型判断生态
+var ecosystemFiles = map[string]string{
+	"package.json":      "npm",
+	"package-lock.json": "npm",
+	"yarn.lock":         "npm",
+	"requirements.txt":  "pypi",
+	"pyproject.toml":    "pypi",
+	"setup.py":          "pypi",
+	"Pipfile":           "pypi",
+	"Cargo.toml":        "cargo",
+	"Cargo.lock":        "cargo",
+	"pom.xml":           "maven",
+	"go.mod":            "golang",
+	"Gemfile":           "gem",
+}
+
+// Ecosystem 返回依赖所属的语言生态(npm、pypi、cargo等), C/C++构建系统、系统包管理器中的依赖返回空字符串
+func Ecosystem(dep *models.Dependency) string {
+	if rest, ok := strings.CutPrefix(dep.PURL, "pkg:"); ok {
+		typ, _, _ := strings.Cut(rest, "/")
+		if typ = strings.ToLower(typ); foreignEcosystems[typ] {
+			return typ
+		}
+		return ""
+	}
+	return ecosystemFiles[dep.ConfigFileType]
+}
+
+// Canonicalize 为依赖列表填充规范ID, 原始名称保持不变
+func (db *Database) Canonicalize(deps []models.Dependency) {
+	for i := range deps {
+		deps[i].CanonicalID = db.Resolve(&deps[i])
+	}
+}
+
+// repositoryPath 从仓库地址中提取 owner/repo
+func repositoryPath(repository string) string {
+	key := normalize(repository)
+	parts := strings.Split(key, "/")
+	if len(parts) < 2 {
+		return ""
+	}
+	return parts[len(parts)-2] + "/" + parts[len(parts)-1]
+}
+
+// normalize 规范化别名: 小写, 去掉URL协议、代码托管域名和.git后缀
+func normalize(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if i := strings.Index(name, "://"); i >= 0 {
+		name = name[i+3:]
+	}
+	name = strings.TrimPrefix(name, "git@")
+	for _, host := range []string{"github.com", "gitlab.com", "bitbucket.org"} {
+		if strings.HasPrefix(name, host+"/") || strings.HasPrefix(name, host+":") {
+			name = name[len(host)+1:]
+			break
+		}
+	}
+	name = strings.TrimSuffix(strings.Trim(name, "/"), ".git")
+	return name
+}
+
+/*
+使用示例:
+
+db := identity.Default()
+fmt.Println(db.CanonicalID("ZLIB"))       // zlib
+fmt.Println(db.CanonicalID("zlib1g-dev")) // zlib
+fmt.Println(db.CanonicalID("https://github.com/madler/zlib.git")) // zlib
+
+deps := []models.Dependency{*models.NewDependency("z")}
+db.Canonicalize(deps)
+fmt.Println(deps[0].Name, deps[0].CanonicalID) // z zlib
+*/
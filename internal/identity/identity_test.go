@@ -0,0 +1,99 @@
+package identity
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lkpsg/ccscanner/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDatabase_CanonicalID(t *testing.T) {
+	db := Default()
+	tests := map[string]string{
+		"ZLIB":                               "zlib",
+		"z":                                  "zlib",
+		"zlib":                               "zlib",
+		"zlib1g-dev":                         "zlib",
+		"madler/zlib":                        "zlib",
+		"https://github.com/madler/zlib.git": "zlib",
+		"git@github.com:madler/zlib.git":     "zlib",
+		"luvit/zlib":                         "zlib",
+		"libcurl":                            "curl",
+		"OpenSSL":                            "openssl",
+		"GTest":                              "googletest",
+		"simonlarsen/mmlgb":                  "simonlarsen/mmlgb",
+		"unknown-library-name":               "",
+	}
+	for name, want := range tests {
+		assert.Equal(t, want, db.CanonicalID(name), name)
+	}
+}
+
+func TestDatabase_Canonicalize(t *testing.T) {
+	deps := []models.Dependency{
+		{Name: "ZLIB"},
+		{Name: "third_party/zlib"},
+		{Name: "zlib-fork", Repository: "https://github.com/madler/zlib"},
+		{Name: "MyLib"},
+		{Name: "pinned", CanonicalID: "custom"},
+	}
+	Default().Canonicalize(deps)
+
+	assert.Equal(t, "zlib", deps[0].CanonicalID)
+	assert.Equal(t, "ZLIB", deps[0].Name)
+	assert.Equal(t, "zlib", deps[1].CanonicalID)
+	assert.Equal(t, "zlib", deps[2].CanonicalID)
+	assert.Equal(t, "mylib", deps[3].CanonicalID)
+	assert.Equal(t, "custom", deps[4].CanonicalID)
+
+	result := models.NewDependencyResult("demo", "/tmp/demo")
+	for _, dep := range deps {
+		result.AddDependency(dep)
+	}
+	assert.Equal(t, 5, result.TotalDeps)
+	assert.Equal(t, 3, result.UniqueDeps)
+}
+
+func TestDatabase_Resolve_Ecosystems(t *testing.T) {
+	db := Default()
+	deps := []models.Dependency{
+		{Name: "crypto", PURL: "pkg:npm/crypto@1.0.1"},
+		{Name: "async", PURL: "pkg:npm/async@3.2.4"},
+		{Name: "@types/async", PURL: "pkg:npm/%40types/async@3.2.0"},
+		{Name: "yaml", ConfigFileType: "yarn.lock"},
+		{Name: "event", PURL: "pkg:pypi/event@1.0"},
+		{Name: "log", PURL: "pkg:cargo/log@0.4.20"},
+		{Name: "zlib", PURL: "pkg:npm/zlib@1.0.5", Repository: "https://github.com/madler/zlib"},
+	}
+	db.Canonicalize(deps)
+
+	// 语言生态中与C库同名的包不归并到C库
+	want := []string{"npm:crypto", "npm:async", "npm:@types/async", "npm:yaml", "pypi:event", "cargo:log", "npm:zlib"}
+	for i, dep := range deps {
+		assert.Equal(t, want[i], dep.CanonicalID, dep.Name)
+	}
+
+	// C/C++构建系统和系统包管理器中的名称仍解析别名
+	assert.Equal(t, "zlib", db.Resolve(&models.Dependency{Name: "ZLIB", ConfigFileType: "CMakeLists.txt"}))
+	assert.Equal(t, "zlib", db.Resolve(&models.Dependency{Name: "zlib1g-dev", PURL: "pkg:deb/debian/zlib1g-dev"}))
+	assert.Equal(t, "@types/async", db.ResolveName("@types/async", ""))
+	assert.Equal(t, "npm:left-pad", db.ResolveName("Left-Pad", "npm"))
+}
+
+func TestDatabase_Load(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "packages.json")
+	require.NoError(t, os.WriteFile(file, []byte(`[
+		{"id": "acme-core", "repository": "acme/core", "aliases": ["AcmeCore", "acme"]}
+	]`), 0644))
+
+	db := NewDatabase()
+	require.NoError(t, db.Load(file))
+	assert.Equal(t, "acme-core", db.CanonicalID("AcmeCore"))
+	assert.Equal(t, "acme-core", db.CanonicalID("https://github.com/acme/core"))
+
+	require.NoError(t, os.WriteFile(file, []byte(`[{"aliases": ["x"]}]`), 0644))
+	assert.Error(t, db.Load(file))
+}
@@ -0,0 +1,249 @@
+package ignore
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Pattern 一条gitignore语法的匹配规则
+type Pattern struct {
+	Source string // 规则来源(如 src/.gitignore、.ccscannerignore、config)
+	Line   int    // 规则所在行, 非文件来源为0
+	Text   string // 原始规则
+
+	base    string // 规则生效的目录(相对扫描根目录, 以/分隔, 根目录为空)
+	negate  bool   // 以!开头的规则, 重新包含之前被忽略的路径
+	dirOnly bool   // 以/结尾的规则, 只匹配目录
+	re      *regexp.Regexp
+}
+
+// ParsePattern 解析一条规则, base为规则所在目录(相对扫描根目录), 空行和注释返回nil
+func ParsePattern(text, base string) (*Pattern, error) {
+	p := &Pattern{Text: text, base: strings.Trim(base, "/")}
+
+	// 去掉未转义的行尾空格
+	line := strings.TrimRight(text, "\r")
+	for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, `\ `) {
+		line = line[:len(line)-1]
+	}
+	if line == "" || strings.HasPrefix(line, "#") {
+		return nil, nil
+	}
+
+	if strings.HasPrefix(line, "!") {
+		p.negate = true
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		p.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return nil, nil
+	}
+
+	// 规则中间或开头含有/时相对规则所在目录匹配, 否则匹配任意层级的名称
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+
+	expr, err := translate(line)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %v", text, err)
+	}
+	if !anchored {
+		expr = "(?:.*/)?" + expr
+	}
+	p.re, err = regexp.Compile("^" + expr + "$")
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %v", text, err)
+	}
+	return p, nil
+}
+
+// Negate 是否为重新包含路径的规则
+func (p *Pattern) Negate() bool {
+	return p.negate
+}
+
+// Match 判断路径是否匹配规则, rel为相对扫描根目录、以/分隔的路径
+func (p *Pattern) Match(rel string, isDir bool) bool {
+	if p.dirOnly && !isDir {
+		return false
+	}
+	if p.base != "" {
+		if !strings.HasPrefix(rel, p.base+"/") {
+			return false
+		}
+		rel = rel[len(p.base)+1:]
+	}
+	return p.re.MatchString(rel)
+}
+
+// String 返回规则及其来源, 用于说明路径被跳过的原因
+func (p *Pattern) String() string {
+	switch {
+	case p.Source == "":
+		return fmt.Sprintf("%q", p.Text)
+	case p.Line == 0:
+		return fmt.Sprintf("%q (%s)", p.Text, p.Source)
+	}
+	return fmt.Sprintf("%q (%s:%d)", p.Text, p.Source, p.Line)
+}
+
+// translate 将通配符转换为正则表达式
+func translate(glob string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch c {
+		case '*':
+			if strings.HasPrefix(glob[i:], "**") && (i == 0 || glob[i-1] == '/') {
+				rest := glob[i+2:]
+				switch {
+				case rest == "":
+					b.WriteString(".*")
+					i++
+					continue
+				case strings.HasPrefix(rest, "/"):
+					b.WriteString("(?:.*/)?")
+					i += 2
+					continue
+				}
+			}
+			b.WriteString("[^/]*")
+		case '?':
+			b.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				return "", fmt.Errorf("unterminated character class")
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		case '\\':
+			if i+1 < len(glob) {
+				i++
+				b.WriteString(regexp.QuoteMeta(string(glob[i])))
+			}
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return b.String(), nil
+}
+
+// ParseFile 读取gitignore语法的规则文件, base为文件所在目录(相对扫描根目录)
+// 文件不存在时返回空列表
+func ParseFile(file, base, source string) ([]*Pattern, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+	return parse(f, base, source)
+}
+
+// ParseFS 从fsys读取规则文件, 用于扫描压缩包等虚拟文件系统, 文件不存在时返回空列表
+func ParseFS(fsys fs.FS, name, base, source string) ([]*Pattern, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+	return parse(f, base, source)
+}
+
+// parse 逐行解析规则, 跳过空行和注释
+func parse(r io.Reader, base, source string) ([]*Pattern, error) {
+	var patterns []*Pattern
+	scanner := bufio.NewScanner(r)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		p, err := ParsePattern(scanner.Text(), base)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", source, lineNum, err)
+		}
+		if p == nil {
+			continue
+		}
+		p.Source = source
+		p.Line = lineNum
+		patterns = append(patterns, p)
+	}
+	return patterns, scanner.Err()
+}
+
+// Matcher 一组按顺序生效的规则, 与git一致, 后出现的规则优先
+type Matcher struct {
+	patterns []*Pattern
+}
+
+// NewMatcher 创建匹配器
+func NewMatcher(patterns ...*Pattern) *Matcher {
+	return &Matcher{patterns: patterns}
+}
+
+// Extend 返回追加了规则的新匹配器, 原匹配器不变(用于子目录继承上级目录的规则)
+func (m *Matcher) Extend(patterns ...*Pattern) *Matcher {
+	if len(patterns) == 0 {
+		return m
+	}
+	combined := make([]*Pattern, 0, len(m.patterns)+len(patterns))
+	combined = append(combined, m.patterns...)
+	combined = append(combined, patterns...)
+	return &Matcher{patterns: combined}
+}
+
+// Match 返回最后一条匹配路径的规则, 路径被忽略时ignored为true(匹配的是!规则时为false)
+func (m *Matcher) Match(rel string, isDir bool) (p *Pattern, ignored bool) {
+	for i := len(m.patterns) - 1; i >= 0; i-- {
+		if m.patterns[i].Match(rel, isDir) {
+			return m.patterns[i], !m.patterns[i].negate
+		}
+	}
+	return nil, false
+}
+
+// MatchAny 判断路径或其任一上级目录是否匹配任意一条规则(用于包含规则)
+func MatchAny(patterns []*Pattern, rel string) bool {
+	isDir := false
+	for p := rel; p != "." && p != "/" && p != ""; p, isDir = path.Dir(p), true {
+		for _, pattern := range patterns {
+			if pattern.Match(p, isDir) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+/*
+使用示例:
+
+patterns, err := ignore.ParseFile("src/.gitignore", "src", "src/.gitignore")
+if err != nil {
+	log.Fatal(err)
+}
+matcher := ignore.NewMatcher().Extend(patterns...)
+if p, ignored := matcher.Match("src/build/out.o", false); ignored {
+	fmt.Println("ignored by", p)
+}
+*/
@@ -0,0 +1,153 @@
+package ignore
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mustParse(t *testing.T, text, base string) *Pattern {
+	t.Helper()
+	p, err := ParsePattern(text, base)
+	require.NoError(t, err)
+	require.NotNil(t, p)
+	return p
+}
+
+func TestParsePattern(t *testing.T) {
+	for _, text := range []string{"", "   ", "# comment", "/"} {
+		p, err := ParsePattern(text, "")
+		assert.NoError(t, err)
+		assert.Nil(t, p, text)
+	}
+
+	_, err := ParsePattern("[abc", "")
+	assert.Error(t, err)
+
+	assert.True(t, mustParse(t, "!keep.txt", "").Negate())
+	assert.True(t, mustParse(t, `foo\ `, "").Match("foo ", false))
+	assert.True(t, mustParse(t, `\#notes`, "").Match("#notes", false))
+}
+
+func TestPattern_Match(t *testing.T) {
+	tests := []struct {
+		pattern string
+		base    string
+		path    string
+		isDir   bool
+		want    bool
+	}{
+		// 不含/的规则匹配任意层级
+		{"*.o", "", "main.o", false, true},
+		{"*.o", "", "src/lib/util.o", false, true},
+		{"*.o", "", "src/main.c", false, false},
+		// 以/结尾只匹配目录
+		{"build/", "", "build", true, true},
+		{"build/", "", "src/build", true, true},
+		{"build/", "", "build", false, false},
+		// 含/的规则相对规则所在目录
+		{"/build", "", "build", true, true},
+		{"/build", "", "src/build", true, false},
+		{"doc/*.md", "", "doc/a.md", false, true},
+		{"doc/*.md", "", "doc/sub/a.md", false, false},
+		// **
+		{"**/gen", "", "gen", true, true},
+		{"**/gen", "", "a/b/gen", true, true},
+		{"out/**", "", "out/a/b.txt", false, true},
+		{"a/**/b", "", "a/b", true, true},
+		{"a/**/b", "", "a/x/y/b", true, true},
+		// ? 和字符类
+		{"file?.txt", "", "file1.txt", false, true},
+		{"file?.txt", "", "file10.txt", false, false},
+		{"lib[0-9].a", "", "lib3.a", false, true},
+		{"lib[!0-9].a", "", "lib3.a", false, false},
+		// 子目录中的规则只作用于该目录
+		{"*.tmp", "src", "src/x.tmp", false, true},
+		{"*.tmp", "src", "x.tmp", false, false},
+		{"/gen", "src", "src/gen", true, true},
+		{"/gen", "src", "src/lib/gen", true, false},
+		{"cmake-build-*/", "", "cmake-build-debug", true, true},
+	}
+
+	for _, tt := range tests {
+		p := mustParse(t, tt.pattern, tt.base)
+		assert.Equal(t, tt.want, p.Match(tt.path, tt.isDir), "%s (base %q) vs %s", tt.pattern, tt.base, tt.path)
+	}
+}
+
+func TestMatcher(t *testing.T) {
+	root := NewMatcher(mustParse(t, "*.log", ""), mustParse(t, "!important.log", ""))
+
+	p, ignored := root.Match("debug.log", false)
+	assert.True(t, ignored)
+	assert.Equal(t, "*.log", p.Text)
+
+	p, ignored = root.Match("important.log", false)
+	assert.False(t, ignored)
+	assert.True(t, p.Negate())
+
+	p, ignored = root.Match("main.c", false)
+	assert.False(t, ignored)
+	assert.Nil(t, p)
+
+	// 子目录规则覆盖上级规则, 上级匹配器不受影响
+	sub := root.Extend(mustParse(t, "!trace.log", "src"))
+	_, ignored = sub.Match("src/trace.log", false)
+	assert.False(t, ignored)
+	_, ignored = root.Match("src/trace.log", false)
+	assert.True(t, ignored)
+
+	assert.Same(t, root, root.Extend())
+}
+
+func TestParseFile(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, ".gitignore")
+	require.NoError(t, os.WriteFile(file, []byte("# build output\nbuild/\n\n*.o\n!keep.o\n"), 0644))
+
+	patterns, err := ParseFile(file, "src", "src/.gitignore")
+	require.NoError(t, err)
+	require.Len(t, patterns, 3)
+	assert.Equal(t, 2, patterns[0].Line)
+	assert.Equal(t, `"build/" (src/.gitignore:2)`, patterns[0].String())
+	assert.True(t, patterns[2].Negate())
+	assert.True(t, patterns[1].Match("src/a.o", false))
+
+	patterns, err = ParseFile(filepath.Join(dir, "missing"), "", "missing")
+	assert.NoError(t, err)
+	assert.Nil(t, patterns)
+
+	require.NoError(t, os.WriteFile(file, []byte("ok\n[bad\n"), 0644))
+	_, err = ParseFile(file, "", ".gitignore")
+	assert.ErrorContains(t, err, ".gitignore:2")
+}
+
+func TestParseFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"src/.gitignore": &fstest.MapFile{Data: []byte("*.o\n!keep.o\n")},
+	}
+
+	patterns, err := ParseFS(fsys, "src/.gitignore", "src", "src/.gitignore")
+	require.NoError(t, err)
+	require.Len(t, patterns, 2)
+	assert.True(t, patterns[0].Match("src/a.o", false))
+	assert.False(t, patterns[0].Match("a.o", false))
+
+	patterns, err = ParseFS(fsys, ".gitignore", "", ".gitignore")
+	assert.NoError(t, err)
+	assert.Nil(t, patterns)
+}
+
+func TestMatchAny(t *testing.T) {
+	patterns := []*Pattern{mustParse(t, "src/", ""), mustParse(t, "*.cmake", "")}
+
+	assert.True(t, MatchAny(patterns, "src/lib/CMakeLists.txt"))
+	assert.True(t, MatchAny(patterns, "cmake/FindFoo.cmake"))
+	assert.False(t, MatchAny(patterns, "tools/CMakeLists.txt"))
+	// 文件名与目录规则同名时不匹配
+	assert.False(t, MatchAny(patterns, "src"))
+}
@@ -0,0 +1,390 @@
+package merger
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/lkpsg/ccscanner/pkg/models"
+)
+
+// SourceKind 检测来源类别, 数值越大越可信
+type SourceKind int
+
+const (
+	SourceHeuristic   SourceKind = iota + 1 // 启发式推断(#include、指纹、二进制分析)
+	SourceBuildScript                       // 构建脚本(CMakeLists.txt、Makefile等)
+	SourceManifest                          // 包管理清单(vcpkg.json、conanfile等)
+	SourceLockfile                          // 锁定文件(flake.lock、CMakeCache.txt等)
+)
+
+// String 返回来源类别名称
+func (k SourceKind) String() string {
+	switch k {
+	case SourceLockfile:
+		return "lockfile"
+	case SourceManifest:
+		return "manifest"
+	case SourceBuildScript:
+		return "build-script"
+	case SourceHeuristic:
+		return "heuristic"
+	}
+	return "unknown"
+}
+
+// sourceKinds 配置文件类型对应的来源类别
+var sourceKinds = map[string]SourceKind{
+	// 锁定文件: 记录了实际解析/固定的版本
+	"flake.lock":        SourceLockfile,
+	"CMakeCache.txt":    SourceLockfile,
+	"conaninfo.txt":     SourceLockfile,
+	"conan.lock":        SourceLockfile,
+	".gitmodules":       SourceLockfile,
+	"Cargo.lock":        SourceLockfile,
+	"package-lock.json": SourceLockfile,
+	"yarn.lock":         SourceLockfile,
+
+	// 包管理清单
+	"vcpkg.json":    SourceManifest,
+	"conanfile.txt": SourceManifest,
+	"conanfile.py":  SourceManifest,
+	"control":       SourceManifest,
+	"PKGBUILD":      SourceManifest,
+	"APKBUILD":      SourceManifest,
+	".pc":           SourceManifest,
+	"default.nix":   SourceManifest,
+	"flake.nix":     SourceManifest,
+	"shell.nix":     SourceManifest,
+	"package.json":  SourceManifest,
+	"Cargo.toml":    SourceManifest,
+	"pom.xml":       SourceManifest,
+
+	// 构建脚本
+	"CMakeLists.txt":        SourceBuildScript,
+	"Makefile":              SourceBuildScript,
+	"meson.build":           SourceBuildScript,
+	"configure.ac":          SourceBuildScript,
+	"compile_commands.json": SourceBuildScript,
+
+	// 启发式检测
+	"directory": SourceHeuristic,
+	"header":    SourceHeuristic,
+	"source":    SourceHeuristic,
+	"elf":       SourceHeuristic,
+	"binary":    SourceHeuristic,
+}
+
+// ClassifySource 根据配置文件类型判断依赖的来源类别, 未知类型按构建脚本处理
+func ClassifySource(dep *models.Dependency) SourceKind {
+	if kind, ok := sourceKinds[dep.ConfigFileType]; ok {
+		return kind
+	}
+	return SourceBuildScript
+}
+
+// Merge 按身份标识和版本合并多个提取器的重复发现
+// 同一依赖的不同版本(如内置源码中的zlib 1.2.11与vcpkg安装的zlib 1.3)分别保留, 以便逐一检测漏洞,
+// 未给出版本的发现并入来源优先级最高的版本。
+// 冲突字段按来源优先级(锁定文件 > 清单 > 构建脚本 > 启发式)取值,
+// 版本约束、构建标志、元数据等取并集, 并保留每个来源的证据
+func Merge(deps []models.Dependency) []models.Dependency {
+	groups := make(map[string][]models.Dependency)
+	for _, dep := range deps {
+		key := dep.Key()
+		groups[key] = append(groups[key], dep)
+	}
+
+	keys := make([]string, 0, len(groups))
+	for key := range groups {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	merged := make([]models.Dependency, 0, len(keys))
+	for _, key := range keys {
+		for _, group := range splitVersions(groups[key]) {
+			merged = append(merged, mergeGroup(group))
+		}
+	}
+	return merged
+}
+
+// splitVersions 按版本拆分同一依赖的发现, 各版本按其最可信来源的优先级排列
+// 未给出版本的发现并入第一个版本, 所有发现都没有版本时不拆分
+func splitVersions(group []models.Dependency) [][]models.Dependency {
+	sortBySource(group)
+
+	var (
+		versions    []string
+		unversioned []models.Dependency
+	)
+	byVersion := make(map[string][]models.Dependency)
+	for _, dep := range group {
+		version := resolvedVersion(dep.Version)
+		if version == "" {
+			unversioned = append(unversioned, dep)
+			continue
+		}
+		if _, ok := byVersion[version]; !ok {
+			versions = append(versions, version)
+		}
+		byVersion[version] = append(byVersion[version], dep)
+	}
+	if len(versions) == 0 {
+		return [][]models.Dependency{unversioned}
+	}
+
+	byVersion[versions[0]] = append(byVersion[versions[0]], unversioned...)
+	split := make([][]models.Dependency, 0, len(versions))
+	for _, version := range versions {
+		split = append(split, byVersion[version])
+	}
+	return split
+}
+
+// resolvedVersion 返回用于分组的版本号, v1.3 与 1.3 视为同一版本
+func resolvedVersion(version string) string {
+	return strings.TrimPrefix(strings.TrimSpace(version), "v")
+}
+
+// sortBySource 按来源优先级排序, 同级保持稳定的文件顺序
+func sortBySource(group []models.Dependency) {
+	sort.SliceStable(group, func(i, j int) bool {
+		ki, kj := ClassifySource(&group[i]), ClassifySource(&group[j])
+		if ki != kj {
+			return ki > kj
+		}
+		return group[i].ConfigFile < group[j].ConfigFile
+	})
+}
+
+// mergeGroup 合并同一依赖同一版本的多条发现
+func mergeGroup(group []models.Dependency) models.Dependency {
+	sortBySource(group)
+
+	// 列表字段复制后再合并, 避免修改原始发现
+	result := group[0]
+	result.Evidence = nil
+	result.Metadata = copyMetadata(group[0].Metadata)
+	result.Languages = union(nil, result.Languages)
+	result.CPEs = union(nil, result.CPEs)
+	result.Dependencies = union(nil, result.Dependencies)
+	result.DevDependencies = union(nil, result.DevDependencies)
+	result.Conflicts = union(nil, result.Conflicts)
+	result.BuildFlags = union(nil, result.BuildFlags)
+	result.Constraints = unionConstraints(nil, result.Constraints)
+
+	for i := range group {
+		dep := &group[i]
+		result.Evidence = append(result.Evidence, evidenceOf(dep)...)
+		if i == 0 {
+			continue
+		}
+
+		fillString(&result.Version, dep.Version)
+		fillString(&result.CanonicalID, dep.CanonicalID)
+		fillString(&result.PURL, dep.PURL)
+		fillString(&result.Description, dep.Description)
+		fillString(&result.Homepage, dep.Homepage)
+		fillString(&result.License, dep.License)
+		fillString(&result.Repository, dep.Repository)
+		fillString(&result.Branch, dep.Branch)
+		fillString(&result.Commit, dep.Commit)
+		fillString(&result.Checksum, dep.Checksum)
+		fillString(&result.Parent, dep.Parent)
+		if result.Drift == nil {
+			result.Drift = dep.Drift
+		}
+
+		result.Languages = union(result.Languages, dep.Languages)
+		result.CPEs = union(result.CPEs, dep.CPEs)
+		result.Dependencies = union(result.Dependencies, dep.Dependencies)
+		result.DevDependencies = union(result.DevDependencies, dep.DevDependencies)
+		result.Conflicts = union(result.Conflicts, dep.Conflicts)
+		result.BuildFlags = union(result.BuildFlags, dep.BuildFlags)
+		result.Constraints = unionConstraints(result.Constraints, dep.Constraints)
+
+		// 任一来源声明为必需即必需, 所有来源都可选才可选
+		result.Required = result.Required || dep.Required
+		result.Optional = result.Optional && dep.Optional
+
+		for key, value := range dep.Metadata {
+			if _, exists := result.Metadata[key]; !exists {
+				if result.Metadata == nil {
+					result.Metadata = make(map[string]interface{})
+				}
+				result.Metadata[key] = value
+			}
+		}
+	}
+
+	result.Confidence, result.ConfidenceReason = combineConfidence(group)
+
+	// 任一来源为项目直接声明即为直接依赖, 所有来源都是锁定文件解析出的间接依赖时才是传递依赖
+	result.Relationship = models.RelationshipTransitive
+	for i := range group {
+		if group[i].IsDirect() {
+			result.Relationship = models.RelationshipDirect
+			break
+		}
+	}
+
+	// 各来源的依赖范围不一致时取影响最大的范围, 如同时作为运行时依赖和测试依赖时按运行时依赖处理
+	result.Scope = widestScope(group)
+
+	return result
+}
+
+// evidenceOf 返回一条发现的证据, 已合并过的依赖沿用原有证据
+func evidenceOf(dep *models.Dependency) []models.Evidence {
+	if len(dep.Evidence) > 0 {
+		return dep.Evidence
+	}
+	return []models.Evidence{{
+		Extractor: dep.DetectedBy,
+		File:      dep.ConfigFile,
+		FileType:  dep.ConfigFileType,
+		Kind:      ClassifySource(dep).String(),
+		Version:   dep.Version,
+
+		Confidence: dep.Confidence,
+		Provenance: dep.Provenance,
+	}}
+}
+
+// maxConfidence 多个来源相互印证后的置信度上限
+const maxConfidence = 0.99
+
+// combineConfidence 合并多个来源的置信度
+// 同一提取器的多次发现只取最高值, 不同提取器相互印证时按 1-∏(1-c) 提高置信度
+func combineConfidence(group []models.Dependency) (float64, string) {
+	best := make(map[string]float64)
+	top, reason := 0.0, ""
+	for i := range group {
+		dep := &group[i]
+		if dep.Confidence <= 0 {
+			continue
+		}
+		if dep.Confidence > best[dep.DetectedBy] {
+			best[dep.DetectedBy] = dep.Confidence
+		}
+		if dep.Confidence > top {
+			top, reason = dep.Confidence, dep.ConfidenceReason
+		}
+	}
+	if len(best) <= 1 {
+		return top, reason
+	}
+
+	miss := 1.0
+	for _, c := range best {
+		miss *= 1 - c
+	}
+	score := math.Max(top, math.Min(1-miss, maxConfidence))
+	return math.Round(score*100) / 100, fmt.Sprintf("%s; corroborated by %d extractors", reason, len(best))
+}
+
+// scopeOrder 依赖范围按影响从大到小排列
+var scopeOrder = []string{
+	models.ScopeRuntime,
+	models.ScopeOptional,
+	models.ScopeBuild,
+	models.ScopeTool,
+	models.ScopeDev,
+	models.ScopeTest,
+}
+
+// widestScope 返回各来源中影响最大的依赖范围, 都未标注时返回空字符串
+func widestScope(group []models.Dependency) string {
+	widest := len(scopeOrder)
+	for i := range group {
+		if group[i].Scope == "" {
+			continue
+		}
+		scope := group[i].EffectiveScope()
+		for rank := 0; rank < widest; rank++ {
+			if scopeOrder[rank] == scope {
+				widest = rank
+				break
+			}
+		}
+	}
+	if widest == len(scopeOrder) {
+		return ""
+	}
+	return scopeOrder[widest]
+}
+
+// copyMetadata 复制元数据
+func copyMetadata(metadata map[string]interface{}) map[string]interface{} {
+	if metadata == nil {
+		return nil
+	}
+	copied := make(map[string]interface{}, len(metadata))
+	for key, value := range metadata {
+		copied[key] = value
+	}
+	return copied
+}
+
+// fillString 目标为空时使用低优先级来源的值
+func fillString(dst *string, value string) {
+	if *dst == "" {
+		*dst = value
+	}
+}
+
+// union 合并字符串列表并去重, 保持原有顺序
+func union(a, b []string) []string {
+	for _, item := range b {
+		if !contains(a, item) {
+			a = append(a, item)
+		}
+	}
+	return a
+}
+
+// unionConstraints 合并版本约束并去重
+func unionConstraints(a, b []models.VersionConstrain) []models.VersionConstrain {
+	for _, c := range b {
+		found := false
+		for _, existing := range a {
+			if existing == c {
+				found = true
+				break
+			}
+		}
+		if !found {
+			a = append(a, c)
+		}
+	}
+	return a
+}
+
+// contains 判断列表是否包含指定元素
+func contains(items []string, item string) bool {
+	for _, existing := range items {
+		if existing == item {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+使用示例:
+
+cmake := *models.NewDependency("OpenSSL")
+cmake.CanonicalID = "openssl"
+cmake.ConfigFileType = "CMakeLists.txt"
+
+vcpkg := *models.NewDependency("openssl")
+vcpkg.CanonicalID = "openssl"
+vcpkg.Version = "3.0.8"
+vcpkg.ConfigFileType = "vcpkg.json"
+
+deps := merger.Merge([]models.Dependency{cmake, vcpkg})
+fmt.Println(len(deps), deps[0].Name, deps[0].Version, len(deps[0].Evidence)) // 1 openssl 3.0.8 2
+*/
@@ -0,0 +1,181 @@
+package merger
+
+import (
+	"testing"
+
+	"github.com/lkpsg/ccscanner/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMerge(t *testing.T) {
+	deps := []models.Dependency{
+		{
+			Name:             "OpenSSL",
+			CanonicalID:      "openssl",
+			ConfigFile:       "CMakeLists.txt",
+			ConfigFileType:   "CMakeLists.txt",
+			DetectedBy:       "cmake",
+			Required:         true,
+			Confidence:       0.8,
+			ConfidenceReason: "found by CMake find_package()",
+			Constraints:      []models.VersionConstrain{{Operator: ">=", Version: "1.1.1"}},
+			Metadata:         map[string]interface{}{"components": []string{"SSL", "Crypto"}},
+		},
+		{
+			Name:             "openssl",
+			CanonicalID:      "openssl",
+			Version:          "3.0.8",
+			ConfigFile:       "vcpkg.json",
+			ConfigFileType:   "vcpkg.json",
+			DetectedBy:       "vcpkg",
+			Optional:         true,
+			Confidence:       0.9,
+			ConfidenceReason: "declared in vcpkg manifest",
+			Constraints:      []models.VersionConstrain{{Operator: ">=", Version: "3.0.0"}},
+			Metadata:         map[string]interface{}{"features": []string{"tools"}},
+		},
+		{
+			Name:           "libssl",
+			CanonicalID:    "openssl",
+			Version:        "3.0.8",
+			ConfigFile:     "lib/pkgconfig/libssl.pc",
+			ConfigFileType: ".pc",
+			DetectedBy:     "pkgconfig",
+			Confidence:     0.85,
+			Description:    "Secure Sockets Layer and cryptography libraries",
+		},
+		{
+			Name:           "zlib",
+			CanonicalID:    "zlib",
+			ConfigFile:     "CMakeLists.txt",
+			ConfigFileType: "CMakeLists.txt",
+			DetectedBy:     "cmake",
+			Confidence:     0.8,
+		},
+	}
+
+	merged := Merge(deps)
+	require.Len(t, merged, 2)
+
+	openssl := merged[0]
+	assert.Equal(t, "openssl", openssl.Key())
+	// 清单优先于构建脚本, 同级按文件路径排序
+	assert.Equal(t, "libssl", openssl.Name)
+	assert.Equal(t, "3.0.8", openssl.Version)
+	assert.Equal(t, "Secure Sockets Layer and cryptography libraries", openssl.Description)
+	assert.True(t, openssl.Required)
+	assert.False(t, openssl.Optional)
+	assert.ElementsMatch(t, []models.VersionConstrain{
+		{Operator: ">=", Version: "1.1.1"},
+		{Operator: ">=", Version: "3.0.0"},
+	}, openssl.Constraints)
+	assert.NotContains(t, openssl.Metadata, "versions")
+	assert.Contains(t, openssl.Metadata, "components")
+	assert.Contains(t, openssl.Metadata, "features")
+
+	require.Len(t, openssl.Evidence, 3)
+	assert.Equal(t, "pkgconfig", openssl.Evidence[0].Extractor)
+	assert.Equal(t, "manifest", openssl.Evidence[0].Kind)
+	assert.Equal(t, "vcpkg", openssl.Evidence[1].Extractor)
+	assert.Equal(t, "cmake", openssl.Evidence[2].Extractor)
+	assert.Equal(t, "build-script", openssl.Evidence[2].Kind)
+	assert.Empty(t, openssl.Evidence[2].Version)
+
+	// 三个提取器相互印证: 1 - 0.2*0.1*0.15 = 0.997, 上限0.99
+	assert.Equal(t, 0.99, openssl.Confidence)
+	assert.Equal(t, "declared in vcpkg manifest; corroborated by 3 extractors", openssl.ConfidenceReason)
+	assert.Equal(t, 0.9, openssl.Evidence[1].Confidence)
+
+	zlib := merged[1]
+	assert.Equal(t, 0.8, zlib.Confidence)
+	assert.Equal(t, "zlib", zlib.Name)
+	assert.Len(t, zlib.Evidence, 1)
+	assert.NotContains(t, zlib.Metadata, "versions")
+
+	// 原始发现不受影响
+	assert.Len(t, deps[0].Constraints, 1)
+	assert.NotContains(t, deps[0].Metadata, "features")
+}
+
+func TestMerge_SourcePrecedence(t *testing.T) {
+	deps := []models.Dependency{
+		{Name: "zlib", ConfigFileType: "header", DetectedBy: "include"},
+		{Name: "zlib", ConfigFileType: "CMakeLists.txt", DetectedBy: "cmake", Homepage: "https://github.com/madler/zlib"},
+		{Name: "zlib", Version: "1.2.13", ConfigFileType: "CMakeCache.txt", DetectedBy: "cmake_cache"},
+		{Name: "zlib", Version: "v1.2.13", ConfigFileType: "vcpkg.json", DetectedBy: "vcpkg", Homepage: "https://zlib.net"},
+	}
+
+	merged := Merge(deps)
+	require.Len(t, merged, 1)
+	assert.Equal(t, "1.2.13", merged[0].Version)
+	assert.Equal(t, "https://zlib.net", merged[0].Homepage)
+
+	var kinds []string
+	for _, evidence := range merged[0].Evidence {
+		kinds = append(kinds, evidence.Kind)
+	}
+	assert.Equal(t, []string{"lockfile", "manifest", "build-script", "heuristic"}, kinds)
+
+	// 再次合并保留原有证据
+	again := Merge(append(merged, models.Dependency{Name: "zlib", ConfigFileType: "elf", DetectedBy: "elf"}))
+	require.Len(t, again, 1)
+	assert.Len(t, again[0].Evidence, 5)
+}
+
+func TestMerge_Versions(t *testing.T) {
+	deps := []models.Dependency{
+		{Name: "zlib", Version: "1.2.11", ConfigFile: "third_party/zlib", ConfigFileType: "directory", DetectedBy: "vendored"},
+		{Name: "zlib", Version: "1.3", ConfigFile: "vcpkg.json", ConfigFileType: "vcpkg.json", DetectedBy: "vcpkg"},
+		{Name: "zlib", ConfigFile: "CMakeLists.txt", ConfigFileType: "CMakeLists.txt", DetectedBy: "cmake"},
+		{Name: "zlib", ConfigFile: "src/main.c", ConfigFileType: "source", DetectedBy: "include"},
+	}
+
+	// 不同版本分别保留, 按最可信来源排列; 没有版本的发现并入清单声明的版本
+	merged := Merge(deps)
+	require.Len(t, merged, 2)
+	assert.Equal(t, "1.3", merged[0].Version)
+	assert.Len(t, merged[0].Evidence, 3)
+	assert.Equal(t, "1.2.11", merged[1].Version)
+	require.Len(t, merged[1].Evidence, 1)
+	assert.Equal(t, "vendored", merged[1].Evidence[0].Extractor)
+
+	// 都没有版本时合并为一条
+	assert.Len(t, Merge(deps[2:]), 1)
+}
+
+func TestClassifySource(t *testing.T) {
+	assert.Equal(t, SourceLockfile, ClassifySource(&models.Dependency{ConfigFileType: "flake.lock"}))
+	assert.Equal(t, SourceManifest, ClassifySource(&models.Dependency{ConfigFileType: "conanfile.txt"}))
+	assert.Equal(t, SourceHeuristic, ClassifySource(&models.Dependency{ConfigFileType: "header"}))
+	assert.Equal(t, SourceBuildScript, ClassifySource(&models.Dependency{ConfigFileType: "SConstruct"}))
+}
+
+func TestMerge_Relationship(t *testing.T) {
+	deps := []models.Dependency{
+		{Name: "zlib", ConfigFileType: "package-lock.json", DetectedBy: "npm", Relationship: models.RelationshipTransitive},
+		{Name: "zlib", ConfigFileType: "CMakeLists.txt", DetectedBy: "cmake"},
+		{Name: "ssl", ConfigFileType: "yarn.lock", DetectedBy: "yarn", Relationship: models.RelationshipTransitive},
+		{Name: "ssl", ConfigFileType: "package-lock.json", DetectedBy: "npm", Relationship: models.RelationshipTransitive},
+	}
+
+	merged := Merge(deps)
+	require.Len(t, merged, 2)
+	// 任一来源直接声明即为直接依赖
+	assert.Equal(t, "ssl", merged[0].Name)
+	assert.Equal(t, models.RelationshipTransitive, merged[0].Relationship)
+	assert.True(t, merged[1].IsDirect())
+}
+
+func TestMerge_Scope(t *testing.T) {
+	deps := []models.Dependency{
+		{Name: "gtest", ConfigFile: "tests/CMakeLists.txt", DetectedBy: "cmake", Scope: models.ScopeTest},
+		{Name: "gtest", ConfigFile: "vcpkg.json", DetectedBy: "vcpkg", Scope: models.ScopeOptional},
+		{Name: "zlib", DetectedBy: "cmake"},
+	}
+
+	merged := Merge(deps)
+	require.Len(t, merged, 2)
+	assert.Equal(t, models.ScopeOptional, merged[0].Scope)
+	assert.Empty(t, merged[1].Scope)
+}
@@ -0,0 +1,251 @@
+package scanner
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/lkpsg/ccscanner/internal/extractor"
+	"github.com/lkpsg/ccscanner/internal/ignore"
+	"github.com/lkpsg/ccscanner/pkg/utils"
+	"go.uber.org/zap"
+)
+
+// ignoreFileName 项目级忽略文件, 语法与.gitignore相同, 优先级高于.gitignore和默认规则
+const ignoreFileName = ".ccscannerignore"
+
+// defaultExcludes 默认跳过的构建输出和包管理器目录, 优先级最低, 可在.gitignore或.ccscannerignore中用!重新包含
+var defaultExcludes = []string{
+	"build/",
+	"out/",
+	"_build/",
+	"cmake-build-*/",
+	"bazel-*/",
+	"node_modules/",
+	"target/",
+	"dist/",
+}
+
+// buildMetadataFiles 通常生成在构建目录中的依赖元数据, 构建目录被跳过时仍提取其顶层的这些文件
+var buildMetadataFiles = []string{"CMakeCache.txt", "compile_commands.json"}
+
+// pathFilter 遍历目录时决定跳过哪些路径
+//
+// 规则按以下顺序检查: 隐藏文件、最大深度、配置的排除规则、.ccscannerignore、
+// 各级.gitignore(子目录规则优先)与默认排除规则、配置的包含规则。
+type pathFilter struct {
+	fsys     fs.FS // 扫描来源, 路径相对扫描根目录
+	maxDepth int
+	excludes []*ignore.Pattern
+	includes []*ignore.Pattern
+	project  *ignore.Matcher            // .ccscannerignore
+	matchers map[string]*ignore.Matcher // 目录(相对根目录) -> 默认规则及该目录和上级目录.gitignore中的规则
+	logger   *zap.Logger
+}
+
+// newPathFilter 根据提取器配置和根目录下的.ccscannerignore创建过滤器
+func newPathFilter(fsys fs.FS, config extractor.ExtractorConfig, logger *zap.Logger) (*pathFilter, error) {
+	f := &pathFilter{
+		fsys:     fsys,
+		maxDepth: config.MaxDepth,
+		matchers: make(map[string]*ignore.Matcher),
+		logger:   logger,
+	}
+
+	var err error
+	if f.excludes, err = parsePatterns(config.ExcludeFiles, "exclude"); err != nil {
+		return nil, err
+	}
+	if f.includes, err = parsePatterns(config.IncludeFiles, "include"); err != nil {
+		return nil, err
+	}
+
+	defaults, err := parsePatterns(defaultExcludes, "default")
+	if err != nil {
+		return nil, err
+	}
+	// .gitignore解析失败时与子目录一样只记录警告, 不中断扫描
+	rootIgnores, err := ignore.ParseFS(fsys, ".gitignore", "", ".gitignore")
+	if err != nil {
+		logger.Warn("解析.gitignore失败",
+			zap.String("dir", "."),
+			zap.Error(err),
+		)
+	}
+	f.matchers[""] = ignore.NewMatcher(defaults...).Extend(rootIgnores...)
+
+	projectIgnores, err := ignore.ParseFS(fsys, ignoreFileName, "", ignoreFileName)
+	if err != nil {
+		return nil, err
+	}
+	f.project = ignore.NewMatcher(projectIgnores...)
+
+	return f, nil
+}
+
+// parsePatterns 解析配置中的规则, 规则相对扫描根目录
+func parsePatterns(texts []string, source string) ([]*ignore.Pattern, error) {
+	var patterns []*ignore.Pattern
+	for _, text := range texts {
+		p, err := ignore.ParsePattern(text, "")
+		if err != nil {
+			return nil, err
+		}
+		if p == nil {
+			continue
+		}
+		p.Source = source
+		patterns = append(patterns, p)
+	}
+	return patterns, nil
+}
+
+// skip 判断路径(相对扫描根目录, 以/分隔)是否需要跳过, 返回跳过原因(不跳过时为空)
+// buildDir 为true表示目录因.gitignore或默认规则被跳过, 其中的构建元数据仍应提取
+func (f *pathFilter) skip(rel string, d fs.DirEntry) (reason string, buildDir bool) {
+	if rel == "." {
+		return "", false
+	}
+	isDir := d.IsDir()
+
+	reason, buildDir = f.match(rel, d)
+	if reason != "" {
+		f.logger.Debug("跳过路径",
+			zap.String("path", rel),
+			zap.Bool("dir", isDir),
+			zap.String("reason", reason),
+		)
+		return reason, buildDir
+	}
+
+	if isDir {
+		f.enterDir(rel)
+	}
+	return "", false
+}
+
+// match 依次检查各类规则, 返回第一条生效规则对应的跳过原因
+func (f *pathFilter) match(rel string, d fs.DirEntry) (string, bool) {
+	isDir := d.IsDir()
+
+	// 隐藏文件和目录(.gitmodules除外)
+	if utils.IsHidden(d.Name()) && !isGitmodules(d) {
+		return "hidden", false
+	}
+
+	if isDir && f.maxDepth > 0 && strings.Count(rel, "/")+1 > f.maxDepth {
+		return fmt.Sprintf("deeper than max depth %d", f.maxDepth), false
+	}
+
+	for _, p := range f.excludes {
+		if p.Match(rel, isDir) {
+			return "exclude pattern " + p.String(), false
+		}
+	}
+
+	// .ccscannerignore中的!规则可以重新包含被.gitignore或默认规则排除的路径
+	p, ignored := f.project.Match(rel, isDir)
+	if ignored {
+		return "ignored by " + p.String(), false
+	}
+	if p == nil {
+		if p, ignored = f.matcher(rel).Match(rel, isDir); ignored {
+			if p.Source == "default" {
+				return "build output " + p.String(), isDir
+			}
+			return "ignored by " + p.String(), isDir
+		}
+	}
+
+	if !isDir && len(f.includes) > 0 && !ignore.MatchAny(f.includes, rel) {
+		return "not matched by include patterns", false
+	}
+	return "", false
+}
+
+// matcher 返回路径所在目录生效的.gitignore规则
+func (f *pathFilter) matcher(rel string) *ignore.Matcher {
+	for dir := path.Dir(rel); ; dir = path.Dir(dir) {
+		if dir == "." {
+			dir = ""
+		}
+		if m, ok := f.matchers[dir]; ok {
+			return m
+		}
+		if dir == "" {
+			return f.matchers[""]
+		}
+	}
+}
+
+// enterDir 进入目录时读取其中的.gitignore, 规则追加在上级目录规则之后
+func (f *pathFilter) enterDir(rel string) {
+	parent := f.matcher(rel)
+	patterns, err := ignore.ParseFS(f.fsys, rel+"/.gitignore", rel, rel+"/.gitignore")
+	if err != nil {
+		f.logger.Warn("解析.gitignore失败",
+			zap.String("dir", rel),
+			zap.Error(err),
+		)
+	}
+	f.matchers[rel] = parent.Extend(patterns...)
+}
+
+// treeSkip 为遍历整个源码树的提取器创建跳过判断, 规则与目录遍历相同
+//
+// 每次调用复制一份过滤器, 各级.gitignore在遍历中加载, 不能在并发运行的提取器间共享。
+// binaries为true时进入被.gitignore或默认规则跳过的构建目录, 其中的二进制文件正是ELF和签名检测的对象。
+func (f *pathFilter) treeSkip(binaries bool) extractor.SkipFunc {
+	tree := &pathFilter{
+		fsys:     f.fsys,
+		maxDepth: f.maxDepth,
+		excludes: f.excludes,
+		includes: f.includes,
+		project:  f.project,
+		matchers: map[string]*ignore.Matcher{"": f.matchers[""]},
+		logger:   f.logger,
+	}
+	return func(rel string, d fs.DirEntry) bool {
+		reason, buildDir := tree.skip(rel, d)
+		if reason == "" {
+			return false
+		}
+		if binaries && buildDir {
+			tree.enterDir(rel)
+			return false
+		}
+		return true
+	}
+}
+
+// buildMetadata 返回被跳过的构建目录顶层可能存在的构建元数据文件
+func (f *pathFilter) buildMetadata(dir string) []string {
+	files := make([]string, len(buildMetadataFiles))
+	for i, name := range buildMetadataFiles {
+		files[i] = path.Join(dir, name)
+	}
+	return files
+}
+
+/*
+使用示例:
+
+fsys := os.DirFS("/path/to/project")
+filter, err := newPathFilter(fsys, extractor.ExtractorConfig{
+	ExcludeFiles: []string{"docs/", "*.bak"},
+	MaxDepth:     8,
+}, logger)
+if err != nil {
+	log.Fatal(err)
+}
+fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+	if reason, _ := filter.skip(name, d); reason != "" {
+		if d.IsDir() {
+			return fs.SkipDir
+		}
+		return nil
+	}
+	...
+})
+*/
@@ -0,0 +1,151 @@
+package scanner
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/lkpsg/ccscanner/internal/extractor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// walkFiltered 按过滤器遍历目录, 返回保留的文件和被跳过的构建目录中提取的元数据文件
+func walkFiltered(t *testing.T, root string, config extractor.ExtractorConfig) []string {
+	t.Helper()
+	fsys := os.DirFS(root)
+	filter, err := newPathFilter(fsys, config, zap.NewNop())
+	require.NoError(t, err)
+
+	var files []string
+	err = fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		require.NoError(t, err)
+		reason, buildDir := filter.skip(name, d)
+		if reason == "" {
+			if !d.IsDir() {
+				files = append(files, name)
+			}
+			return nil
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if buildDir {
+			for _, file := range filter.buildMetadata(name) {
+				if _, err := fs.Stat(fsys, file); err == nil {
+					files = append(files, file)
+				}
+			}
+		}
+		return fs.SkipDir
+	})
+	require.NoError(t, err)
+	sort.Strings(files)
+	return files
+}
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+}
+
+func TestPathFilter(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		".gitignore":                         "*.log\ngenerated/\n",
+		"CMakeLists.txt":                     "",
+		"debug.log":                          "",
+		"build/CMakeCache.txt":               "",
+		"build/compile_commands.json":        "",
+		"build/sub/CMakeLists.txt":           "",
+		"cmake-build-debug/CMakeLists.txt":   "",
+		"node_modules/left-pad/package.json": "",
+		"src/CMakeLists.txt":                 "",
+		"src/.gitignore":                     "/local/\n!keep.log\n",
+		"src/keep.log":                       "",
+		"src/local/conanfile.txt":            "",
+		"src/generated/vcpkg.json":           "",
+		"lib/local/conanfile.txt":            "",
+		".git/config":                        "",
+	})
+
+	files := walkFiltered(t, root, extractor.ExtractorConfig{})
+	assert.Equal(t, []string{
+		"CMakeLists.txt",
+		"build/CMakeCache.txt",
+		"build/compile_commands.json",
+		"lib/local/conanfile.txt",
+		"src/CMakeLists.txt",
+		"src/keep.log",
+	}, files)
+}
+
+func TestPathFilter_ProjectIgnore(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		".gitignore":       "third_party/\n",
+		".ccscannerignore": "!third_party/\ndocs/\n!out/\n",
+		"third_party/x.pc": "",
+		"docs/Makefile":    "",
+		"out/vcpkg.json":   "",
+		"dist/vcpkg.json":  "",
+		"Makefile":         "",
+	})
+
+	files := walkFiltered(t, root, extractor.ExtractorConfig{})
+	assert.Equal(t, []string{"Makefile", "out/vcpkg.json", "third_party/x.pc"}, files)
+}
+
+func TestPathFilter_Config(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"CMakeLists.txt":       "",
+		"a/CMakeLists.txt":     "",
+		"a/b/CMakeLists.txt":   "",
+		"a/b/c/CMakeLists.txt": "",
+		"examples/Makefile":    "",
+		"src/Makefile":         "",
+		"src/conanfile.txt":    "",
+		"src/legacy/Makefile":  "",
+	})
+
+	files := walkFiltered(t, root, extractor.ExtractorConfig{MaxDepth: 2})
+	assert.NotContains(t, files, "a/b/c/CMakeLists.txt")
+	assert.Contains(t, files, "a/b/CMakeLists.txt")
+
+	files = walkFiltered(t, root, extractor.ExtractorConfig{
+		ExcludeFiles: []string{"examples/", "src/legacy"},
+		IncludeFiles: []string{"src/", "CMakeLists.txt"},
+	})
+	assert.Equal(t, []string{
+		"CMakeLists.txt",
+		"a/CMakeLists.txt",
+		"a/b/CMakeLists.txt",
+		"a/b/c/CMakeLists.txt",
+		"src/Makefile",
+		"src/conanfile.txt",
+	}, files)
+
+	_, err := newPathFilter(os.DirFS(root), extractor.ExtractorConfig{ExcludeFiles: []string{"[bad"}}, zap.NewNop())
+	assert.Error(t, err)
+}
+
+func TestPathFilter_InvalidGitignore(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		".gitignore":     "[bad\n",
+		"CMakeLists.txt": "",
+		"build/app.c":    "",
+	})
+
+	// 根目录.gitignore解析失败时忽略其中的规则, 默认规则仍然生效
+	files := walkFiltered(t, root, extractor.ExtractorConfig{})
+	assert.Equal(t, []string{"CMakeLists.txt"}, files)
+}
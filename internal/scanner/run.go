@@ -0,0 +1,290 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lkpsg/ccscanner/internal/extractor"
+	"github.com/lkpsg/ccscanner/internal/graph"
+	"github.com/lkpsg/ccscanner/internal/identity"
+	"github.com/lkpsg/ccscanner/internal/merger"
+	"github.com/lkpsg/ccscanner/pkg/models"
+	"go.uber.org/zap"
+)
+
+// scanExtractorTypes 扫描器能够运行的提取器, 用于校验 ScanOptions.Extractors
+var scanExtractorTypes = map[extractor.ExtractorType]bool{
+	extractor.CMakeExtractorType:           true,
+	extractor.MakeExtractorType:            true,
+	extractor.ConanExtractorType:           true,
+	extractor.VcpkgExtractorType:           true,
+	extractor.SubmoduleExtractorType:       true,
+	extractor.MesonExtractorType:           true,
+	extractor.PkgConfigExtractorType:       true,
+	extractor.AutoconfExtractorType:        true,
+	extractor.ControlExtractorType:         true,
+	extractor.PkgbuildExtractorType:        true,
+	extractor.NixExtractorType:             true,
+	extractor.CompileCommandsExtractorType: true,
+	extractor.CMakeCacheExtractorType:      true,
+	extractor.VendoredExtractorType:        true,
+	extractor.VersionMacroExtractorType:    true,
+	extractor.IncludeExtractorType:         true,
+	extractor.ElfExtractorType:             true,
+	extractor.SignatureExtractorType:       true,
+}
+
+// ScanOptions 单次扫描的选项, 未设置的字段使用扫描器配置
+type ScanOptions struct {
+	Root          string                     // 扫描根目录或源码压缩包(为空时使用Config.TargetDir)
+	Revision      string                     // 扫描的Git版本(标签、分支或提交), Root为仓库路径(为空时使用Config.Revision)
+	Extractors    []string                   // 启用的提取器类型(如 cmake、conan), 为空表示全部启用
+	Filters       *extractor.ExtractorConfig // 路径和依赖范围过滤(为nil时使用Config.Extractor)
+	MinConfidence float64                    // 最低置信度(为0时使用Config.MinConfidence)
+	Progress      func(ProgressEvent)        // 进度回调, 串行调用, 不应长时间阻塞
+}
+
+// ProgressEvent 扫描进度
+type ProgressEvent struct {
+	Discovered  int    `json:"discovered"`  // 已发现的待提取文件数(源码树检测按根目录计一次)
+	Processed   int    `json:"processed"`   // 已处理完成的文件数
+	CurrentFile string `json:"currentFile"` // 最近开始处理的文件
+	Extractor   string `json:"extractor"`   // 处理该文件的提取器类型
+}
+
+// scanRun 一次扫描的状态, 每次扫描独立创建, 并发扫描之间互不影响
+type scanRun struct {
+	config     Config
+	extractors []string                         // 启用的提取器类型
+	enabled    map[extractor.ExtractorType]bool // 为nil表示全部启用
+	progress   func(ProgressEvent)
+
+	// 流式扫描时发现的依赖和错误直接发送给调用方, 不在内存中收集
+	ctx      context.Context
+	events   chan<- Event
+	streamed int          // 已发送的依赖数
+	wait     func() error // 等待所有提取任务结束
+
+	mu       sync.Mutex // 保护以下字段
+	result   *models.DependencyResult
+	findings []models.Dependency // 各提取器的原始发现, 扫描结束后合并
+	event    ProgressEvent
+}
+
+// newRun 合并扫描器配置与本次扫描选项
+func (s *Scanner) newRun(opts ScanOptions) (*scanRun, error) {
+	config := s.config
+	if opts.Root != "" {
+		config.TargetDir = opts.Root
+	}
+	if opts.Revision != "" {
+		config.Revision = opts.Revision
+	}
+	if opts.Filters != nil {
+		config.Extractor = *opts.Filters
+	}
+	if opts.MinConfidence > 0 {
+		config.MinConfidence = opts.MinConfidence
+	}
+
+	run := &scanRun{
+		config:     config,
+		extractors: opts.Extractors,
+		progress:   opts.Progress,
+		result:     models.NewDependencyResult(filepath.Base(config.TargetDir), config.TargetDir),
+	}
+	for _, name := range opts.Extractors {
+		typ := extractor.ExtractorType(strings.ToLower(strings.TrimSpace(name)))
+		if !scanExtractorTypes[typ] {
+			return nil, fmt.Errorf("未知的提取器类型: %s", name)
+		}
+		if run.enabled == nil {
+			run.enabled = make(map[extractor.ExtractorType]bool)
+		}
+		run.enabled[typ] = true
+	}
+	return run, nil
+}
+
+// isEnabled 判断本次扫描是否启用了该提取器
+func (r *scanRun) isEnabled(typ extractor.ExtractorType) bool {
+	return r.enabled == nil || r.enabled[typ]
+}
+
+// setCommit 在结果中记录扫描的Git版本和提交
+func (r *scanRun) setCommit(commit string) {
+	if commit == "" {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.result.Revision = r.config.Revision
+	r.result.Commit = commit
+}
+
+// discovered 记录发现了一个待提取的文件
+func (r *scanRun) discovered() {
+	r.update(func(e *ProgressEvent) {
+		e.Discovered++
+	})
+}
+
+// started 记录开始处理文件
+func (r *scanRun) started(typ extractor.ExtractorType, path string) {
+	r.update(func(e *ProgressEvent) {
+		e.CurrentFile = path
+		e.Extractor = string(typ)
+	})
+}
+
+// processed 记录文件处理完成
+func (r *scanRun) processed() {
+	r.update(func(e *ProgressEvent) {
+		e.Processed++
+	})
+}
+
+// update 更新进度并通知回调, 持锁调用保证回调按顺序收到事件
+func (r *scanRun) update(fn func(*ProgressEvent)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	fn(&r.event)
+	if r.progress != nil {
+		r.progress(r.event)
+	}
+}
+
+// addDependencies 记录提取器的发现(线程安全), 流式扫描时逐个发送, 调用方取消时返回错误
+func (r *scanRun) addDependencies(deps []models.Dependency) error {
+	identity.Default().Canonicalize(deps)
+
+	if r.events != nil {
+		for i := range deps {
+			r.mu.Lock()
+			accepted := r.accept(&deps[i])
+			if accepted {
+				r.streamed++
+			}
+			r.mu.Unlock()
+			if !accepted {
+				continue
+			}
+			if err := r.send(Event{Type: EventDependency, Dependency: &deps[i]}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.findings = append(r.findings, deps...)
+	return nil
+}
+
+// addError 记录提取错误(线程安全), 流式扫描时作为诊断事件发送
+func (r *scanRun) addError(file, message string) error {
+	r.mu.Lock()
+	r.result.AddError(message)
+	r.mu.Unlock()
+
+	if r.events == nil {
+		return nil
+	}
+	return r.send(Event{Type: EventDiagnostic, Diagnostic: &Diagnostic{
+		Level:   DiagnosticError,
+		File:    file,
+		Message: message,
+	}})
+}
+
+// send 发送事件, 调用方未及时接收时阻塞(背压), ctx取消时放弃发送
+func (r *scanRun) send(event Event) error {
+	select {
+	case r.events <- event:
+		return nil
+	case <-r.ctx.Done():
+		return r.ctx.Err()
+	}
+}
+
+// finish 合并不同提取器对同一依赖的重复发现, 根据依赖关系图区分直接/传递依赖, 再按置信度和范围过滤
+func (r *scanRun) finish(startTime time.Time) *models.DependencyResult {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	merged := merger.Merge(r.findings)
+	graph.Classify(merged)
+	for _, dep := range merged {
+		if r.accept(&dep) {
+			r.result.AddDependency(dep)
+		}
+	}
+	r.findings = nil
+
+	// 更新扫描时间
+	r.result.ScanDuration = time.Since(startTime).Seconds()
+	return r.result
+}
+
+// accept 按最低置信度和依赖范围过滤依赖并计数, 调用方需持有r.mu
+func (r *scanRun) accept(dep *models.Dependency) bool {
+	if dep.Confidence > 0 && dep.Confidence < r.config.MinConfidence {
+		r.config.Logger.Debug("依赖置信度过低, 已过滤",
+			zap.String("name", dep.Name),
+			zap.Float64("confidence", dep.Confidence),
+			zap.String("reason", dep.ConfidenceReason),
+		)
+		r.result.FilteredDeps++
+		return false
+	}
+	if !r.scopeAllowed(dep) {
+		r.config.Logger.Debug("依赖范围不在扫描范围内, 已过滤",
+			zap.String("name", dep.Name),
+			zap.String("scope", dep.EffectiveScope()),
+		)
+		r.result.ScopeFilteredDeps++
+		return false
+	}
+	return true
+}
+
+// scopeAllowed 判断依赖范围是否符合配置: IgnoreTests时排除测试依赖, 指定Scopes时只保留其中的范围
+func (r *scanRun) scopeAllowed(dep *models.Dependency) bool {
+	scope := dep.EffectiveScope()
+	if r.config.Extractor.IgnoreTests && scope == models.ScopeTest {
+		return false
+	}
+	if len(r.config.Extractor.Scopes) == 0 {
+		return true
+	}
+	for _, allowed := range r.config.Extractor.Scopes {
+		if models.NormalizeScope(allowed) == scope {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+使用示例:
+
+ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+defer cancel()
+
+result, err := scanner.ScanContext(ctx, ScanOptions{
+	Root:       "/path/to/project",
+	Extractors: []string{"cmake", "conan", "vcpkg"},
+	Filters:    &extractor.ExtractorConfig{IgnoreTests: true, MaxDepth: 8},
+	Progress: func(e ProgressEvent) {
+		fmt.Printf("%d/%d %s\n", e.Processed, e.Discovered, e.CurrentFile)
+	},
+})
+*/
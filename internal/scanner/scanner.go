@@ -1,9 +1,12 @@
 package scanner
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
 	"sync"
 	"time"
@@ -11,26 +14,28 @@ import (
 	"github.com/lkpsg/ccscanner/internal/cache"
 	"github.com/lkpsg/ccscanner/internal/extractor"
 	"github.com/lkpsg/ccscanner/pkg/models"
-	"github.com/lkpsg/ccscanner/pkg/utils"
 	"go.uber.org/zap"
 	"golang.org/x/sync/errgroup"
 )
 
 // Config 扫描器配置
 type Config struct {
-	TargetDir    string     // 目标目录
+	TargetDir    string     // 目标目录或源码压缩包(tar、tar.gz、tar.xz、zip)
+	Revision     string     // 扫描的Git版本(标签、分支或提交), TargetDir为仓库路径, 为空时扫描工作区
 	OutputFile   string     // 输出文件
 	EnableCache  bool       // 是否启用缓存
 	MaxWorkers   int        // 最大工作协程数
 	Logger       *zap.Logger // 日志记录器
+	MinConfidence float64   // 最低置信度, 低于该值的依赖不计入结果(0表示不过滤)
+	Extractor    extractor.ExtractorConfig // 提取器配置(IgnoreTests、Scopes用于按依赖范围过滤, ExcludeFiles、IncludeFiles、MaxDepth用于过滤遍历的路径)
 }
 
-// Scanner 依赖扫描器
+// Scanner 依赖扫描器, 可重复使用和并发调用, 每次扫描的状态相互独立
 type Scanner struct {
 	config Config
 	cache  *cache.Cache
-	result *models.DependencyResult
-	mu     sync.Mutex // 保护result
+	result *models.DependencyResult // 最近一次完成的扫描结果
+	mu     sync.Mutex               // 保护result
 }
 
 // NewScanner 创建新的扫描器实例
@@ -57,50 +62,102 @@ func NewScanner(config Config) *Scanner {
 	return scanner
 }
 
-// Scan 执行依赖扫描
+// Scan 按扫描器配置执行依赖扫描, 结果通过GetResults获取
 func (s *Scanner) Scan() error {
+	_, err := s.ScanContext(context.Background(), ScanOptions{})
+	return err
+}
+
+// ScanContext 按本次扫描选项执行依赖扫描, 每次调用返回新的结果
+//
+// ctx取消后不再提交新的提取任务并立即返回ctx的错误, 已在运行的提取器结束后其结果被丢弃。
+func (s *Scanner) ScanContext(ctx context.Context, opts ScanOptions) (*models.DependencyResult, error) {
+	run, err := s.newRun(opts)
+	if err != nil {
+		return nil, err
+	}
+
 	startTime := time.Now()
+	if err := s.execute(ctx, run); err != nil {
+		return nil, err
+	}
+
+	result := run.finish(startTime)
+	s.config.Logger.Info("扫描完成",
+		zap.Int("total_deps", result.TotalDeps),
+		zap.Int("direct_deps", result.DirectDeps),
+		zap.Int("indirect_deps", result.IndirectDeps),
+		zap.Int("vulnerable_deps", result.VulnerableDeps),
+		zap.Int("low_confidence_deps", result.LowConfidenceDeps),
+		zap.Int("filtered_deps", result.FilteredDeps),
+		zap.Int("scope_filtered_deps", result.ScopeFilteredDeps),
+		zap.Float64("duration", result.ScanDuration),
+	)
+
+	s.mu.Lock()
+	s.result = result
+	s.mu.Unlock()
+
+	return result, nil
+}
+
+// execute 遍历目录并运行启用的提取器, 提取结果交给run收集或以事件发送
+func (s *Scanner) execute(ctx context.Context, run *scanRun) error {
+	root := run.config.TargetDir
 	s.config.Logger.Info("开始扫描",
-		zap.String("target", s.config.TargetDir),
+		zap.String("target", root),
+		zap.String("revision", run.config.Revision),
 		zap.Bool("cache_enabled", s.config.EnableCache),
+		zap.Strings("extractors", run.extractors),
 	)
 
 	// 创建错误组和信号量
-	eg := errgroup.Group{}
+	eg, egCtx := errgroup.WithContext(ctx)
 	sem := make(chan struct{}, s.config.MaxWorkers)
+	run.wait = eg.Wait
 
-	// 遍历目录
-	err := filepath.Walk(s.config.TargetDir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
+	// 扫描Git版本时从对象库读取, 扫描目标为压缩包时在内存中读取, 提取器通过fs.FS读取其中的文件
+	src, err := openSource(root, run.config.Revision, s.config.Logger)
+	if err != nil {
+		return err
+	}
+	run.setCommit(src.commit)
+	// 缓存按磁盘上的文件内容校验, 虚拟文件系统中的文件不使用缓存
+	useCache := s.config.EnableCache && !src.virtual
 
-		// 跳过隐藏文件和目录
-		if utils.IsHidden(info.Name()) {
-			if info.IsDir() {
-				return filepath.SkipDir
-			}
-			return nil
-		}
+	// 遍历目录时按隐藏文件、深度、包含/排除规则、.gitignore和.ccscannerignore过滤路径
+	filter, err := newPathFilter(src.fsys, run.config.Extractor, s.config.Logger)
+	if err != nil {
+		return fmt.Errorf("加载忽略规则失败: %v", err)
+	}
 
-		// 检查是否是配置文件
-		ext := s.detectFileType(path, info)
-		if ext == nil {
-			return nil
-		}
+	// 提交提取任务, 等待信号量时响应取消, path为结果中显示的路径
+	submit := func(typ extractor.ExtractorType, path string, ext extractor.Extractor) error {
+		run.discovered()
 
 		// 如果启用了缓存,检查缓存
-		if s.config.EnableCache {
+		if useCache {
 			if deps, ok := s.cache.Get(path); ok {
-				s.addDependencies(deps)
+				if err := run.addDependencies(deps); err != nil {
+					return err
+				}
+				run.processed()
 				return nil
 			}
 		}
 
 		// 添加扫描任务
-		sem <- struct{}{} // 获取信号量
+		select {
+		case sem <- struct{}{}: // 获取信号量
+		case <-egCtx.Done():
+			return egCtx.Err()
+		}
 		eg.Go(func() error {
 			defer func() { <-sem }() // 释放信号量
+			if err := egCtx.Err(); err != nil {
+				return err
+			}
+			run.started(typ, path)
 
 			// 提取依赖
 			deps, err := ext.Extract()
@@ -109,43 +166,118 @@ func (s *Scanner) Scan() error {
 					zap.String("file", path),
 					zap.Error(err),
 				)
-				s.result.AddError(fmt.Sprintf("Failed to extract dependencies from %s: %v", path, err))
-				return nil
+				run.processed()
+				return run.addError(path, fmt.Sprintf("Failed to extract dependencies from %s: %v", path, err))
 			}
 
+			src.relabel(deps)
+
 			// 更新缓存
-			if s.config.EnableCache {
+			if useCache {
 				s.cache.Set(path, deps)
 			}
 
 			// 添加依赖
-			s.addDependencies(deps)
+			if err := run.addDependencies(deps); err != nil {
+				return err
+			}
+			run.processed()
 			return nil
 		})
-
 		return nil
-	})
-
-	if err != nil {
-		return fmt.Errorf("扫描目录失败: %v", err)
 	}
 
-	// 等待所有任务完成
-	if err := eg.Wait(); err != nil {
-		return fmt.Errorf("扫描任务失败: %v", err)
+	// 嵌套子模块中的.gitmodules由SubmoduleExtractor递归提取, 不重复提交
+	submodules := newSubmoduleTracker()
+
+	// submitFile 提交配置文件的提取任务, name为相对扫描根目录的路径
+	submitFile := func(name string, d fs.DirEntry) error {
+		// 检查是否是配置文件
+		typ, ext := s.detectFileType(src.path(name), d, run.config.Extractor)
+		if ext == nil || !run.isEnabled(typ) || !src.prepare(ext) {
+			return nil
+		}
+		if sub, ok := ext.(*extractor.SubmoduleExtractor); ok && !submodules.claim(name, sub) {
+			return nil
+		}
+		return submit(typ, src.display(name), ext)
 	}
 
-	// 更新扫描时间
-	s.result.ScanDuration = time.Since(startTime).Seconds()
+	// 遍历目录
+	err = fs.WalkDir(src.fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := egCtx.Err(); err != nil {
+			return err
+		}
+
+		reason, buildDir := filter.skip(name, d)
+		if reason == "" {
+			if !d.IsDir() {
+				return submitFile(name, d)
+			}
+			return nil
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		// 构建目录被忽略时仍提取其中的CMakeCache.txt、compile_commands.json
+		if buildDir {
+			for _, file := range filter.buildMetadata(name) {
+				if info, err := fs.Stat(src.fsys, file); err == nil && info.Mode().IsRegular() {
+					if err := submitFile(file, fs.FileInfoToDirEntry(info)); err != nil {
+						return err
+					}
+				}
+			}
+		}
+		return fs.SkipDir
+	})
 
-	s.config.Logger.Info("扫描完成",
-		zap.Int("total_deps", s.result.TotalDeps),
-		zap.Int("direct_deps", s.result.DirectDeps),
-		zap.Int("indirect_deps", s.result.IndirectDeps),
-		zap.Int("vulnerable_deps", s.result.VulnerableDeps),
-		zap.Float64("duration", s.result.ScanDuration),
-	)
+	// 针对整个源码树的检测: 拷贝进源码树的第三方库、头文件版本宏、#include推断的依赖、构建产物的运行时依赖
+	// 遍历时使用与上面相同的过滤规则, ELF和签名检测仍进入构建目录
+	if err == nil {
+		treeRoot := src.path(".")
+		treeExtractors := []struct {
+			typ      extractor.ExtractorType
+			ext      extractor.TreeExtractor
+			binaries bool
+		}{
+			{extractor.VendoredExtractorType, extractor.NewVendoredExtractor(treeRoot), false},
+			{extractor.VersionMacroExtractorType, extractor.NewVersionMacroExtractor(treeRoot), false},
+			{extractor.IncludeExtractorType, extractor.NewIncludeExtractor(treeRoot), false},
+			{extractor.ElfExtractorType, extractor.NewElfExtractor(treeRoot), true},
+			{extractor.SignatureExtractorType, extractor.NewSignatureExtractor(treeRoot), true},
+		}
+		for _, tree := range treeExtractors {
+			if !run.isEnabled(tree.typ) || !src.prepare(tree.ext) {
+				continue
+			}
+			tree.ext.SetSkip(filter.treeSkip(tree.binaries))
+			if err = submit(tree.typ, root, tree.ext); err != nil {
+				break
+			}
+		}
+	}
 
+	// 等待所有任务完成, 取消时不等待仍在运行的提取器
+	done := make(chan error, 1)
+	go func() { done <- eg.Wait() }()
+	select {
+	case waitErr := <-done:
+		if err == nil {
+			err = waitErr
+		}
+	case <-ctx.Done():
+	}
+	if ctx.Err() != nil {
+		s.config.Logger.Info("扫描已取消", zap.String("target", root))
+		return fmt.Errorf("扫描已取消: %w", ctx.Err())
+	}
+	if err != nil {
+		return fmt.Errorf("扫描目录失败: %v", err)
+	}
 	return nil
 }
 
@@ -157,7 +289,7 @@ func (s *Scanner) SaveResults() error {
 	}
 
 	// 序列化结果
-	data, err := json.MarshalIndent(s.result, "", "  ")
+	data, err := json.MarshalIndent(s.GetResults(), "", "  ")
 	if err != nil {
 		return fmt.Errorf("序列化结果失败: %v", err)
 	}
@@ -170,49 +302,104 @@ func (s *Scanner) SaveResults() error {
 	return nil
 }
 
-// GetResults 获取扫描结果
+// GetResults 获取最近一次完成的扫描结果
 func (s *Scanner) GetResults() *models.DependencyResult {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	return s.result
 }
 
-// addDependencies 添加依赖项(线程安全)
-func (s *Scanner) addDependencies(deps []models.Dependency) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+// isGitmodules 判断是否为.gitmodules文件, 是否提取由submoduleTracker决定
+func isGitmodules(d fs.DirEntry) bool {
+	return !d.IsDir() && d.Name() == ".gitmodules"
+}
 
-	for _, dep := range deps {
-		s.result.AddDependency(dep)
+// submoduleTracker 记录SubmoduleExtractor递归到达的子模块目录, 其中的.gitmodules不再单独提取
+//
+// fs.WalkDir按名称顺序遍历, 目录中的.gitmodules总是先于其子目录被访问。
+type submoduleTracker struct {
+	reached map[string]int // 子模块目录(fs路径) → 嵌套层数, 1表示单独提取的.gitmodules直接声明的子模块
+}
+
+func newSubmoduleTracker() *submoduleTracker {
+	return &submoduleTracker{reached: make(map[string]int)}
+}
+
+// claim 判断name处的.gitmodules是否需要单独提取, 并记录提取时递归到达的子模块目录
+//
+// 位于扫描根目录或不在已到达的子模块目录中的.gitmodules需要提取; 子模块根目录中的.gitmodules
+// 在递归层数内时已由上级的SubmoduleExtractor提取, 其声明的子模块同样被递归到达。
+func (t *submoduleTracker) claim(name string, ext *extractor.SubmoduleExtractor) bool {
+	dir := path.Dir(name)
+	inside := false
+	for p := dir; p != "."; p = path.Dir(p) {
+		if _, ok := t.reached[p]; ok {
+			inside = true
+			break
+		}
+	}
+
+	paths, err := ext.SubmodulePaths()
+	if err != nil {
+		// 解析失败时由提取器报告错误
+		return !inside
+	}
+	if !inside {
+		t.record(dir, paths, 1)
+		return true
 	}
+	if depth, ok := t.reached[dir]; ok && depth <= ext.NestedDepth() {
+		t.record(dir, paths, depth+1)
+	}
+	return false
 }
 
-// detectFileType 检测文件类型并返回相应的提取器
-func (s *Scanner) detectFileType(path string, info os.FileInfo) extractor.Extractor {
-	filename := info.Name()
+// record 记录dir中的.gitmodules声明的子模块目录
+func (t *submoduleTracker) record(dir string, paths []string, depth int) {
+	for _, p := range paths {
+		t.reached[path.Join(dir, p)] = depth
+	}
+}
+
+// detectFileType 检测文件类型并返回相应的提取器类型和提取器
+func (s *Scanner) detectFileType(path string, d fs.DirEntry, config extractor.ExtractorConfig) (extractor.ExtractorType, extractor.Extractor) {
+	filename := d.Name()
 	ext := filepath.Ext(filename)
 
 	// 根据文件名和扩展名判断文件类型
 	switch {
 	case filename == "CMakeLists.txt" || ext == ".cmake":
-		return extractor.NewCMakeExtractor(path)
+		return extractor.CMakeExtractorType, extractor.NewCMakeExtractor(path)
 	case filename == "Makefile" || filename == "makefile":
-		return extractor.NewMakeExtractor(path)
+		return extractor.MakeExtractorType, extractor.NewMakeExtractor(path)
 	case filename == "conanfile.txt" || filename == "conanfile.py":
-		return extractor.NewConanExtractor(path)
+		return extractor.ConanExtractorType, extractor.NewConanExtractor(path)
 	case filename == "vcpkg.json":
-		return extractor.NewVcpkgExtractor(path)
+		return extractor.VcpkgExtractorType, extractor.NewVcpkgExtractor(path)
 	case filename == ".gitmodules":
-		return extractor.NewSubmoduleExtractor(path)
+		sub := extractor.NewSubmoduleExtractor(path)
+		sub.SetConfig(config)
+		return extractor.SubmoduleExtractorType, sub
 	case filename == "meson.build":
-		return extractor.NewMesonExtractor(path)
+		return extractor.MesonExtractorType, extractor.NewMesonExtractor(path)
 	case ext == ".pc":
-		return extractor.NewPkgConfigExtractor(path)
+		return extractor.PkgConfigExtractorType, extractor.NewPkgConfigExtractor(path)
 	case filename == "configure" || filename == "configure.ac":
-		return extractor.NewAutoconfExtractor(path)
+		return extractor.AutoconfExtractorType, extractor.NewAutoconfExtractor(path)
 	case ext == ".dsc":
-		return extractor.NewControlExtractor(path)
+		return extractor.ControlExtractorType, extractor.NewControlExtractor(path)
+	case filename == "PKGBUILD" || filename == "APKBUILD":
+		return extractor.PkgbuildExtractorType, extractor.NewPkgbuildExtractor(path)
+	case filename == "flake.lock" || filename == "default.nix" || filename == "flake.nix" || filename == "shell.nix":
+		return extractor.NixExtractorType, extractor.NewNixExtractor(path)
+	case filename == "compile_commands.json":
+		return extractor.CompileCommandsExtractorType, extractor.NewCompileCommandsExtractor(path)
+	case filename == "CMakeCache.txt":
+		return extractor.CMakeCacheExtractorType, extractor.NewCMakeCacheExtractor(path)
 	}
 
-	return nil
+	return "", nil
 }
 
 /*
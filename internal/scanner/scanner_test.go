@@ -0,0 +1,285 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/lkpsg/ccscanner/internal/extractor"
+	"github.com/lkpsg/ccscanner/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newTestProject(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"conanfile.txt":     "[requires]\nzlib/1.3\n",
+		"app/vcpkg.json":    `{"name": "app", "dependencies": [{"name": "fmt"}]}`,
+		"lib/conanfile.txt": "[requires]\nopenssl/3.1.4\n",
+	})
+	return root
+}
+
+func dependencyNames(t *testing.T, s *Scanner, opts ScanOptions) []string {
+	t.Helper()
+	result, err := s.ScanContext(context.Background(), opts)
+	require.NoError(t, err)
+	var names []string
+	for _, dep := range result.Dependencies {
+		names = append(names, dep.Name)
+	}
+	return names
+}
+
+func TestScanContext(t *testing.T) {
+	root := newTestProject(t)
+	s := NewScanner(Config{Logger: zap.NewNop()})
+
+	opts := ScanOptions{Root: root, Extractors: []string{"conan"}}
+	first, err := s.ScanContext(context.Background(), opts)
+	require.NoError(t, err)
+	second, err := s.ScanContext(context.Background(), opts)
+	require.NoError(t, err)
+
+	// 每次扫描返回新的结果, 不会累加上一次的依赖
+	assert.NotSame(t, first, second)
+	assert.Equal(t, 2, first.TotalDeps)
+	assert.Equal(t, 2, second.TotalDeps)
+	assert.Same(t, second, s.GetResults())
+	assert.Equal(t, root, second.ProjectPath)
+
+	assert.ElementsMatch(t, []string{"fmt"}, dependencyNames(t, s, ScanOptions{Root: root, Extractors: []string{"VCPKG"}}))
+	assert.ElementsMatch(t, []string{"zlib", "fmt"}, dependencyNames(t, s, ScanOptions{
+		Root:       root,
+		Extractors: []string{"conan", "vcpkg"},
+		Filters:    &extractor.ExtractorConfig{ExcludeFiles: []string{"lib/"}},
+	}))
+
+	_, err = s.ScanContext(context.Background(), ScanOptions{Root: root, Extractors: []string{"gradle"}})
+	assert.ErrorContains(t, err, "gradle")
+}
+
+func TestScanContext_Progress(t *testing.T) {
+	root := newTestProject(t)
+	s := NewScanner(Config{Logger: zap.NewNop()})
+
+	var events []ProgressEvent
+	_, err := s.ScanContext(context.Background(), ScanOptions{
+		Root:       root,
+		Extractors: []string{"conan", "vcpkg"},
+		Progress: func(e ProgressEvent) {
+			events = append(events, e)
+		},
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, events)
+
+	last := events[len(events)-1]
+	assert.Equal(t, 3, last.Discovered)
+	assert.Equal(t, 3, last.Processed)
+
+	files := make(map[string]string)
+	for i, e := range events {
+		if i > 0 {
+			assert.GreaterOrEqual(t, e.Discovered, events[i-1].Discovered)
+			assert.GreaterOrEqual(t, e.Processed, events[i-1].Processed)
+		}
+		assert.LessOrEqual(t, e.Processed, e.Discovered)
+		if e.CurrentFile != "" {
+			files[e.CurrentFile] = e.Extractor
+		}
+	}
+	assert.Len(t, files, 3)
+	assert.Equal(t, "vcpkg", files[filepath.Join(root, "app", "vcpkg.json")])
+}
+
+func TestScanContext_Cancel(t *testing.T) {
+	root := newTestProject(t)
+	s := NewScanner(Config{Logger: zap.NewNop(), MaxWorkers: 1})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := s.ScanContext(ctx, ScanOptions{Root: root})
+	assert.True(t, errors.Is(err, context.Canceled))
+
+	// 扫描过程中取消
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	result, err := s.ScanContext(ctx, ScanOptions{
+		Root: root,
+		Progress: func(e ProgressEvent) {
+			if e.Discovered == 1 {
+				cancel()
+			}
+		},
+	})
+	assert.Nil(t, result)
+	assert.True(t, errors.Is(err, context.Canceled))
+	// 取消的扫描不会覆盖已有结果
+	assert.Zero(t, s.GetResults().TotalDeps)
+}
+
+func TestScanContext_NestedGitmodules(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		".gitmodules": "[submodule \"zlib\"]\n\tpath = libs/zlib\n\turl = https://github.com/madler/zlib.git\n",
+		// 已检出的子模块, .git为目录, 其中的嵌套子模块由SubmoduleExtractor递归提取
+		"libs/zlib/.git/HEAD":                   "ref: refs/heads/master\n",
+		"libs/zlib/.gitmodules":                 "[submodule \"minizip\"]\n\tpath = contrib/minizip\n\turl = https://github.com/zlib-ng/minizip-ng.git\n",
+		"libs/zlib/contrib/minizip/.gitmodules": "[submodule \"deep\"]\n\tpath = deep\n\turl = https://example.org/deep.git\n",
+		// 未被声明为子模块的目录中的.gitmodules单独提取
+		"vendor/other/.gitmodules": "[submodule \"ext\"]\n\tpath = ext\n\turl = https://example.org/ext.git\n",
+	})
+
+	s := NewScanner(Config{Logger: zap.NewNop()})
+	events, err := s.Stream(context.Background(), ScanOptions{Root: root, Extractors: []string{"submodule"}})
+	require.NoError(t, err)
+
+	counts := make(map[string]int)
+	for _, event := range collectEvents(t, events) {
+		if event.Type == EventDependency {
+			counts[event.Dependency.Name]++
+		}
+	}
+	assert.Equal(t, map[string]int{"zlib": 1, "minizip": 1, "deep": 1, "ext": 1}, counts)
+}
+
+// newMirror 在mirrors/zlib创建本地镜像仓库: 打了v1.2.13标签的提交之后master上还有2个提交, 返回标签提交
+func newMirror(t *testing.T, mirrors string) plumbing.Hash {
+	t.Helper()
+	dir := filepath.Join(mirrors, "zlib")
+	repo, err := git.PlainInit(dir, false)
+	require.NoError(t, err)
+	wt, err := repo.Worktree()
+	require.NoError(t, err)
+	signature := &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()}
+	commit := func(content string) plumbing.Hash {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "zlib.h"), []byte(content), 0644))
+		_, err := wt.Add("zlib.h")
+		require.NoError(t, err)
+		hash, err := wt.Commit(content, &git.CommitOptions{Author: signature})
+		require.NoError(t, err)
+		return hash
+	}
+
+	tagged := commit("1.2.13")
+	_, err = repo.CreateTag("v1.2.13", tagged, nil)
+	require.NoError(t, err)
+	commit("1.3")
+	commit("1.3.1")
+	return tagged
+}
+
+// newSuperproject 创建包含未检出子模块third_party/zlib的上级仓库, 子模块固定在pinned提交
+func newSuperproject(t *testing.T, pinned plumbing.Hash, branch string) (string, *git.Repository) {
+	t.Helper()
+	root := t.TempDir()
+	repo, err := git.PlainInit(root, false)
+	require.NoError(t, err)
+	gitmodules := "[submodule \"zlib\"]\n\tpath = third_party/zlib\n\turl = https://github.com/madler/zlib.git\n"
+	if branch != "" {
+		gitmodules += "\tbranch = " + branch + "\n"
+	}
+	writeFiles(t, root, map[string]string{".gitmodules": gitmodules})
+
+	idx, err := repo.Storer.Index()
+	require.NoError(t, err)
+	entry := idx.Add("third_party/zlib")
+	entry.Mode = filemode.Submodule
+	entry.Hash = pinned
+	require.NoError(t, repo.Storer.SetIndex(idx))
+	return root, repo
+}
+
+func scanSubmodule(t *testing.T, opts ScanOptions) models.Dependency {
+	t.Helper()
+	s := NewScanner(Config{Logger: zap.NewNop()})
+	opts.Extractors = []string{"submodule"}
+	result, err := s.ScanContext(context.Background(), opts)
+	require.NoError(t, err)
+	require.Len(t, result.Dependencies, 1)
+	return result.Dependencies[0]
+}
+
+func TestScanContext_SubmoduleMirror(t *testing.T) {
+	mirrors := t.TempDir()
+	tagged := newMirror(t, mirrors)
+	root, _ := newSuperproject(t, tagged, "")
+
+	// 未配置镜像目录时无法推断未检出子模块的版本
+	dep := scanSubmodule(t, ScanOptions{Root: root})
+	assert.Equal(t, tagged.String(), dep.Commit)
+	assert.Empty(t, dep.Version)
+
+	// 扫描配置中的镜像目录传递给子模块提取器
+	dep = scanSubmodule(t, ScanOptions{Root: root, Filters: &extractor.ExtractorConfig{GitMirrorDirs: []string{mirrors}}})
+	assert.Equal(t, "1.2.13", dep.Version)
+	assert.Equal(t, "exact", dep.Metadata["version_confidence"])
+}
+
+func TestScanContext_SubmoduleDrift(t *testing.T) {
+	mirrors := t.TempDir()
+	tagged := newMirror(t, mirrors)
+	root, repo := newSuperproject(t, tagged, "master")
+	filters := &extractor.ExtractorConfig{GitMirrorDirs: []string{mirrors}}
+
+	// 子模块未检出时通过本地镜像计算与跟踪分支的差距
+	dep := scanSubmodule(t, ScanOptions{Root: root, Filters: filters})
+	require.NotNil(t, dep.Drift)
+	assert.Equal(t, "master", dep.Drift.Branch)
+	assert.Equal(t, 2, dep.Drift.CommitsBehind)
+	assert.NotContains(t, dep.Metadata, "drift_unavailable")
+
+	// 扫描Git版本时同样使用本地镜像
+	wt, err := repo.Worktree()
+	require.NoError(t, err)
+	_, err = wt.Add(".gitmodules")
+	require.NoError(t, err)
+	signature := &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()}
+	_, err = wt.Commit("add zlib", &git.CommitOptions{Author: signature})
+	require.NoError(t, err)
+	dep = scanSubmodule(t, ScanOptions{Root: root, Revision: "master", Filters: filters})
+	assert.Equal(t, "1.2.13", dep.Version)
+	require.NotNil(t, dep.Drift)
+	assert.Equal(t, 2, dep.Drift.CommitsBehind)
+
+	// 找不到子模块仓库时在元数据中说明漂移信息缺失, 而不是静默省略
+	dep = scanSubmodule(t, ScanOptions{Root: root})
+	assert.Nil(t, dep.Drift)
+	assert.Contains(t, dep.Metadata, "drift_unavailable")
+}
+
+func TestScanContext_TreeExtractorsFiltered(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"third_party/zlib/zlib.h":    "#ifndef ZLIB_H\n#define ZLIB_H\n#endif\n",
+		"third_party/zlib/zconf.h":   "",
+		"third_party/zlib/inflate.c": "",
+		"third_party/curl/easy.c":    "#include <curl/curl.h>\n",
+		"src/main.c":                 "#include <openssl/ssl.h>\n",
+		"build/gen.c":                "#include <png.h>\n",
+	})
+	s := NewScanner(Config{Logger: zap.NewNop()})
+	opts := ScanOptions{Root: root, Extractors: []string{"vendored", "include"}}
+
+	// 默认规则跳过build/
+	assert.ElementsMatch(t, []string{"zlib", "libcurl", "openssl"}, dependencyNames(t, s, opts))
+
+	opts.Filters = &extractor.ExtractorConfig{ExcludeFiles: []string{"third_party/"}}
+	assert.ElementsMatch(t, []string{"openssl"}, dependencyNames(t, s, opts))
+
+	// .ccscannerignore 同样生效
+	writeFiles(t, root, map[string]string{".ccscannerignore": "third_party/\n"})
+	opts.Filters = nil
+	assert.ElementsMatch(t, []string{"openssl"}, dependencyNames(t, s, opts))
+}
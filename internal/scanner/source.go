@@ -0,0 +1,119 @@
+package scanner
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/lkpsg/ccscanner/internal/archive"
+	"github.com/lkpsg/ccscanner/internal/extractor"
+	"github.com/lkpsg/ccscanner/internal/gitfs"
+	"github.com/lkpsg/ccscanner/pkg/models"
+	"go.uber.org/zap"
+)
+
+// source 扫描的文件来源: 本地目录, 或压缩包、Git提交等虚拟文件系统
+type source struct {
+	root    string // 扫描目标(目录、压缩包或Git仓库路径)
+	fsys    fs.FS  // 遍历使用的文件系统, 路径相对扫描目标
+	virtual bool   // 文件不在本地磁盘上, 提取器通过fsys读取
+	commit  string // 扫描Git版本时解析得到的提交哈希
+
+	displayPath func(name string) string // 虚拟文件在结果中显示的路径
+}
+
+// openSource 打开扫描目标
+//
+// 指定revision时从Git对象库读取该版本的文件树, 压缩包在内存中读取为虚拟文件系统, 其余按本地目录遍历。
+func openSource(root, revision string, logger *zap.Logger) (*source, error) {
+	if root == "" {
+		return nil, fmt.Errorf("未指定扫描目标")
+	}
+	if revision != "" {
+		fsys, err := gitfs.Open(root, revision)
+		if err != nil {
+			return nil, fmt.Errorf("打开Git版本失败: %v", err)
+		}
+		return &source{root: root, fsys: fsys, virtual: true, commit: fsys.Commit(), displayPath: fsys.DisplayPath}, nil
+	}
+	if info, err := os.Stat(root); err != nil || !info.Mode().IsRegular() || !archive.IsArchive(root) {
+		return &source{root: root, fsys: os.DirFS(root)}, nil
+	}
+
+	fsys, err := archive.Open(root, archive.DefaultLimits)
+	if err != nil {
+		return nil, fmt.Errorf("打开压缩包失败: %v", err)
+	}
+	for _, skipped := range fsys.Skipped {
+		logger.Warn("跳过压缩包条目",
+			zap.String("archive", root),
+			zap.String("entry", skipped.Name),
+			zap.String("reason", skipped.Reason),
+		)
+	}
+	return &source{root: root, fsys: fsys, virtual: true, displayPath: fsys.DisplayPath}, nil
+}
+
+// path 返回提取器读取文件使用的路径: 本地目录中为绝对路径, 虚拟文件系统中为fs路径
+func (s *source) path(name string) string {
+	if s.virtual {
+		return name
+	}
+	return filepath.Join(s.root, filepath.FromSlash(name))
+}
+
+// display 返回文件在结果、进度和诊断中显示的路径
+func (s *source) display(name string) string {
+	if s.virtual {
+		return s.displayPath(name)
+	}
+	return s.path(name)
+}
+
+// prepare 让提取器从扫描来源读取文件, 提取器不支持虚拟文件系统时返回false
+func (s *source) prepare(ext extractor.Extractor) bool {
+	if !s.virtual {
+		return true
+	}
+	fsExt, ok := ext.(extractor.FSExtractor)
+	if !ok {
+		return false
+	}
+	fsExt.SetFS(s.fsys)
+	return true
+}
+
+// relabel 将提取器输出中的虚拟文件路径替换为显示路径(如 vendor.tar.gz!/src/vcpkg.json、v2.3.0:src/vcpkg.json)
+func (s *source) relabel(deps []models.Dependency) {
+	if !s.virtual {
+		return
+	}
+	for i := range deps {
+		if deps[i].ConfigFile != "" {
+			deps[i].ConfigFile = s.displayPath(deps[i].ConfigFile)
+		}
+		if p := deps[i].Provenance; p != nil && p.File != "" {
+			p.File = s.displayPath(p.File)
+		}
+	}
+}
+
+/*
+使用示例:
+
+src, err := openSource("/drops/vendor-src.tar.gz", "", logger)
+if err != nil {
+	log.Fatal(err)
+}
+fs.WalkDir(src.fsys, ".", func(name string, d fs.DirEntry, err error) error {
+	if err == nil && d.Name() == "vcpkg.json" {
+		ext := extractor.NewVcpkgExtractor(src.path(name))
+		if src.prepare(ext) {
+			deps, _ := ext.Extract()
+			src.relabel(deps)
+		}
+	}
+	return err
+})
+*/
@@ -0,0 +1,160 @@
+package scanner
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// writeTarGz 按顺序写入tar.gz压缩包
+func writeTarGz(t *testing.T, file string, entries [][2]string) {
+	t.Helper()
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+	for _, e := range entries {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: e[0], Mode: 0644, Size: int64(len(e[1])), Typeflag: tar.TypeReg}))
+		_, err := tw.Write([]byte(e[1]))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gw.Close())
+	require.NoError(t, os.WriteFile(file, buf.Bytes(), 0644))
+}
+
+func zipData(t *testing.T, files map[string]string) string {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	return buf.String()
+}
+
+func TestScanContext_Archive(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "vendor-src.tar.gz")
+	writeTarGz(t, file, [][2]string{
+		{"src/conanfile.txt", "[requires]\nzlib/1.3\n"},
+		{"src/app/vcpkg.json", `{"name": "app", "dependencies": [{"name": "fmt"}]}`},
+		{"src/.gitignore", "generated/\n"},
+		{"src/generated/conanfile.txt", "[requires]\nboost/1.83.0\n"},
+		{"src/deps/libfoo.zip", zipData(t, map[string]string{"libfoo/conanfile.txt": "[requires]\nopenssl/3.1.4\n"})},
+		{"../escape/conanfile.txt", "[requires]\nbzip2/1.0.8\n"},
+	})
+
+	s := NewScanner(Config{Logger: zap.NewNop()})
+	result, err := s.ScanContext(context.Background(), ScanOptions{Root: file})
+	require.NoError(t, err)
+	assert.Equal(t, file, result.ProjectPath)
+	assert.Empty(t, result.Errors)
+
+	files := make(map[string]string)
+	for _, dep := range result.Dependencies {
+		files[dep.Name] = dep.ConfigFile
+	}
+	assert.Equal(t, map[string]string{
+		"zlib":    file + "!/src/conanfile.txt",
+		"fmt":     file + "!/src/app/vcpkg.json",
+		"openssl": file + "!/src/deps/libfoo.zip!/libfoo/conanfile.txt",
+	}, files)
+
+	for _, dep := range result.Dependencies {
+		if dep.Name == "zlib" {
+			require.NotNil(t, dep.Provenance)
+			assert.Equal(t, file+"!/src/conanfile.txt:2:1", dep.Provenance.String())
+		}
+	}
+
+	// 损坏或超出资源限制的压缩包使扫描失败
+	broken := filepath.Join(t.TempDir(), "broken.tar.gz")
+	require.NoError(t, os.WriteFile(broken, []byte("not gzip"), 0644))
+	_, err = s.ScanContext(context.Background(), ScanOptions{Root: broken})
+	assert.ErrorContains(t, err, "打开压缩包失败")
+}
+
+func TestScanContext_Revision(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	require.NoError(t, err)
+	wt, err := repo.Worktree()
+	require.NoError(t, err)
+	signature := &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()}
+	commit := func(files map[string]string) plumbing.Hash {
+		for name, content := range files {
+			file := filepath.Join(dir, filepath.FromSlash(name))
+			require.NoError(t, os.MkdirAll(filepath.Dir(file), 0755))
+			require.NoError(t, os.WriteFile(file, []byte(content), 0644))
+		}
+		require.NoError(t, wt.AddWithOptions(&git.AddOptions{All: true}))
+		hash, err := wt.Commit("update", &git.CommitOptions{Author: signature})
+		require.NoError(t, err)
+		return hash
+	}
+
+	release := commit(map[string]string{
+		"conanfile.txt": "[requires]\nzlib/1.3\n",
+		"src/net.c":     "#include <openssl/ssl.h>\n",
+	})
+	_, err = repo.CreateTag("v2.3.0", release, &git.CreateTagOptions{Tagger: signature, Message: "v2.3.0"})
+	require.NoError(t, err)
+	commit(map[string]string{"conanfile.txt": "[requires]\nfmt/10.1.1\n"})
+	// 未提交的文件不在扫描范围内
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "extra"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "extra", "conanfile.txt"), []byte("[requires]\nbzip2/1.0.8\n"), 0644))
+
+	s := NewScanner(Config{Logger: zap.NewNop()})
+	opts := ScanOptions{Root: dir, Revision: "v2.3.0", Extractors: []string{"conan", "include"}}
+	result, err := s.ScanContext(context.Background(), opts)
+	require.NoError(t, err)
+	assert.Equal(t, "v2.3.0", result.Revision)
+	assert.Equal(t, release.String(), result.Commit)
+	assert.Empty(t, result.Errors)
+
+	files := make(map[string]string)
+	for _, dep := range result.Dependencies {
+		files[dep.Name] = dep.ConfigFile
+	}
+	assert.Equal(t, map[string]string{
+		"zlib":    "v2.3.0:conanfile.txt",
+		"openssl": "v2.3.0:",
+	}, files)
+
+	// 流式扫描在统计信息中记录提交
+	events, err := s.Stream(context.Background(), opts)
+	require.NoError(t, err)
+	var summary *Summary
+	for event := range events {
+		if event.Type == EventSummary {
+			summary = event.Summary
+		}
+	}
+	require.NotNil(t, summary)
+	assert.Equal(t, release.String(), summary.Commit)
+
+	// 扫描工作区时不记录提交
+	result, err = s.ScanContext(context.Background(), ScanOptions{Root: dir, Extractors: []string{"conan"}})
+	require.NoError(t, err)
+	assert.Empty(t, result.Commit)
+	assert.Len(t, result.Dependencies, 2)
+
+	_, err = s.ScanContext(context.Background(), ScanOptions{Root: dir, Revision: "v9.9.9"})
+	assert.ErrorContains(t, err, "打开Git版本失败")
+}
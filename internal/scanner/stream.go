@@ -0,0 +1,163 @@
+package scanner
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/lkpsg/ccscanner/pkg/models"
+	"go.uber.org/zap"
+)
+
+// EventType 扫描事件类型
+type EventType string
+
+const (
+	EventDependency EventType = "dependency" // 发现依赖
+	EventDiagnostic EventType = "diagnostic" // 提取错误等诊断信息
+	EventSummary    EventType = "summary"    // 扫描结束时的统计, 总是最后一个事件
+)
+
+// 诊断级别
+const (
+	DiagnosticError = "error" // 单个文件提取失败, 扫描继续
+	DiagnosticFatal = "fatal" // 扫描中止
+)
+
+// Event 流式扫描事件, 按类型只设置对应的字段
+type Event struct {
+	Type       EventType          `json:"type"`
+	Dependency *models.Dependency `json:"dependency,omitempty"`
+	Diagnostic *Diagnostic        `json:"diagnostic,omitempty"`
+	Summary    *Summary           `json:"summary,omitempty"`
+}
+
+// Diagnostic 扫描过程中的诊断信息
+type Diagnostic struct {
+	Level   string `json:"level"`
+	File    string `json:"file,omitempty"`
+	Message string `json:"message"`
+}
+
+// Summary 流式扫描的统计信息
+type Summary struct {
+	ProjectPath       string  `json:"projectPath"`
+	Revision          string  `json:"revision,omitempty"` // 扫描的Git版本
+	Commit            string  `json:"commit,omitempty"`   // 扫描的Git提交哈希
+	TotalDeps         int     `json:"totalDeps"`          // 已发送的依赖数
+	FilteredDeps      int     `json:"filteredDeps"`       // 低于最低置信度被过滤的依赖数
+	ScopeFilteredDeps int     `json:"scopeFilteredDeps"`  // 按依赖范围被过滤的依赖数
+	Errors            int     `json:"errors"`             // 诊断错误数
+	ScanDuration      float64 `json:"scanDuration"`       // 扫描耗时(秒)
+}
+
+// Stream 以事件流的形式执行扫描, 依赖在提取器返回后立即发送, 不等待整个扫描结束
+//
+// 事件通道无缓冲, 调用方接收变慢时提取任务随之阻塞(背压)。为了不在内存中保留全部发现,
+// 流式扫描不合并不同提取器的重复发现, 也不根据依赖关系图区分直接/传递依赖, 置信度和范围
+// 过滤对每个发现单独生效。扫描结束、出错或取消后通道关闭(取消时等待正在运行的提取器返回),
+// 调用方应一直接收到通道关闭, 或在停止接收前取消ctx。
+func (s *Scanner) Stream(ctx context.Context, opts ScanOptions) (<-chan Event, error) {
+	run, err := s.newRun(opts)
+	if err != nil {
+		return nil, err
+	}
+	events := make(chan Event)
+	run.ctx = ctx
+	run.events = events
+
+	go func() {
+		// 取消时execute不等待仍在运行的提取任务, 关闭通道前需等待它们退出, 避免向已关闭的通道发送
+		defer func() {
+			if run.wait != nil {
+				run.wait()
+			}
+			close(events)
+		}()
+
+		startTime := time.Now()
+		if err := s.execute(ctx, run); err != nil {
+			// 取消时调用方已不再接收事件
+			if ctx.Err() == nil {
+				run.send(Event{Type: EventDiagnostic, Diagnostic: &Diagnostic{
+					Level:   DiagnosticFatal,
+					Message: err.Error(),
+				}})
+			}
+			return
+		}
+
+		summary := run.summarize(time.Since(startTime))
+		s.config.Logger.Info("流式扫描完成",
+			zap.Int("total_deps", summary.TotalDeps),
+			zap.Int("filtered_deps", summary.FilteredDeps),
+			zap.Int("scope_filtered_deps", summary.ScopeFilteredDeps),
+			zap.Int("errors", summary.Errors),
+			zap.Float64("duration", summary.ScanDuration),
+		)
+		run.send(Event{Type: EventSummary, Summary: summary})
+	}()
+
+	return events, nil
+}
+
+// summarize 生成流式扫描的统计信息
+func (r *scanRun) summarize(duration time.Duration) *Summary {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return &Summary{
+		ProjectPath:       r.config.TargetDir,
+		Revision:          r.result.Revision,
+		Commit:            r.result.Commit,
+		TotalDeps:         r.streamed,
+		FilteredDeps:      r.result.FilteredDeps,
+		ScopeFilteredDeps: r.result.ScopeFilteredDeps,
+		Errors:            len(r.result.Errors),
+		ScanDuration:      duration.Seconds(),
+	}
+}
+
+// WriteNDJSON 将事件逐行写为JSON(NDJSON), 每写一行刷新一次(w实现Flush时, 如http.ResponseWriter)
+// 写入失败时立即返回, 调用方应取消扫描的ctx以结束事件流
+func WriteNDJSON(w io.Writer, events <-chan Event) error {
+	encoder := json.NewEncoder(w)
+	flusher, _ := w.(interface{ Flush() })
+	for event := range events {
+		if err := encoder.Encode(event); err != nil {
+			return err
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+	return nil
+}
+
+/*
+使用示例:
+
+ctx, cancel := context.WithCancel(context.Background())
+defer cancel()
+
+events, err := scanner.Stream(ctx, ScanOptions{Root: "/path/to/monorepo"})
+if err != nil {
+	log.Fatal(err)
+}
+for event := range events {
+	switch event.Type {
+	case EventDependency:
+		fmt.Println(event.Dependency.Name, event.Dependency.Version)
+	case EventDiagnostic:
+		fmt.Println(event.Diagnostic.Level, event.Diagnostic.Message)
+	case EventSummary:
+		fmt.Println("total:", event.Summary.TotalDeps)
+	}
+}
+
+// 或直接输出NDJSON
+if err := WriteNDJSON(os.Stdout, events); err != nil {
+	cancel()
+}
+*/
@@ -0,0 +1,151 @@
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func collectEvents(t *testing.T, events <-chan Event) []Event {
+	t.Helper()
+	var collected []Event
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return collected
+			}
+			collected = append(collected, event)
+		case <-timeout:
+			t.Fatal("event stream not closed")
+		}
+	}
+}
+
+func TestStream(t *testing.T) {
+	root := newTestProject(t)
+	writeFiles(t, root, map[string]string{"broken/vcpkg.json": "{"})
+	s := NewScanner(Config{Logger: zap.NewNop()})
+
+	events, err := s.Stream(context.Background(), ScanOptions{Root: root, Extractors: []string{"conan", "vcpkg"}})
+	require.NoError(t, err)
+	collected := collectEvents(t, events)
+	require.NotEmpty(t, collected)
+
+	var names []string
+	var diagnostics []*Diagnostic
+	for _, event := range collected[:len(collected)-1] {
+		switch event.Type {
+		case EventDependency:
+			names = append(names, event.Dependency.Name)
+		case EventDiagnostic:
+			diagnostics = append(diagnostics, event.Diagnostic)
+		default:
+			t.Errorf("unexpected event %s", event.Type)
+		}
+	}
+	assert.ElementsMatch(t, []string{"zlib", "openssl", "fmt"}, names)
+	require.Len(t, diagnostics, 1)
+	assert.Equal(t, DiagnosticError, diagnostics[0].Level)
+	assert.Equal(t, filepath.Join(root, "broken", "vcpkg.json"), diagnostics[0].File)
+
+	last := collected[len(collected)-1]
+	require.Equal(t, EventSummary, last.Type)
+	assert.Equal(t, 3, last.Summary.TotalDeps)
+	assert.Equal(t, 1, last.Summary.Errors)
+	assert.Equal(t, root, last.Summary.ProjectPath)
+
+	// 流式扫描不影响GetResults
+	assert.Zero(t, s.GetResults().TotalDeps)
+}
+
+func TestStream_Unmerged(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"conanfile.txt":  "[requires]\nzlib/1.3\n",
+		"app/vcpkg.json": `{"name": "app", "dependencies": [{"name": "zlib"}]}`,
+	})
+	s := NewScanner(Config{Logger: zap.NewNop()})
+	opts := ScanOptions{Root: root, Extractors: []string{"conan", "vcpkg"}}
+
+	// 流式扫描逐个发送发现: 同一依赖的两次发现分别发送, 且不区分直接/传递依赖
+	events, err := s.Stream(context.Background(), opts)
+	require.NoError(t, err)
+	var streamed []string
+	for _, event := range collectEvents(t, events) {
+		if event.Type == EventDependency {
+			streamed = append(streamed, event.Dependency.ConfigFileType)
+			assert.Equal(t, "zlib", event.Dependency.Name)
+			assert.Empty(t, event.Dependency.Relationship)
+		}
+	}
+	assert.ElementsMatch(t, []string{"conanfile.txt", "vcpkg.json"}, streamed)
+
+	// ScanContext 返回合并并分类后的结果
+	result, err := s.ScanContext(context.Background(), opts)
+	require.NoError(t, err)
+	require.Len(t, result.Dependencies, 1)
+	assert.Equal(t, "zlib", result.Dependencies[0].Name)
+	assert.NotEmpty(t, result.Dependencies[0].Relationship)
+}
+
+func TestStream_Fatal(t *testing.T) {
+	s := NewScanner(Config{Logger: zap.NewNop()})
+	events, err := s.Stream(context.Background(), ScanOptions{Root: filepath.Join(t.TempDir(), "missing")})
+	require.NoError(t, err)
+
+	collected := collectEvents(t, events)
+	require.Len(t, collected, 1)
+	assert.Equal(t, EventDiagnostic, collected[0].Type)
+	assert.Equal(t, DiagnosticFatal, collected[0].Diagnostic.Level)
+
+	_, err = s.Stream(context.Background(), ScanOptions{Extractors: []string{"unknown"}})
+	assert.Error(t, err)
+}
+
+func TestStream_Cancel(t *testing.T) {
+	root := newTestProject(t)
+	s := NewScanner(Config{Logger: zap.NewNop(), MaxWorkers: 1})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events, err := s.Stream(ctx, ScanOptions{Root: root, Extractors: []string{"conan", "vcpkg"}})
+	require.NoError(t, err)
+
+	// 接收一个事件后不再接收, 提取任务因背压阻塞, 取消后通道关闭
+	first := <-events
+	assert.Equal(t, EventDependency, first.Type)
+	cancel()
+	rest := collectEvents(t, events)
+	for _, event := range rest {
+		assert.NotEqual(t, EventSummary, event.Type)
+	}
+}
+
+func TestWriteNDJSON(t *testing.T) {
+	root := newTestProject(t)
+	s := NewScanner(Config{Logger: zap.NewNop()})
+
+	events, err := s.Stream(context.Background(), ScanOptions{Root: root, Extractors: []string{"conan"}})
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteNDJSON(&buf, events))
+
+	var types []EventType
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var event Event
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+		types = append(types, event.Type)
+	}
+	assert.Equal(t, []EventType{EventDependency, EventDependency, EventSummary}, types)
+}
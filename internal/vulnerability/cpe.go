@@ -0,0 +1,359 @@
+package vulnerability
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// CPE CPE 2.3 名称(格式化字符串绑定)
+type CPE struct {
+	Part      string // a(应用)、o(操作系统)、h(硬件)
+	Vendor    string // 厂商
+	Product   string // 产品
+	Version   string // 版本
+	Update    string // 更新
+	Edition   string // 版本类型
+	Language  string // 语言
+	SWEdition string // 软件版本类型
+	TargetSW  string // 目标软件环境
+	TargetHW  string // 目标硬件环境
+	Other     string // 其他
+}
+
+const (
+	cpePrefix = "cpe:2.3:"
+	cpeAny    = "*" // ANY: 匹配任意值
+	cpeNA     = "-" // NA: 不适用
+)
+
+// NewCPE 创建应用类型的CPE, 其余属性为ANY
+func NewCPE(vendor, product, version string) CPE {
+	if version == "" {
+		version = cpeAny
+	}
+	return CPE{
+		Part: "a", Vendor: escapeCPE(vendor), Product: escapeCPE(product), Version: escapeCPE(version),
+		Update: cpeAny, Edition: cpeAny, Language: cpeAny, SWEdition: cpeAny,
+		TargetSW: cpeAny, TargetHW: cpeAny, Other: cpeAny,
+	}
+}
+
+// ParseCPE 解析CPE 2.3格式化字符串(cpe:2.3:part:vendor:product:version:...)
+// 省略的尾部属性视为ANY
+func ParseCPE(s string) (CPE, error) {
+	if !strings.HasPrefix(strings.ToLower(s), cpePrefix) {
+		return CPE{}, fmt.Errorf("invalid CPE %q: missing %s prefix", s, cpePrefix)
+	}
+	fields := splitCPE(s[len(cpePrefix):])
+	if len(fields) < 3 || len(fields) > 11 {
+		return CPE{}, fmt.Errorf("invalid CPE %q: expected 11 components, got %d", s, len(fields))
+	}
+	for len(fields) < 11 {
+		fields = append(fields, cpeAny)
+	}
+	for i, field := range fields {
+		if field == "" {
+			fields[i] = cpeAny
+		} else {
+			fields[i] = strings.ToLower(field)
+		}
+	}
+	if !strings.Contains("aoh*", fields[0]) || len(fields[0]) != 1 {
+		return CPE{}, fmt.Errorf("invalid CPE %q: unknown part %q", s, fields[0])
+	}
+	return CPE{
+		Part: fields[0], Vendor: fields[1], Product: fields[2], Version: fields[3],
+		Update: fields[4], Edition: fields[5], Language: fields[6], SWEdition: fields[7],
+		TargetSW: fields[8], TargetHW: fields[9], Other: fields[10],
+	}, nil
+}
+
+// String 返回CPE 2.3格式化字符串
+func (c CPE) String() string {
+	return cpePrefix + strings.Join(c.fields(), ":")
+}
+
+// fields 按规范顺序返回各属性
+func (c CPE) fields() []string {
+	fields := []string{c.Part, c.Vendor, c.Product, c.Version, c.Update, c.Edition,
+		c.Language, c.SWEdition, c.TargetSW, c.TargetHW, c.Other}
+	for i, field := range fields {
+		if field == "" {
+			fields[i] = cpeAny
+		}
+	}
+	return fields
+}
+
+// VendorProduct 返回 vendor:product, 用于按产品索引漏洞
+func (c CPE) VendorProduct() string {
+	return c.Vendor + ":" + c.Product
+}
+
+// CPEMatch NVD格式的CPE匹配条件
+type CPEMatch struct {
+	Criteria              string `json:"criteria"`                        // CPE匹配串
+	Vulnerable            bool   `json:"vulnerable"`                      // 是否为受影响的配置
+	VersionStartIncluding string `json:"versionStartIncluding,omitempty"` // 起始版本(含)
+	VersionStartExcluding string `json:"versionStartExcluding,omitempty"` // 起始版本(不含)
+	VersionEndIncluding   string `json:"versionEndIncluding,omitempty"`   // 结束版本(含)
+	VersionEndExcluding   string `json:"versionEndExcluding,omitempty"`   // 结束版本(不含)
+}
+
+// hasRange 是否带有版本范围
+func (m CPEMatch) hasRange() bool {
+	return m.VersionStartIncluding != "" || m.VersionStartExcluding != "" ||
+		m.VersionEndIncluding != "" || m.VersionEndExcluding != ""
+}
+
+// Matches 判断目标CPE是否满足匹配条件
+// 目标版本未知(ANY)时只匹配不限版本的条件, 避免把所有版本范围都判为受影响
+func (m CPEMatch) Matches(target CPE) (bool, error) {
+	criteria, err := ParseCPE(m.Criteria)
+	if err != nil {
+		return false, err
+	}
+
+	cf, tf := criteria.fields(), target.fields()
+	for i := range cf {
+		if i == 3 {
+			continue // 版本单独处理
+		}
+		if !matchCPEValue(cf[i], tf[i]) {
+			return false, nil
+		}
+	}
+
+	version := target.Version
+	if version == cpeAny || version == cpeNA {
+		if m.hasRange() {
+			return false, nil
+		}
+		return criteria.Version == cpeAny || criteria.Version == version, nil
+	}
+	if criteria.Version != cpeAny && !matchCPEValue(criteria.Version, version) {
+		return false, nil
+	}
+
+	version = unescapeCPE(version)
+	if m.VersionStartIncluding != "" && CompareVersions(version, m.VersionStartIncluding) < 0 {
+		return false, nil
+	}
+	if m.VersionStartExcluding != "" && CompareVersions(version, m.VersionStartExcluding) <= 0 {
+		return false, nil
+	}
+	if m.VersionEndIncluding != "" && CompareVersions(version, m.VersionEndIncluding) > 0 {
+		return false, nil
+	}
+	if m.VersionEndExcluding != "" && CompareVersions(version, m.VersionEndExcluding) >= 0 {
+		return false, nil
+	}
+	return true, nil
+}
+
+// MatchCPEs 判断任一候选CPE是否命中任一受影响的匹配条件, 返回命中的条件
+func MatchCPEs(criteria []CPEMatch, candidates []CPE) (*CPEMatch, error) {
+	for i := range criteria {
+		if !criteria[i].Vulnerable {
+			continue
+		}
+		for _, candidate := range candidates {
+			ok, err := criteria[i].Matches(candidate)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				return &criteria[i], nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// matchCPEValue 比较单个属性, 条件中未转义的 * 和 ? 作为通配符
+func matchCPEValue(pattern, value string) bool {
+	if pattern == cpeAny {
+		return true
+	}
+	if pattern == cpeNA || value == cpeNA {
+		return pattern == value
+	}
+	if value == cpeAny {
+		return false
+	}
+	return globCPE(pattern, value)
+}
+
+// globCPE 带通配符的匹配(* 匹配任意长度, ? 匹配单个字符)
+func globCPE(pattern, value string) bool {
+	if pattern == "" {
+		return value == ""
+	}
+	switch pattern[0] {
+	case '*':
+		for i := 0; i <= len(value); i++ {
+			if globCPE(pattern[1:], value[i:]) {
+				return true
+			}
+		}
+		return false
+	case '?':
+		return value != "" && globCPE(pattern[1:], value[1:])
+	case '\\':
+		if len(pattern) > 1 {
+			return len(value) > 1 && value[0] == '\\' && value[1] == pattern[1] && globCPE(pattern[2:], value[2:])
+		}
+	}
+	return value != "" && value[0] == pattern[0] && globCPE(pattern[1:], value[1:])
+}
+
+// splitCPE 按未转义的冒号拆分
+func splitCPE(s string) []string {
+	var fields []string
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && i+1 < len(s):
+			b.WriteByte(s[i])
+			b.WriteByte(s[i+1])
+			i++
+		case s[i] == ':':
+			fields = append(fields, b.String())
+			b.Reset()
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return append(fields, b.String())
+}
+
+// escapeCPE 转义属性值中的特殊字符(字母数字和 . - _ 以外), 并把空白替换为下划线
+func escapeCPE(value string) string {
+	if value == cpeAny || value == cpeNA {
+		return value
+	}
+	var b strings.Builder
+	for _, r := range strings.ToLower(strings.TrimSpace(value)) {
+		switch {
+		case r == ' ':
+			b.WriteByte('_')
+		case r < 0x80 && (r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '_' || r == '.' || r == '-'):
+			b.WriteRune(r)
+		case r < 0x80:
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// unescapeCPE 去掉属性值中的转义符
+func unescapeCPE(value string) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] == '\\' && i+1 < len(value) {
+			i++
+		}
+		b.WriteByte(value[i])
+	}
+	return b.String()
+}
+
+// CompareVersions 比较两个版本号, 返回 -1、0 或 1
+// 按数字段和字母段逐段比较, 支持 1.1.1k、2.0.0-rc1 这类非语义化版本
+func CompareVersions(a, b string) int {
+	ta, tb := versionTokens(a), versionTokens(b)
+	for i := 0; i < len(ta) && i < len(tb); i++ {
+		if c := compareVersionToken(ta[i], tb[i]); c != 0 {
+			return c
+		}
+	}
+	switch {
+	case len(ta) == len(tb):
+		return 0
+	case len(ta) > len(tb):
+		return extraTokenOrder(ta[len(tb):])
+	default:
+		return -extraTokenOrder(tb[len(ta):])
+	}
+}
+
+// versionTokens 把版本号拆分为数字段和字母段
+func versionTokens(version string) []string {
+	version = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(version)), "v")
+	var tokens []string
+	start := -1
+	isDigit := func(c byte) bool { return c >= '0' && c <= '9' }
+	isAlpha := func(c byte) bool { return c >= 'a' && c <= 'z' }
+	for i := 0; i <= len(version); i++ {
+		if start >= 0 && (i == len(version) || isDigit(version[i]) != isDigit(version[start]) ||
+			!isDigit(version[i]) && !isAlpha(version[i])) {
+			tokens = append(tokens, version[start:i])
+			start = -1
+		}
+		if i < len(version) && start < 0 && (isDigit(version[i]) || isAlpha(version[i])) {
+			start = i
+		}
+	}
+	return tokens
+}
+
+// compareVersionToken 比较单个版本段, 数字段大于字母段
+func compareVersionToken(a, b string) int {
+	na, errA := strconv.ParseUint(a, 10, 64)
+	nb, errB := strconv.ParseUint(b, 10, 64)
+	switch {
+	case errA == nil && errB == nil:
+		if na != nb {
+			if na < nb {
+				return -1
+			}
+			return 1
+		}
+		return 0
+	case errA == nil:
+		return 1
+	case errB == nil:
+		return -1
+	}
+	return strings.Compare(a, b)
+}
+
+// extraTokenOrder 根据较长版本多出的部分决定大小
+// 多出的全是0时相等(1.0 与 1.0.0), 预发布标记(alpha、beta、rc等)表示更早的版本,
+// 其他(如 1.1.1k 的 k)表示更新的版本
+func extraTokenOrder(extra []string) int {
+	allZero := true
+	for _, token := range extra {
+		if strings.Trim(token, "0") != "" {
+			allZero = false
+			break
+		}
+	}
+	if allZero {
+		return 0
+	}
+	for _, pre := range []string{"alpha", "beta", "pre", "rc", "dev"} {
+		if extra[0] == pre {
+			return -1
+		}
+	}
+	return 1
+}
+
+/*
+使用示例:
+
+match := CPEMatch{
+	Criteria:            "cpe:2.3:a:zlib:zlib:*:*:*:*:*:*:*:*",
+	Vulnerable:          true,
+	VersionEndExcluding: "1.2.12",
+}
+ok, _ := match.Matches(NewCPE("zlib", "zlib", "1.2.11"))
+fmt.Println(ok) // true
+
+fmt.Println(CompareVersions("1.1.1k", "1.1.1")) // 1
+*/
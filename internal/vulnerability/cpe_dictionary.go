@@ -0,0 +1,121 @@
+package vulnerability
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/lkpsg/ccscanner/internal/identity"
+	"github.com/lkpsg/ccscanner/pkg/models"
+)
+
+// cpeDictionaryData 内置的包名到CPE产品的映射, 以规范ID为键
+//
+//go:embed data/cpe_dictionary.json
+var cpeDictionaryData []byte
+
+// cpeDictionaryEntry CPE字典条目
+type cpeDictionaryEntry struct {
+	Name     string   `json:"name"`     // 依赖的规范ID或名称
+	Products []string `json:"products"` // vendor:product 列表
+}
+
+var (
+	cpeDictionary   = make(map[string][]string)
+	cpeDictionaryMu sync.RWMutex
+)
+
+func init() {
+	if err := loadCPEDictionary(cpeDictionaryData); err != nil {
+		panic(fmt.Sprintf("invalid embedded CPE dictionary: %v", err))
+	}
+}
+
+// LoadCPEDictionary 从文件加载额外的CPE映射, 同名条目会被替换
+func LoadCPEDictionary(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read CPE dictionary: %v", err)
+	}
+	return loadCPEDictionary(data)
+}
+
+// loadCPEDictionary 解析并合并CPE映射
+func loadCPEDictionary(data []byte) error {
+	var entries []cpeDictionaryEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		for _, product := range entry.Products {
+			if vendor, name, ok := strings.Cut(product, ":"); !ok || vendor == "" || name == "" {
+				return fmt.Errorf("%s: invalid product %q, expected vendor:product", entry.Name, product)
+			}
+		}
+	}
+
+	cpeDictionaryMu.Lock()
+	defer cpeDictionaryMu.Unlock()
+	for _, entry := range entries {
+		cpeDictionary[strings.ToLower(entry.Name)] = entry.Products
+	}
+	return nil
+}
+
+// CandidateCPEs 根据CPE字典为依赖生成候选CPE, 版本未知时为ANY
+// 依次使用规范ID和原始名称查找, 未收录的依赖返回空。
+// 字典收录的是C/C++库, npm、PyPI等生态中的同名包不按原始名称查找
+func CandidateCPEs(dep *models.Dependency) []CPE {
+	cpeDictionaryMu.RLock()
+	products, ok := cpeDictionary[strings.ToLower(dep.Key())]
+	if !ok && identity.Ecosystem(dep) == "" {
+		products = cpeDictionary[strings.ToLower(dep.Name)]
+	}
+	cpeDictionaryMu.RUnlock()
+
+	cpes := make([]CPE, 0, len(products))
+	for _, product := range products {
+		vendor, name, _ := strings.Cut(product, ":")
+		cpes = append(cpes, NewCPE(vendor, name, dep.Version))
+	}
+	return cpes
+}
+
+// AssignCPEs 为依赖填充候选CPE名称, 已有CPE的依赖保持不变
+func AssignCPEs(deps []*models.Dependency) {
+	for _, dep := range deps {
+		if len(dep.CPEs) > 0 {
+			continue
+		}
+		for _, cpe := range CandidateCPEs(dep) {
+			dep.CPEs = append(dep.CPEs, cpe.String())
+		}
+	}
+}
+
+// dependencyCPEs 返回依赖的CPE列表, 优先使用已填充的值
+func dependencyCPEs(dep *models.Dependency) []CPE {
+	if len(dep.CPEs) == 0 {
+		return CandidateCPEs(dep)
+	}
+	cpes := make([]CPE, 0, len(dep.CPEs))
+	for _, s := range dep.CPEs {
+		if cpe, err := ParseCPE(s); err == nil {
+			cpes = append(cpes, cpe)
+		}
+	}
+	return cpes
+}
+
+/*
+使用示例:
+
+dep := models.NewDependency("ZLIB")
+dep.CanonicalID = "zlib"
+dep.Version = "1.2.11"
+AssignCPEs([]*models.Dependency{dep})
+fmt.Println(dep.CPEs) // [cpe:2.3:a:zlib:zlib:1.2.11:*:*:*:*:*:*:* cpe:2.3:a:gnu:zlib:1.2.11:*:*:*:*:*:*:*]
+*/
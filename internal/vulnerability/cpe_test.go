@@ -0,0 +1,139 @@
+package vulnerability
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lkpsg/ccscanner/internal/identity"
+	"github.com/lkpsg/ccscanner/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCPE(t *testing.T) {
+	cpe, err := ParseCPE("cpe:2.3:a:haxx:libcurl:7.88.1:*:*:*:*:*:*:*")
+	require.NoError(t, err)
+	assert.Equal(t, "haxx", cpe.Vendor)
+	assert.Equal(t, "libcurl", cpe.Product)
+	assert.Equal(t, "7.88.1", cpe.Version)
+	assert.Equal(t, "cpe:2.3:a:haxx:libcurl:7.88.1:*:*:*:*:*:*:*", cpe.String())
+
+	cpe, err = ParseCPE(`cpe:2.3:a:json-for-modern-cpp_project:json-for-modern-cpp:3.11\:2`)
+	require.NoError(t, err)
+	assert.Equal(t, `3.11\:2`, cpe.Version)
+	assert.Equal(t, "*", cpe.Other)
+
+	for _, input := range []string{"", "cpe:/a:zlib:zlib:1.2.11", "cpe:2.3:x:zlib:zlib", "cpe:2.3:a"} {
+		_, err := ParseCPE(input)
+		assert.Error(t, err, input)
+	}
+}
+
+func TestCPEMatch_Matches(t *testing.T) {
+	zlib := func(version string) CPE { return NewCPE("zlib", "zlib", version) }
+	tests := []struct {
+		name   string
+		match  CPEMatch
+		target CPE
+		want   bool
+	}{
+		{"end excluding hit", CPEMatch{Criteria: "cpe:2.3:a:zlib:zlib:*:*:*:*:*:*:*:*", VersionEndExcluding: "1.2.12"}, zlib("1.2.11"), true},
+		{"end excluding boundary", CPEMatch{Criteria: "cpe:2.3:a:zlib:zlib:*:*:*:*:*:*:*:*", VersionEndExcluding: "1.2.12"}, zlib("1.2.12"), false},
+		{"end including boundary", CPEMatch{Criteria: "cpe:2.3:a:zlib:zlib:*:*:*:*:*:*:*:*", VersionEndIncluding: "1.2.12"}, zlib("1.2.12"), true},
+		{"start including boundary", CPEMatch{Criteria: "cpe:2.3:a:zlib:zlib:*:*:*:*:*:*:*:*", VersionStartIncluding: "1.2.9", VersionEndExcluding: "1.2.12"}, zlib("1.2.9"), true},
+		{"start excluding boundary", CPEMatch{Criteria: "cpe:2.3:a:zlib:zlib:*:*:*:*:*:*:*:*", VersionStartExcluding: "1.2.9"}, zlib("1.2.9"), false},
+		{"exact version", CPEMatch{Criteria: "cpe:2.3:a:zlib:zlib:1.2.11:*:*:*:*:*:*:*"}, zlib("1.2.11"), true},
+		{"exact version miss", CPEMatch{Criteria: "cpe:2.3:a:zlib:zlib:1.2.11:*:*:*:*:*:*:*"}, zlib("1.2.13"), false},
+		{"wildcard version", CPEMatch{Criteria: "cpe:2.3:a:zlib:zlib:1.2.*:*:*:*:*:*:*:*"}, zlib("1.2.13"), true},
+		{"other product", CPEMatch{Criteria: "cpe:2.3:a:openssl:openssl:*:*:*:*:*:*:*:*"}, zlib("1.2.11"), false},
+		{"unknown version with range", CPEMatch{Criteria: "cpe:2.3:a:zlib:zlib:*:*:*:*:*:*:*:*", VersionEndExcluding: "1.2.12"}, zlib(""), false},
+		{"unknown version without range", CPEMatch{Criteria: "cpe:2.3:a:zlib:zlib:*:*:*:*:*:*:*:*"}, zlib(""), true},
+		{"letter patch release", CPEMatch{Criteria: "cpe:2.3:a:openssl:openssl:*:*:*:*:*:*:*:*", VersionStartIncluding: "1.1.1", VersionEndExcluding: "1.1.1t"}, NewCPE("openssl", "openssl", "1.1.1k"), true},
+	}
+	for _, tt := range tests {
+		got, err := tt.match.Matches(tt.target)
+		require.NoError(t, err, tt.name)
+		assert.Equal(t, tt.want, got, tt.name)
+	}
+
+	_, err := CPEMatch{Criteria: "not-a-cpe"}.Matches(zlib("1.0"))
+	assert.Error(t, err)
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.2.11", "1.2.12", -1},
+		{"1.2.13", "1.2.9", 1},
+		{"1.0", "1.0.0", 0},
+		{"v2.0.0", "2.0.0", 0},
+		{"1.1.1k", "1.1.1", 1},
+		{"1.1.1k", "1.1.1t", -1},
+		{"2.0.0-rc1", "2.0.0", -1},
+		{"3.11.2", "3.11.2", 0},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, CompareVersions(tt.a, tt.b), "%s vs %s", tt.a, tt.b)
+	}
+}
+
+func TestAssignCPEs(t *testing.T) {
+	zlib := models.NewDependency("ZLIB")
+	zlib.CanonicalID = "zlib"
+	zlib.Version = "1.2.11"
+	curl := models.NewDependency("libcurl")
+	curl.CanonicalID = "curl"
+	unknown := models.NewDependency("acme-internal")
+
+	AssignCPEs([]*models.Dependency{zlib, curl, unknown})
+	assert.Contains(t, zlib.CPEs, "cpe:2.3:a:zlib:zlib:1.2.11:*:*:*:*:*:*:*")
+	assert.Equal(t, []string{"cpe:2.3:a:haxx:curl:*:*:*:*:*:*:*:*", "cpe:2.3:a:haxx:libcurl:*:*:*:*:*:*:*:*"}, curl.CPEs)
+	assert.Empty(t, unknown.CPEs)
+
+	// npm中与C库同名的包不使用C库的CPE
+	npm := &models.Dependency{Name: "openssl", PURL: "pkg:npm/openssl@1.1.0"}
+	npm.CanonicalID = identity.Default().Resolve(npm)
+	AssignCPEs([]*models.Dependency{npm})
+	assert.Empty(t, npm.CPEs)
+
+	file := filepath.Join(t.TempDir(), "cpe.json")
+	require.NoError(t, os.WriteFile(file, []byte(`[{"name": "acme-internal", "products": ["acme:internal"]}]`), 0644))
+	require.NoError(t, LoadCPEDictionary(file))
+	assert.Len(t, CandidateCPEs(unknown), 1)
+
+	require.NoError(t, os.WriteFile(file, []byte(`[{"name": "broken", "products": ["noproduct"]}]`), 0644))
+	assert.Error(t, LoadCPEDictionary(file))
+}
+
+func TestVulnerabilityDatabase_GetVulnerabilitiesByCPE(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "vulns.json")
+	require.NoError(t, os.WriteFile(file, []byte(`[
+		{"id": "CVE-2022-37434", "configurations": [
+			{"criteria": "cpe:2.3:a:zlib:zlib:*:*:*:*:*:*:*:*", "vulnerable": true, "versionEndIncluding": "1.2.12"}
+		]},
+		{"id": "GHSA-0001", "packageName": "zlib", "versions": ["<1.2.12"]}
+	]`), 0644))
+
+	db := NewVulnerabilityDatabase(file)
+	require.NoError(t, db.LoadData())
+
+	vulns := db.GetVulnerabilitiesByCPE([]CPE{NewCPE("zlib", "zlib", "1.2.11")})
+	require.Len(t, vulns, 1)
+	assert.Equal(t, "CVE-2022-37434", vulns[0].ID)
+
+	match, err := MatchCPEs(vulns[0].Configurations, []CPE{NewCPE("zlib", "zlib", "1.2.11")})
+	require.NoError(t, err)
+	assert.NotNil(t, match)
+	match, err = MatchCPEs(vulns[0].Configurations, []CPE{NewCPE("zlib", "zlib", "1.2.13")})
+	require.NoError(t, err)
+	assert.Nil(t, match)
+
+	// 保存后只有CPE条件的记录不能丢失
+	require.NoError(t, db.SaveData())
+	reloaded := NewVulnerabilityDatabase(file)
+	require.NoError(t, reloaded.LoadData())
+	assert.Len(t, reloaded.GetVulnerabilitiesByCPE([]CPE{NewCPE("zlib", "zlib", "")}), 1)
+}
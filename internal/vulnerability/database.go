@@ -17,6 +17,7 @@ import (
 // VulnerabilityDatabase 表示漏洞数据库
 type VulnerabilityDatabase struct {
 	vulnerabilities map[string][]Vulnerability // 按包名索引的漏洞列表
+	cpeIndex       map[string][]Vulnerability // 按CPE vendor:product索引的漏洞列表
 	lastUpdate     time.Time                  // 最后更新时间
 	dataPath       string                     // 数据文件路径
 	sources        []VulnerabilitySource      // 漏洞数据源
@@ -37,6 +38,7 @@ type Vulnerability struct {
 	Type        string    `json:"type"`         // 漏洞类型
 	CWE         []string  `json:"cwe"`         // CWE编号
 	CVE         []string  `json:"cve"`         // CVE编号
+	Configurations []CPEMatch `json:"configurations,omitempty"` // NVD格式的CPE匹配条件
 	Metadata    map[string]interface{} `json:"metadata"` // 额外元数据
 }
 
@@ -65,6 +67,7 @@ type NVDSource struct {
 func NewVulnerabilityDatabase(dataPath string) *VulnerabilityDatabase {
 	return &VulnerabilityDatabase{
 		vulnerabilities: make(map[string][]Vulnerability),
+		cpeIndex:       make(map[string][]Vulnerability),
 		dataPath:       dataPath,
 		sources: []VulnerabilitySource{
 			&GithubAdvisoriesSource{},
@@ -93,11 +96,7 @@ func (db *VulnerabilityDatabase) LoadData() error {
 		return fmt.Errorf("failed to parse vulnerability database: %v", err)
 	}
 
-	// 按包名索引漏洞
-	db.vulnerabilities = make(map[string][]Vulnerability)
-	for _, vuln := range vulns {
-		db.vulnerabilities[vuln.PackageName] = append(db.vulnerabilities[vuln.PackageName], vuln)
-	}
+	db.index(vulns)
 
 	return nil
 }
@@ -112,6 +111,16 @@ func (db *VulnerabilityDatabase) SaveData() error {
 	for _, packageVulns := range db.vulnerabilities {
 		vulns = append(vulns, packageVulns...)
 	}
+	// 只有CPE条件、没有包名的记录(如NVD)只存在于CPE索引中
+	seen := make(map[string]bool)
+	for _, productVulns := range db.cpeIndex {
+		for _, vuln := range productVulns {
+			if vuln.PackageName == "" && !seen[vuln.ID] {
+				seen[vuln.ID] = true
+				vulns = append(vulns, vuln)
+			}
+		}
+	}
 
 	data, err := json.MarshalIndent(vulns, "", "  ")
 	if err != nil {
@@ -177,15 +186,52 @@ func (db *VulnerabilityDatabase) Update() error {
 	}
 
 	// 更新数据库
-	db.vulnerabilities = make(map[string][]Vulnerability)
-	for _, vuln := range allVulns {
-		db.vulnerabilities[vuln.PackageName] = append(db.vulnerabilities[vuln.PackageName], vuln)
-	}
+	db.index(allVulns)
 	db.lastUpdate = time.Now()
 
 	return nil
 }
 
+// index 按包名和CPE产品建立漏洞索引, 调用方需持有写锁
+func (db *VulnerabilityDatabase) index(vulns []Vulnerability) {
+	db.vulnerabilities = make(map[string][]Vulnerability)
+	db.cpeIndex = make(map[string][]Vulnerability)
+	for _, vuln := range vulns {
+		if vuln.PackageName != "" {
+			db.vulnerabilities[vuln.PackageName] = append(db.vulnerabilities[vuln.PackageName], vuln)
+		}
+
+		// NVD记录没有包名, 通过CPE条件中的产品查找
+		seen := make(map[string]bool)
+		for _, match := range vuln.Configurations {
+			cpe, err := ParseCPE(match.Criteria)
+			if err != nil || seen[cpe.VendorProduct()] {
+				continue
+			}
+			seen[cpe.VendorProduct()] = true
+			db.cpeIndex[cpe.VendorProduct()] = append(db.cpeIndex[cpe.VendorProduct()], vuln)
+		}
+	}
+}
+
+// GetVulnerabilitiesByCPE 获取与任一CPE产品相关的漏洞信息(按ID去重)
+func (db *VulnerabilityDatabase) GetVulnerabilitiesByCPE(cpes []CPE) []Vulnerability {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	var result []Vulnerability
+	seen := make(map[string]bool)
+	for _, cpe := range cpes {
+		for _, vuln := range db.cpeIndex[cpe.VendorProduct()] {
+			if !seen[vuln.ID] {
+				seen[vuln.ID] = true
+				result = append(result, vuln)
+			}
+		}
+	}
+	return result
+}
+
 // GetVulnerabilities 获取指定包的漏洞信息
 func (db *VulnerabilityDatabase) GetVulnerabilities(packageName string) []Vulnerability {
 	db.mu.RLock()
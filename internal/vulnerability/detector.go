@@ -8,12 +8,14 @@ import (
 
 	"github.com/Masterminds/semver/v3"
 	"github.com/your-org/ccscanner/pkg/models"
+	"go.uber.org/zap"
 )
 
 // Detector 漏洞检测器
 type Detector struct {
 	database *VulnerabilityDatabase
-	cache    *sync.Map // 缓存检测结果
+	cache    *sync.Map   // 缓存检测结果
+	logger   *zap.Logger // 日志记录器
 }
 
 // DetectionResult 漏洞检测结果
@@ -37,11 +39,21 @@ const (
 	RiskLow      Risk = "LOW"      // 低危
 )
 
-// NewDetector 创建新的漏洞检测器
+// NewDetector 创建新的漏洞检测器, 不输出日志
 func NewDetector(database *VulnerabilityDatabase) *Detector {
+	return NewDetectorWithLogger(database, nil)
+}
+
+// NewDetectorWithLogger 创建使用指定日志记录器的漏洞检测器, 跳过的畸形漏洞记录等警告写入logger
+func NewDetectorWithLogger(database *VulnerabilityDatabase, logger *zap.Logger) *Detector {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+
 	return &Detector{
 		database: database,
 		cache:    &sync.Map{},
+		logger:   logger,
 	}
 }
 
@@ -119,20 +131,57 @@ func (d *Detector) detectSingle(dep *models.Dependency) (*DetectionResult, error
 		Metadata: dep.Metadata,
 	}
 
-	// 获取包的所有漏洞
-	vulns := d.database.GetVulnerabilities(dep.Name)
-	if len(vulns) == 0 {
-		return result, nil
+	// 获取包的所有漏洞, 优先使用规范ID查询
+	vulns := d.database.GetVulnerabilities(dep.Key())
+	if len(vulns) == 0 && dep.Key() != dep.Name {
+		vulns = d.database.GetVulnerabilities(dep.Name)
 	}
 
-	// 解析当前版本
-	currentVersion, err := semver.NewVersion(normalizeVersion(dep.Version))
-	if err != nil {
-		return result, fmt.Errorf("invalid version format: %v", err)
+	// 按CPE产品查找NVD格式的漏洞记录
+	cpes := dependencyCPEs(dep)
+	seen := make(map[string]bool, len(vulns))
+	for _, vuln := range vulns {
+		seen[vuln.ID] = true
+	}
+	for _, vuln := range d.database.GetVulnerabilitiesByCPE(cpes) {
+		if !seen[vuln.ID] {
+			seen[vuln.ID] = true
+			vulns = append(vulns, vuln)
+		}
+	}
+	if len(vulns) == 0 {
+		return result, nil
 	}
 
 	// 检查每个漏洞
+	var currentVersion *semver.Version
 	for _, vuln := range vulns {
+		// 带CPE条件的记录使用CPE匹配器按版本范围精确判断, 条件格式错误时跳过该记录, 不影响其他记录
+		if len(vuln.Configurations) > 0 && len(cpes) > 0 {
+			match, err := MatchCPEs(vuln.Configurations, cpes)
+			if err != nil {
+				d.logger.Warn("跳过CPE条件无效的漏洞记录",
+					zap.String("id", vuln.ID),
+					zap.String("package", dep.Name),
+					zap.Error(err),
+				)
+				continue
+			}
+			if match != nil {
+				result.Vulnerabilities = append(result.Vulnerabilities, vuln)
+			}
+			continue
+		}
+
+		// 解析当前版本
+		if currentVersion == nil {
+			version, err := semver.NewVersion(normalizeVersion(dep.Version))
+			if err != nil {
+				return result, fmt.Errorf("invalid version format: %v", err)
+			}
+			currentVersion = version
+		}
+
 		// 检查版本是否受影响
 		affected := false
 		for _, version := range vuln.Versions {
@@ -199,7 +248,7 @@ func (d *Detector) calculateRisk(vuln Vulnerability) Risk {
 
 // cacheKey 生成缓存键
 func (d *Detector) cacheKey(dep *models.Dependency) string {
-	return fmt.Sprintf("%s@%s", dep.Name, dep.Version)
+	return fmt.Sprintf("%s@%s", dep.Key(), dep.Version)
 }
 
 // normalizeVersion 标准化版本号
@@ -0,0 +1,71 @@
+package vulnerability
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lkpsg/ccscanner/internal/merger"
+	"github.com/lkpsg/ccscanner/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// newTestDetector 从JSON记录创建检测器
+func newTestDetector(t *testing.T, records string) *Detector {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), "vulns.json")
+	require.NoError(t, os.WriteFile(file, []byte(records), 0644))
+	db := NewVulnerabilityDatabase(file)
+	require.NoError(t, db.LoadData())
+	return NewDetector(db)
+}
+
+func TestDetector_MergedVersions(t *testing.T) {
+	detector := newTestDetector(t, `[
+		{"id": "GHSA-old", "packageName": "zlib", "versions": ["<1.2.12"]},
+		{"id": "GHSA-new", "packageName": "zlib", "versions": [">=1.3, <1.3.1"]}
+	]`)
+
+	// 内置源码中的旧版本与vcpkg安装的新版本合并后仍分别检测
+	merged := merger.Merge([]models.Dependency{
+		{Name: "zlib", Version: "1.2.11", ConfigFile: "third_party/zlib", ConfigFileType: "directory", DetectedBy: "vendored"},
+		{Name: "zlib", Version: "1.3", ConfigFile: "vcpkg.json", ConfigFileType: "vcpkg.json", DetectedBy: "vcpkg"},
+	})
+	deps := make([]*models.Dependency, len(merged))
+	for i := range merged {
+		deps[i] = &merged[i]
+	}
+
+	results, err := detector.DetectVulnerabilities(deps)
+	require.NoError(t, err)
+	found := make(map[string][]string)
+	for _, result := range results {
+		for _, vuln := range result.Vulnerabilities {
+			found[result.Version] = append(found[result.Version], vuln.ID)
+		}
+	}
+	assert.Equal(t, map[string][]string{"1.2.11": {"GHSA-old"}, "1.3": {"GHSA-new"}}, found)
+}
+
+func TestDetector_InvalidCriteria(t *testing.T) {
+	detector := newTestDetector(t, `[
+		{"id": "CVE-BROKEN", "packageName": "zlib", "configurations": [{"criteria": "cpe:2.3:a:zlib", "vulnerable": true}]},
+		{"id": "CVE-2022-37434", "configurations": [
+			{"criteria": "cpe:2.3:a:zlib:zlib:*:*:*:*:*:*:*:*", "vulnerable": true, "versionEndIncluding": "1.2.12"}
+		]}
+	]`)
+	core, logs := observer.New(zap.WarnLevel)
+	detector = NewDetectorWithLogger(detector.database, zap.New(core))
+
+	// 格式错误的记录被跳过并记录警告, 其他记录照常匹配
+	dep := &models.Dependency{Name: "zlib", Version: "1.2.11", CPEs: []string{"cpe:2.3:a:zlib:zlib:1.2.11:*:*:*:*:*:*:*"}}
+	results, err := detector.DetectVulnerabilities([]*models.Dependency{dep})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	require.Len(t, results[0].Vulnerabilities, 1)
+	assert.Equal(t, "CVE-2022-37434", results[0].Vulnerabilities[0].ID)
+	assert.Equal(t, 1, logs.FilterField(zap.String("id", "CVE-BROKEN")).Len())
+}
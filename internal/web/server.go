@@ -1,7 +1,9 @@
 package web
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"html/template"
 	"net/http"
@@ -12,6 +14,7 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/gorilla/websocket"
 	"github.com/your-org/ccscanner/internal/analyzer"
+	"github.com/your-org/ccscanner/internal/extractor"
 	"github.com/your-org/ccscanner/internal/scanner"
 	"github.com/your-org/ccscanner/internal/vulnerability"
 	"github.com/your-org/ccscanner/pkg/models"
@@ -28,20 +31,43 @@ type Server struct {
 	clientsMu  sync.RWMutex
 	staticDir  string
 	templates  *template.Template
+	scans      map[string]context.CancelFunc // 运行中的扫描, 用于停止扫描
+	scansMu    sync.Mutex
 }
 
 // ScanRequest 扫描请求
 type ScanRequest struct {
 	Path       string   `json:"path"`
+	Revision   string   `json:"revision"` // Git版本(标签、分支或提交), 为空时扫描工作区
 	Extractors []string `json:"extractors"`
 	Options    struct {
 		IgnoreTests    bool     `json:"ignoreTests"`
 		ExcludeFiles   []string `json:"excludeFiles"`
+		IncludeFiles   []string `json:"includeFiles"`
 		MaxDepth       int      `json:"maxDepth"`
 		IncludeDevDeps bool     `json:"includeDevDeps"`
 	} `json:"options"`
 }
 
+// filters 将请求选项转换为扫描过滤配置, 未要求包含开发依赖时只保留其余范围
+func (r *ScanRequest) filters() *extractor.ExtractorConfig {
+	config := extractor.DefaultConfig
+	config.IgnoreTests = r.Options.IgnoreTests
+	config.ExcludeFiles = r.Options.ExcludeFiles
+	config.IncludeFiles = r.Options.IncludeFiles
+	config.MaxDepth = r.Options.MaxDepth
+	if !r.Options.IncludeDevDeps {
+		config.Scopes = []string{
+			models.ScopeRuntime,
+			models.ScopeBuild,
+			models.ScopeTest,
+			models.ScopeOptional,
+			models.ScopeTool,
+		}
+	}
+	return &config
+}
+
 // ScanResult 扫描结果
 type ScanResult struct {
 	ID           string                 `json:"id"`
@@ -65,6 +91,7 @@ func NewServer(scanner *scanner.Scanner, analyzer *analyzer.DependencyAnalyzer,
 		detector:  detector,
 		staticDir: staticDir,
 		clients:   make(map[*websocket.Conn]bool),
+		scans:     make(map[string]context.CancelFunc),
 		upgrader: websocket.Upgrader{
 			ReadBufferSize:  1024,
 			WriteBufferSize: 1024,
@@ -103,6 +130,7 @@ func (s *Server) registerRoutes() {
 	// API端点
 	api := s.router.PathPrefix("/api").Subrouter()
 	api.HandleFunc("/scan", s.handleScan).Methods("POST")
+	api.HandleFunc("/scan/stream", s.handleScanStream).Methods("POST")
 	api.HandleFunc("/scan/{id}", s.handleGetScan).Methods("GET")
 	api.HandleFunc("/scan/{id}/stop", s.handleStopScan).Methods("POST")
 	api.HandleFunc("/scan/{id}/results", s.handleGetResults).Methods("GET")
@@ -158,16 +186,48 @@ func (s *Server) handleScan(w http.ResponseWriter, r *http.Request) {
 		StartTime: time.Now(),
 	}
 
+	// 注册扫描, 以便通过 /api/scan/{id}/stop 取消
+	ctx, cancel := context.WithCancel(context.Background())
+	s.scansMu.Lock()
+	s.scans[result.ID] = cancel
+	s.scansMu.Unlock()
+
 	// 异步执行扫描
 	go func() {
-		// 扫描依赖
-		deps, err := s.scanner.Scan(req.Path, req.Extractors)
+		defer func() {
+			s.scansMu.Lock()
+			delete(s.scans, result.ID)
+			s.scansMu.Unlock()
+			cancel()
+		}()
+
+		// 扫描依赖, 扫描阶段占总进度的三分之一
+		scanResult, err := s.scanner.ScanContext(ctx, scanner.ScanOptions{
+			Root:       req.Path,
+			Revision:   req.Revision,
+			Extractors: req.Extractors,
+			Filters:    req.filters(),
+			Progress: func(e scanner.ProgressEvent) {
+				if e.Discovered > 0 {
+					result.Progress = 33.3 * float64(e.Processed) / float64(e.Discovered)
+					s.broadcastUpdate(result)
+				}
+			},
+		})
 		if err != nil {
 			result.Errors = append(result.Errors, err.Error())
 			result.Status = "failed"
+			if errors.Is(err, context.Canceled) {
+				result.Status = "stopped"
+			}
 			s.broadcastUpdate(result)
 			return
 		}
+		result.Errors = append(result.Errors, scanResult.Errors...)
+		deps := make([]*models.Dependency, len(scanResult.Dependencies))
+		for i := range scanResult.Dependencies {
+			deps[i] = &scanResult.Dependencies[i]
+		}
 		result.Dependencies = deps
 		result.Progress = 33.3
 		s.broadcastUpdate(result)
@@ -205,6 +265,30 @@ func (s *Server) handleScan(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// handleScanStream 以NDJSON逐行返回扫描事件, 客户端断开连接时扫描随请求一起取消
+func (s *Server) handleScanStream(w http.ResponseWriter, r *http.Request) {
+	var req ScanRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	events, err := s.scanner.Stream(r.Context(), scanner.ScanOptions{
+		Root:       req.Path,
+		Revision:   req.Revision,
+		Extractors: req.Extractors,
+		Filters:    req.filters(),
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	// 写入失败说明客户端已断开, 返回后请求的ctx被取消, 扫描随之结束
+	scanner.WriteNDJSON(w, events)
+}
+
 // handleGetScan 处理获取扫描状态请求
 func (s *Server) handleGetScan(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
@@ -225,7 +309,14 @@ func (s *Server) handleStopScan(w http.ResponseWriter, r *http.Request) {
 	id := vars["id"]
 
 	// 停止扫描
-	// ...
+	s.scansMu.Lock()
+	cancel, ok := s.scans[id]
+	s.scansMu.Unlock()
+	if !ok {
+		http.Error(w, "scan not found or already finished", http.StatusNotFound)
+		return
+	}
+	cancel()
 
 	json.NewEncoder(w).Encode(map[string]string{
 		"status": "stopped",
@@ -78,22 +78,28 @@ func (f *TextFormatter) Format(result *models.ScanResult) ([]byte, error) {
 	b.WriteString(fmt.Sprintf("扫描时间: %s\n", result.StartTime.Format(time.RFC3339)))
 	b.WriteString(fmt.Sprintf("耗时: %s\n\n", result.Duration))
 
-	// 依赖信息
+	// 依赖信息, 低置信度的依赖单独列出
+	deps, lowDeps := splitByConfidence(result.Dependencies)
 	b.WriteString(fmt.Sprintf("找到 %d 个依赖:\n", len(result.Dependencies)))
-	if f.Verbose {
-		for _, dep := range result.Dependencies {
-			b.WriteString(fmt.Sprintf("- %s (%s)\n", dep.Name, dep.Type))
-			b.WriteString(fmt.Sprintf("  文件: %s:%d\n", dep.FilePath, dep.Line))
-			if dep.Parent != "" {
-				b.WriteString(fmt.Sprintf("  父节点: %s\n", dep.Parent))
+	f.writeDependencies(&b, deps)
+	if len(lowDeps) > 0 {
+		b.WriteString(fmt.Sprintf("\n低置信度依赖 %d 个(置信度低于 %.2f, 需人工确认):\n", len(lowDeps), models.LowConfidence))
+		f.writeDependencies(&b, lowDeps)
+	}
+	b.WriteString("\n")
+
+	// 子模块相对跟踪分支的落后情况, 落后最多的排在前面
+	if drifts := models.CollectSubmoduleDrift(result.Dependencies); len(drifts) > 0 {
+		b.WriteString(fmt.Sprintf("子模块漂移 %d 个:\n", len(drifts)))
+		for _, drift := range drifts {
+			b.WriteString(fmt.Sprintf("- %s\n", drift.String()))
+			if f.Verbose {
+				b.WriteString(fmt.Sprintf("  固定提交: %s (%s)\n", drift.PinnedCommit, drift.PinnedDate.Format("2006-01-02")))
+				b.WriteString(fmt.Sprintf("  分支最新: %s (%s)\n", drift.BranchCommit, drift.BranchDate.Format("2006-01-02")))
 			}
 		}
-	} else {
-		for _, dep := range result.Dependencies {
-			b.WriteString(fmt.Sprintf("- %s\n", dep.Name))
-		}
+		b.WriteString("\n")
 	}
-	b.WriteString("\n")
 
 	// 漏洞信息
 	if len(result.Vulnerabilities) > 0 {
@@ -121,6 +127,47 @@ func (f *TextFormatter) Format(result *models.ScanResult) ([]byte, error) {
 	return []byte(b.String()), nil
 }
 
+// writeDependencies 输出依赖列表
+func (f *TextFormatter) writeDependencies(b *strings.Builder, deps []models.Dependency) {
+	for _, dep := range deps {
+		if !f.Verbose {
+			b.WriteString(fmt.Sprintf("- %s\n", dep.Name))
+			continue
+		}
+		b.WriteString(fmt.Sprintf("- %s (%s)\n", dep.Name, dep.Type))
+		b.WriteString(fmt.Sprintf("  文件: %s\n", dep.Location()))
+		if dep.Scope != "" {
+			b.WriteString(fmt.Sprintf("  范围: %s\n", dep.Scope))
+		}
+		if dep.Provenance != nil && dep.Provenance.Snippet != "" {
+			b.WriteString(fmt.Sprintf("  代码: %s\n", dep.Provenance.Snippet))
+		}
+		if dep.Confidence > 0 {
+			b.WriteString(fmt.Sprintf("  置信度: %.2f (%s)\n", dep.Confidence, dep.ConfidenceReason))
+		}
+		if dep.Parent != "" {
+			b.WriteString(fmt.Sprintf("  父节点: %s\n", dep.Parent))
+		}
+		if !dep.IsDirect() {
+			for _, path := range dep.IntroducedBy {
+				b.WriteString(fmt.Sprintf("  引入路径: %s\n", strings.Join(path, " -> ")))
+			}
+		}
+	}
+}
+
+// splitByConfidence 将依赖分为正常和低置信度两组
+func splitByConfidence(deps []models.Dependency) (normal, low []models.Dependency) {
+	for _, dep := range deps {
+		if dep.IsLowConfidence() {
+			low = append(low, dep)
+		} else {
+			normal = append(normal, dep)
+		}
+	}
+	return normal, low
+}
+
 // Format 实现 HTML 格式化
 func (f *HTMLFormatter) Format(result *models.ScanResult) ([]byte, error) {
 	// 使用默认模板或加载自定义模板
@@ -134,12 +181,19 @@ func (f *HTMLFormatter) Format(result *models.ScanResult) ([]byte, error) {
 	}
 
 	// 准备模板数据
+	deps, lowDeps := splitByConfidence(result.Dependencies)
 	data := struct {
-		Result    *models.ScanResult
-		Timestamp string
+		Result        *models.ScanResult
+		Dependencies  []models.Dependency
+		LowConfidence []models.Dependency
+		Drifts        []models.SubmoduleDrift
+		Timestamp     string
 	}{
-		Result:    result,
-		Timestamp: time.Now().Format(time.RFC3339),
+		Result:        result,
+		Dependencies:  deps,
+		LowConfidence: lowDeps,
+		Drifts:        models.CollectSubmoduleDrift(result.Dependencies),
+		Timestamp:     time.Now().Format(time.RFC3339),
 	}
 
 	// 渲染模板
@@ -229,17 +283,38 @@ var defaultHTMLTemplate = template.Must(template.New("report").Parse(`
         <div class="section">
             <h2>依赖信息</h2>
             <p>共找到 {{len .Result.Dependencies}} 个依赖</p>
-            {{range .Result.Dependencies}}
+            {{range .Dependencies}}
+            {{template "dependency" .}}
+            {{end}}
+        </div>
+
+        {{if .LowConfidence}}
+        <div class="section">
+            <h2>低置信度依赖</h2>
+            <p>以下 {{len .LowConfidence}} 个依赖为推断结果, 需人工确认</p>
+            {{range .LowConfidence}}
+            {{template "dependency" .}}
+            {{end}}
+        </div>
+        {{end}}
+
+        {{if .Drifts}}
+        <div class="section">
+            <h2>子模块漂移</h2>
+            <p>{{len .Drifts}} 个子模块设置了跟踪分支</p>
+            {{range .Drifts}}
             <div class="dependency">
-                <h3>{{.Name}}</h3>
-                <p>类型: {{.Type}}</p>
-                <p>文件: {{.FilePath}}:{{.Line}}</p>
-                {{if .Parent}}
-                <p>父节点: {{.Parent}}</p>
+                <h3>{{.Name}} ({{.Path}})</h3>
+                <p>落后 {{.Branch}}: {{.CommitsBehind}} 个提交 / {{.DaysBehind}} 天{{if .CommitsAhead}}, 领先 {{.CommitsAhead}} 个提交{{end}}</p>
+                <p>固定提交: {{.PinnedCommit}}</p>
+                <p>分支最新: {{.BranchCommit}}</p>
+                {{if .NewerTags}}
+                <p>新标签: {{range $i, $tag := .NewerTags}}{{if $i}}, {{end}}{{$tag}}{{end}}</p>
                 {{end}}
             </div>
             {{end}}
         </div>
+        {{end}}
 
         {{if .Result.Vulnerabilities}}
         <div class="section">
@@ -266,6 +341,28 @@ var defaultHTMLTemplate = template.Must(template.New("report").Parse(`
     </div>
 </body>
 </html>
+{{define "dependency"}}
+            <div class="dependency">
+                <h3>{{.Name}}</h3>
+                <p>类型: {{.Type}}</p>
+                <p>文件: {{.Location}}</p>
+                {{if .Scope}}
+                <p>范围: {{.Scope}}</p>
+                {{end}}
+                {{with .Provenance}}{{if .Snippet}}
+                <pre>{{.Snippet}}</pre>
+                {{end}}{{end}}
+                {{if .Confidence}}
+                <p>置信度: {{printf "%.2f" .Confidence}} ({{.ConfidenceReason}})</p>
+                {{end}}
+                {{if .Parent}}
+                <p>父节点: {{.Parent}}</p>
+                {{end}}
+                {{if not .IsDirect}}{{range .IntroducedBy}}
+                <p>引入路径: {{range $i, $name := .}}{{if $i}} -&gt; {{end}}{{$name}}{{end}}</p>
+                {{end}}{{end}}
+            </div>
+{{end}}
 `))
 
 // 注意事项:
@@ -273,7 +370,8 @@ var defaultHTMLTemplate = template.Must(template.New("report").Parse(`
 // 2. 提供了格式化选项(美化、详细程度等)
 // 3. HTML输出支持自定义模板
 // 4. 文本输出支持详细和简略两种模式
-// 5. 提供了保存到文件的功能
+// 5. 设置了跟踪分支的子模块单独列出落后情况
+// 6. 提供了保存到文件的功能
 
 // 使用示例:
 // formatter, err := NewFormatter("json", map[string]interface{}{"pretty": true})
@@ -21,17 +21,45 @@ func createTestResult() *models.ScanResult {
 		Duration:   time.Second * 10,
 		Dependencies: []models.Dependency{
 			{
-				Name:     "boost",
-				Type:     "system",
-				FilePath: "CMakeLists.txt",
-				Line:     10,
-				Parent:   "main",
+				Name: "boost",
+				Type: "system",
+				Provenance: &models.Provenance{
+					File:      "CMakeLists.txt",
+					StartLine: 10,
+					Snippet:   "find_package(Boost REQUIRED)",
+				},
+				Parent: "main",
+				Scope:  models.ScopeRuntime,
 			},
 			{
-				Name:     "openssl",
-				Type:     "system",
-				FilePath: "CMakeLists.txt",
-				Line:     15,
+				Name: "deps/fmt",
+				Type: "submodule",
+				Drift: &models.SubmoduleDrift{
+					Name:          "fmt",
+					Path:          "deps/fmt",
+					Branch:        "master",
+					PinnedCommit:  "1111111111111111111111111111111111111111",
+					BranchCommit:  "2222222222222222222222222222222222222222",
+					CommitsBehind: 4,
+					DaysBehind:    30,
+					NewerTags:     []string{"10.2.0"},
+				},
+			},
+			{
+				Name: "openssl",
+				Type: "system",
+				Provenance: &models.Provenance{
+					File:      "CMakeLists.txt",
+					StartLine: 15,
+				},
+				Relationship: models.RelationshipTransitive,
+				IntroducedBy: [][]string{{"boost", "openssl"}},
+			},
+			{
+				Name:             "z",
+				Type:             "library",
+				Confidence:       0.3,
+				ConfidenceReason: "inferred from #include directives",
 			},
 		},
 		Vulnerabilities: []models.Vulnerability{
@@ -153,9 +181,17 @@ func TestTextFormatter_Format(t *testing.T) {
 				"项目路径: /path/to/project",
 				"boost (system)",
 				"文件: CMakeLists.txt:10",
+				"代码: find_package(Boost REQUIRED)",
+				"范围: runtime",
 				"父节点: main",
+				"引入路径: boost -> openssl",
+				"低置信度依赖 1 个",
+				"置信度: 0.30 (inferred from #include directives)",
 				"CVE-2023-1234 (严重程度: high)",
 				"描述: 严重的安全漏洞",
+				"子模块漂移 1 个",
+				"fmt (deps/fmt): 4 commits / 30 days behind master, newer tags: 10.2.0",
+				"固定提交: 1111111111111111111111111111111111111111",
 			},
 		},
 		{
@@ -166,6 +202,7 @@ func TestTextFormatter_Format(t *testing.T) {
 				"项目路径: /path/to/project",
 				"- boost",
 				"- openssl",
+				"fmt (deps/fmt): 4 commits / 30 days behind master",
 			},
 		},
 	}
@@ -202,8 +239,12 @@ func TestHTMLFormatter_Format(t *testing.T) {
 				"项目路径: /path/to/project",
 				"boost",
 				"openssl",
+				"低置信度依赖",
 				"CVE-2023-1234",
 				"严重的安全漏洞",
+				"子模块漂移",
+				"落后 master: 4 个提交 / 30 天",
+				"新标签: 10.2.0",
 			},
 		},
 	}
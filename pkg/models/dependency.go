@@ -1,11 +1,19 @@
 package models
 
-import "time"
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
 
 // Dependency 表示一个依赖项
 type Dependency struct {
 	// 基本信息
 	Name        string   `json:"name"`        // 依赖名称
+	CanonicalID string   `json:"canonicalId"` // 规范ID(同一个库在不同生态中的名称归一后的标识)
+	PURL        string   `json:"purl"`        // Package URL(pkg:type/namespace/name@version)
+	CPEs        []string `json:"cpes"`        // 候选CPE 2.3名称
 	Version     string   `json:"version"`     // 版本号
 	Type        string   `json:"type"`        // 依赖类型(如: library, framework等)
 	Description string   `json:"description"` // 描述信息
@@ -18,14 +26,20 @@ type Dependency struct {
 	Repository string `json:"repository"`   // 仓库地址
 	Branch     string `json:"branch"`      // 分支
 	Commit     string `json:"commit"`      // 提交hash
+	Checksum   string `json:"checksum"`    // 源码包校验和(如: sha256:...)
+	Drift      *SubmoduleDrift `json:"drift,omitempty"` // 子模块相对跟踪分支的落后情况
 
 	// 依赖关系
 	Dependencies   []string          `json:"dependencies"`    // 直接依赖
+	Parent         string            `json:"parent"`          // 上级依赖(如嵌套子模块所属的子模块)
 	DevDependencies []string         `json:"devDependencies"` // 开发依赖
 	Conflicts      []string          `json:"conflicts"`       // 冲突项
 	Optional       bool              `json:"optional"`        // 是否可选
 	Required       bool              `json:"required"`        // 是否必需
+	Scope          string            `json:"scope"`           // 依赖范围(runtime、build、test、dev、optional、tool)
 	Constraints    []VersionConstrain `json:"constraints"`    // 版本约束
+	Relationship   string            `json:"relationship"`    // 直接依赖(direct)或传递依赖(transitive)
+	IntroducedBy   [][]string        `json:"introducedBy,omitempty"` // 引入路径, 每条从项目直接声明的依赖开始, 到该依赖结束
 
 	// 构建信息
 	BuildSystem    string   `json:"buildSystem"`    // 构建系统(如: cmake, make等)
@@ -44,6 +58,192 @@ type Dependency struct {
 	LastUpdated    time.Time `json:"lastUpdated"`    // 最后更新时间
 	ConfigFile     string    `json:"configFile"`     // 配置文件路径
 	ConfigFileType string    `json:"configFileType"` // 配置文件类型
+	Metadata       map[string]interface{} `json:"metadata"` // 额外元数据
+	Evidence       []Evidence `json:"evidence"`     // 合并前各检测来源
+	Provenance     *Provenance `json:"provenance,omitempty"` // 精确来源位置
+
+	// 置信度
+	Confidence       float64 `json:"confidence"`       // 检测结果的置信度(0-1, 0表示未评分)
+	ConfidenceReason string  `json:"confidenceReason"` // 置信度的依据
+}
+
+// 依赖关系类型
+const (
+	RelationshipDirect     = "direct"     // 项目自身直接声明的依赖
+	RelationshipTransitive = "transitive" // 仅由其他依赖引入(锁定文件中解析出的间接依赖、嵌套子模块等)
+)
+
+// 依赖范围, 统一各生态中 devDependencies、tool_requires、Build-Depends、<scope> 等不同的表达
+const (
+	ScopeRuntime  = "runtime"  // 运行时依赖(链接进产品或随产品分发)
+	ScopeBuild    = "build"    // 只在构建时需要(构建脚本、Maven provided等)
+	ScopeTest     = "test"     // 只在测试时需要
+	ScopeDev      = "dev"      // 开发依赖(devDependencies、dev-dependencies等)
+	ScopeOptional = "optional" // 可选依赖(可选特性、Recommends/Suggests等)
+	ScopeTool     = "tool"     // 构建工具(conan tool_requires、vcpkg host依赖、nativeBuildInputs等)
+)
+
+// scopeAliases 各生态中的范围名称到统一范围的映射
+var scopeAliases = map[string]string{
+	"runtime":        ScopeRuntime,
+	"compile":        ScopeRuntime,
+	"system":         ScopeRuntime,
+	"production":     ScopeRuntime,
+	"prod":           ScopeRuntime,
+	"main":           ScopeRuntime,
+	"implementation": ScopeRuntime,
+	"api":            ScopeRuntime,
+	"peer":           ScopeRuntime,
+	"requires":       ScopeRuntime,
+	"build":          ScopeBuild,
+	"provided":       ScopeBuild,
+	"import":         ScopeBuild,
+	"build-depends":  ScopeBuild,
+	"makedepends":    ScopeBuild,
+	"test":           ScopeTest,
+	"testing":        ScopeTest,
+	"check":          ScopeTest,
+	"checkdepends":   ScopeTest,
+	"test_requires":  ScopeTest,
+	"dev":            ScopeDev,
+	"development":    ScopeDev,
+	"optional":       ScopeOptional,
+	"recommends":     ScopeOptional,
+	"suggests":       ScopeOptional,
+	"enhances":       ScopeOptional,
+	"feature":        ScopeOptional,
+	"tool":           ScopeTool,
+	"native":         ScopeTool,
+	"host":           ScopeTool,
+	"tool_requires":  ScopeTool,
+	"build_requires": ScopeTool,
+}
+
+// NormalizeScope 将生态特有的范围名称(如 compile、provided、devDependencies)转换为统一范围, 无法识别时返回空字符串
+func NormalizeScope(scope string) string {
+	scope = strings.ToLower(strings.TrimSpace(scope))
+	scope = strings.TrimRight(strings.TrimSuffix(scope, "dependencies"), "-_")
+	return scopeAliases[scope]
+}
+
+// LowConfidence 低置信度阈值, 低于该值的依赖在报告中单独列出
+const LowConfidence = 0.5
+
+// Provenance 依赖在源文件中的精确来源, 行列从1开始, 列按字节计算
+type Provenance struct {
+	File             string `json:"file"`                  // 文件路径
+	StartLine        int    `json:"startLine,omitempty"`   // 起始行
+	StartColumn      int    `json:"startColumn,omitempty"` // 起始列
+	EndLine          int    `json:"endLine,omitempty"`     // 结束行
+	EndColumn        int    `json:"endColumn,omitempty"`   // 结束列(不含, 与SARIF一致)
+	Snippet          string `json:"snippet,omitempty"`     // 命中的源码片段
+	Extractor        string `json:"extractor"`             // 提取器名称
+	ExtractorVersion string `json:"extractorVersion"`      // 提取器版本
+	Rule             string `json:"rule,omitempty"`        // 命中的规则(如 cmake.find_package)
+}
+
+// String 返回 file:line:column 形式的位置
+func (p *Provenance) String() string {
+	if p == nil {
+		return ""
+	}
+	switch {
+	case p.StartLine == 0:
+		return p.File
+	case p.StartColumn == 0:
+		return fmt.Sprintf("%s:%d", p.File, p.StartLine)
+	}
+	return fmt.Sprintf("%s:%d:%d", p.File, p.StartLine, p.StartColumn)
+}
+
+// SubmoduleDrift 子模块相对跟踪分支的落后情况
+type SubmoduleDrift struct {
+	Name          string    `json:"name"`          // 子模块名
+	Path          string    `json:"path"`          // 子模块路径
+	Branch        string    `json:"branch"`        // 跟踪的分支
+	PinnedCommit  string    `json:"pinnedCommit"`  // 固定的提交
+	BranchCommit  string    `json:"branchCommit"`  // 分支最新提交
+	PinnedDate    time.Time `json:"pinnedDate"`    // 固定提交的提交时间
+	BranchDate    time.Time `json:"branchDate"`    // 分支最新提交的提交时间
+	CommitsBehind int       `json:"commitsBehind"` // 落后的提交数
+	CommitsAhead  int       `json:"commitsAhead"`  // 领先的提交数(固定提交不在分支上时)
+	DaysBehind    int       `json:"daysBehind"`    // 落后的天数
+	NewerTags     []string  `json:"newerTags"`     // 固定提交之后的新标签
+}
+
+// String 返回漂移信息的单行描述
+func (d SubmoduleDrift) String() string {
+	s := fmt.Sprintf("%s (%s): %d commits / %d days behind %s", d.Name, d.Path, d.CommitsBehind, d.DaysBehind, d.Branch)
+	if d.CommitsAhead > 0 {
+		s += fmt.Sprintf(", %d commits ahead", d.CommitsAhead)
+	}
+	if len(d.NewerTags) > 0 {
+		s += fmt.Sprintf(", newer tags: %s", strings.Join(d.NewerTags, ", "))
+	}
+	return s
+}
+
+// CollectSubmoduleDrift 从依赖列表中收集子模块漂移信息,按落后天数降序排列
+func CollectSubmoduleDrift(deps []Dependency) []SubmoduleDrift {
+	drifts := make([]SubmoduleDrift, 0)
+	for _, dep := range deps {
+		if dep.Drift != nil {
+			drifts = append(drifts, *dep.Drift)
+		}
+	}
+	sort.SliceStable(drifts, func(i, j int) bool {
+		if drifts[i].DaysBehind != drifts[j].DaysBehind {
+			return drifts[i].DaysBehind > drifts[j].DaysBehind
+		}
+		return drifts[i].CommitsBehind > drifts[j].CommitsBehind
+	})
+	return drifts
+}
+
+// Evidence 依赖的一条检测来源
+type Evidence struct {
+	Extractor string `json:"extractor"` // 检测工具
+	File      string `json:"file"`      // 配置文件路径
+	FileType  string `json:"fileType"`  // 配置文件类型
+	Kind      string `json:"kind"`      // 来源类别(lockfile、manifest、build-script、heuristic)
+	Version   string `json:"version"`   // 该来源给出的版本
+
+	Confidence float64     `json:"confidence"`           // 该来源的置信度
+	Provenance *Provenance `json:"provenance,omitempty"` // 该来源的精确位置
+}
+
+// Key 返回依赖的身份标识, 已规范化时为规范ID, 否则为原始名称
+func (d *Dependency) Key() string {
+	if d.CanonicalID != "" {
+		return d.CanonicalID
+	}
+	return d.Name
+}
+
+// IsDirect 是否为直接依赖, 未分类的依赖按直接依赖处理
+func (d *Dependency) IsDirect() bool {
+	return d.Relationship != RelationshipTransitive
+}
+
+// IsLowConfidence 是否为低置信度的检测结果, 未评分的依赖不算低置信度
+func (d *Dependency) IsLowConfidence() bool {
+	return d.Confidence > 0 && d.Confidence < LowConfidence
+}
+
+// EffectiveScope 返回依赖范围, 未标注时按运行时依赖处理
+func (d *Dependency) EffectiveScope() string {
+	if scope := NormalizeScope(d.Scope); scope != "" {
+		return scope
+	}
+	return ScopeRuntime
+}
+
+// Location 返回依赖的来源位置, 没有来源信息时使用配置文件路径
+func (d *Dependency) Location() string {
+	if d.Provenance != nil {
+		return d.Provenance.String()
+	}
+	return d.ConfigFile
 }
 
 // VersionConstrain 表示版本约束
@@ -70,6 +270,8 @@ type DependencyResult struct {
 	// 项目信息
 	ProjectName    string    `json:"projectName"`    // 项目名称
 	ProjectPath    string    `json:"projectPath"`    // 项目路径
+	Revision       string    `json:"revision,omitempty"` // 扫描的Git版本(标签、分支或提交), 扫描工作区时为空
+	Commit         string    `json:"commit,omitempty"`   // 扫描的Git提交哈希
 	ScanTime      time.Time `json:"scanTime"`       // 扫描时间
 	ScanDuration  float64   `json:"scanDuration"`   // 扫描耗时(秒)
 	
@@ -78,6 +280,10 @@ type DependencyResult struct {
 	DirectDeps    int `json:"directDeps"`    // 直接依赖数
 	IndirectDeps  int `json:"indirectDeps"`  // 间接依赖数
 	VulnerableDeps int `json:"vulnerableDeps"` // 存在漏洞的依赖数
+	UniqueDeps    int `json:"uniqueDeps"`    // 按规范ID去重后的依赖数
+	LowConfidenceDeps int `json:"lowConfidenceDeps"` // 低置信度的依赖数
+	FilteredDeps  int `json:"filteredDeps"`  // 低于最低置信度被过滤的依赖数
+	ScopeFilteredDeps int `json:"scopeFilteredDeps"` // 按依赖范围(如测试依赖)被过滤的依赖数
 	
 	// 依赖列表
 	Dependencies []Dependency `json:"dependencies"` // 依赖列表
@@ -87,6 +293,8 @@ type DependencyResult struct {
 	
 	// 错误信息
 	Errors []string `json:"errors"` // 扫描过程中的错误
+
+	keys map[string]bool // 已计数的规范ID
 }
 
 // NewDependency 创建新的依赖项
@@ -115,7 +323,14 @@ func NewDependencyResult(projectName, projectPath string) *DependencyResult {
 func (r *DependencyResult) AddDependency(dep Dependency) {
 	r.Dependencies = append(r.Dependencies, dep)
 	r.TotalDeps++
-	if len(dep.Dependencies) == 0 {
+	if r.keys == nil {
+		r.keys = make(map[string]bool)
+	}
+	if !r.keys[dep.Key()] {
+		r.keys[dep.Key()] = true
+		r.UniqueDeps++
+	}
+	if dep.IsDirect() {
 		r.DirectDeps++
 	} else {
 		r.IndirectDeps++
@@ -123,6 +338,9 @@ func (r *DependencyResult) AddDependency(dep Dependency) {
 	if len(dep.Vulnerabilities) > 0 {
 		r.VulnerableDeps++
 	}
+	if dep.IsLowConfidence() {
+		r.LowConfidenceDeps++
+	}
 }
 
 // AddError 添加错误信息
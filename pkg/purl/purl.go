@@ -0,0 +1,243 @@
+package purl
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// 常用的purl类型
+const (
+	TypeGeneric   = "generic"   // 通用类型(源码包、二进制中识别出的库等)
+	TypeGitHub    = "github"    // GitHub仓库
+	TypeConan     = "conan"     // Conan包
+	TypeDebian    = "deb"       // Debian/Ubuntu包
+	TypeRPM       = "rpm"       // RPM包
+	TypeAlpm      = "alpm"      // Arch Linux包
+	TypeApk       = "apk"       // Alpine包
+	TypeNPM       = "npm"       // npm包
+	TypeMaven     = "maven"     // Maven构件
+	TypeCargo     = "cargo"     // Rust crate
+	TypeNuGet     = "nuget"     // NuGet包
+	TypeComposer  = "composer"  // PHP Composer包
+	TypeCocoapods = "cocoapods" // CocoaPods包
+	TypeSwift     = "swift"     // Swift包
+	TypePyPI      = "pypi"      // Python包
+)
+
+// PackageURL 表示一个Package URL(https://github.com/package-url/purl-spec)
+type PackageURL struct {
+	Type       string            // 包类型(生态)
+	Namespace  string            // 命名空间(如 Maven groupId、GitHub owner)
+	Name       string            // 包名
+	Version    string            // 版本
+	Qualifiers map[string]string // 限定符(如 arch、download_url、checksum)
+	Subpath    string            // 包内子路径
+}
+
+// New 创建Package URL
+func New(typ, namespace, name, version string) *PackageURL {
+	return &PackageURL{
+		Type:      typ,
+		Namespace: namespace,
+		Name:      name,
+		Version:   version,
+	}
+}
+
+// WithQualifier 设置限定符, 空值会被忽略
+func (p *PackageURL) WithQualifier(key, value string) *PackageURL {
+	if value == "" {
+		return p
+	}
+	if p.Qualifiers == nil {
+		p.Qualifiers = make(map[string]string)
+	}
+	p.Qualifiers[strings.ToLower(key)] = value
+	return p
+}
+
+// String 返回规范形式的purl字符串
+func (p *PackageURL) String() string {
+	if p == nil || p.Type == "" || p.Name == "" {
+		return ""
+	}
+	typ := strings.ToLower(p.Type)
+	namespace, name := normalizeNames(typ, p.Namespace, p.Name)
+
+	var b strings.Builder
+	b.WriteString("pkg:")
+	b.WriteString(typ)
+	b.WriteString("/")
+	for _, segment := range strings.Split(strings.Trim(namespace, "/"), "/") {
+		if segment != "" {
+			b.WriteString(escape(segment, ""))
+			b.WriteString("/")
+		}
+	}
+	b.WriteString(escape(name, ""))
+	if p.Version != "" {
+		b.WriteString("@")
+		b.WriteString(escape(p.Version, ":"))
+	}
+
+	keys := make([]string, 0, len(p.Qualifiers))
+	for key, value := range p.Qualifiers {
+		if value != "" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for i, key := range keys {
+		if i == 0 {
+			b.WriteString("?")
+		} else {
+			b.WriteString("&")
+		}
+		b.WriteString(strings.ToLower(key))
+		b.WriteString("=")
+		b.WriteString(escape(p.Qualifiers[key], ":/+@"))
+	}
+
+	if subpath := cleanSubpath(p.Subpath); subpath != "" {
+		b.WriteString("#")
+		segments := strings.Split(subpath, "/")
+		for i, segment := range segments {
+			segments[i] = escape(segment, "")
+		}
+		b.WriteString(strings.Join(segments, "/"))
+	}
+	return b.String()
+}
+
+// Parse 解析purl字符串
+func Parse(s string) (*PackageURL, error) {
+	rest, ok := strings.CutPrefix(strings.TrimSpace(s), "pkg:")
+	if !ok {
+		return nil, fmt.Errorf("invalid purl %q: missing pkg: scheme", s)
+	}
+	p := &PackageURL{}
+
+	if i := strings.LastIndex(rest, "#"); i >= 0 {
+		subpath, err := unescapeSegments(rest[i+1:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid purl %q: %v", s, err)
+		}
+		p.Subpath = cleanSubpath(subpath)
+		rest = rest[:i]
+	}
+
+	if i := strings.LastIndex(rest, "?"); i >= 0 {
+		for _, pair := range strings.Split(rest[i+1:], "&") {
+			key, value, ok := strings.Cut(pair, "=")
+			if !ok || key == "" {
+				continue
+			}
+			value, err := url.PathUnescape(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid purl %q: qualifier %s: %v", s, key, err)
+			}
+			p.WithQualifier(key, value)
+		}
+		rest = rest[:i]
+	}
+
+	rest = strings.Trim(rest, "/")
+	typ, rest, ok := strings.Cut(rest, "/")
+	if !ok || typ == "" {
+		return nil, fmt.Errorf("invalid purl %q: missing type", s)
+	}
+	p.Type = strings.ToLower(typ)
+
+	if i := strings.LastIndex(rest, "@"); i >= 0 {
+		version, err := url.PathUnescape(rest[i+1:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid purl %q: version: %v", s, err)
+		}
+		p.Version = version
+		rest = rest[:i]
+	}
+
+	rest = strings.Trim(rest, "/")
+	namespace, name := "", rest
+	if i := strings.LastIndex(rest, "/"); i >= 0 {
+		namespace, name = rest[:i], rest[i+1:]
+	}
+	var err error
+	if p.Name, err = url.PathUnescape(name); err != nil {
+		return nil, fmt.Errorf("invalid purl %q: name: %v", s, err)
+	}
+	if p.Name == "" {
+		return nil, fmt.Errorf("invalid purl %q: missing name", s)
+	}
+	if p.Namespace, err = unescapeSegments(namespace); err != nil {
+		return nil, fmt.Errorf("invalid purl %q: namespace: %v", s, err)
+	}
+	p.Namespace, p.Name = normalizeNames(p.Type, p.Namespace, p.Name)
+	return p, nil
+}
+
+// normalizeNames 按各类型的规则规范化命名空间和名称
+func normalizeNames(typ, namespace, name string) (string, string) {
+	switch typ {
+	case TypeGitHub, "bitbucket", TypeComposer, TypeDebian, TypeAlpm, TypeApk:
+		return strings.ToLower(namespace), strings.ToLower(name)
+	case TypePyPI:
+		return namespace, strings.ReplaceAll(strings.ToLower(name), "_", "-")
+	}
+	return namespace, name
+}
+
+// unescapeSegments 逐段解码以"/"分隔的路径
+func unescapeSegments(s string) (string, error) {
+	segments := strings.Split(s, "/")
+	for i, segment := range segments {
+		decoded, err := url.PathUnescape(segment)
+		if err != nil {
+			return "", err
+		}
+		segments[i] = decoded
+	}
+	return strings.Join(segments, "/"), nil
+}
+
+// cleanSubpath 去掉子路径中的空段以及"."和".."
+func cleanSubpath(subpath string) string {
+	var segments []string
+	for _, segment := range strings.Split(subpath, "/") {
+		if segment != "" && segment != "." && segment != ".." {
+			segments = append(segments, segment)
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// escape 百分号编码, 保留非保留字符以及safe中列出的字符
+func escape(s, safe string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' ||
+			c == '-' || c == '.' || c == '_' || c == '~' || strings.IndexByte(safe, c) >= 0 {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+/*
+使用示例:
+
+p := purl.New(purl.TypeConan, "", "openssl", "3.0.8").
+	WithQualifier("user", "conan").
+	WithQualifier("channel", "stable")
+fmt.Println(p.String()) // pkg:conan/openssl@3.0.8?channel=stable&user=conan
+
+parsed, err := purl.Parse("pkg:npm/%40angular/core@16.0.0")
+if err == nil {
+	fmt.Println(parsed.Namespace, parsed.Name) // @angular core
+}
+*/
@@ -0,0 +1,69 @@
+package purl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPackageURL_String(t *testing.T) {
+	tests := []struct {
+		purl *PackageURL
+		want string
+	}{
+		{New(TypeConan, "", "openssl", "3.0.8").WithQualifier("user", "conan").WithQualifier("channel", "stable"),
+			"pkg:conan/openssl@3.0.8?channel=stable&user=conan"},
+		{New(TypeGitHub, "Madler", "ZLib", "04f42ceca40f73e2978b50e93806c2a18c1281fc"),
+			"pkg:github/madler/zlib@04f42ceca40f73e2978b50e93806c2a18c1281fc"},
+		{New(TypeNPM, "@angular", "core", "16.0.0"), "pkg:npm/%40angular/core@16.0.0"},
+		{New(TypeDebian, "debian", "libssl-dev", "1:3.0.11-1").WithQualifier("arch", "amd64"),
+			"pkg:deb/debian/libssl-dev@1:3.0.11-1?arch=amd64"},
+		{New(TypeGeneric, "", "zlib", "1.3").
+			WithQualifier("download_url", "https://zlib.net/zlib-1.3.tar.gz").
+			WithQualifier("checksum", "sha256:ff0ba4c2"),
+			"pkg:generic/zlib@1.3?checksum=sha256:ff0ba4c2&download_url=https://zlib.net/zlib-1.3.tar.gz"},
+		{&PackageURL{Type: TypeGeneric, Name: "lib foo", Subpath: "./src/../inc"},
+			"pkg:generic/lib%20foo#src/inc"},
+		{New(TypeGeneric, "", "", "1.0"), ""},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, tt.purl.String())
+	}
+}
+
+func TestParse_RoundTrip(t *testing.T) {
+	inputs := []string{
+		"pkg:conan/openssl@3.0.8?channel=stable&user=conan",
+		"pkg:maven/org.apache.commons/commons-lang3@3.12.0",
+		"pkg:npm/%40angular/core@16.0.0",
+		"pkg:cargo/serde@1.0.188",
+		"pkg:rpm/fedora/curl@7.50.3-1.fc25?arch=i386&distro=fedora-25",
+		"pkg:generic/zlib@1.3?checksum=sha256:ff0ba4c2&download_url=https://zlib.net/zlib-1.3.tar.gz",
+		"pkg:github/madler/zlib@v1.3#contrib/minizip",
+	}
+	for _, input := range inputs {
+		p, err := Parse(input)
+		require.NoError(t, err, input)
+		assert.Equal(t, input, p.String())
+	}
+
+	p, err := Parse("pkg:npm/%40angular/core@16.0.0")
+	require.NoError(t, err)
+	assert.Equal(t, "@angular", p.Namespace)
+	assert.Equal(t, "core", p.Name)
+	assert.Equal(t, "16.0.0", p.Version)
+
+	p, err = Parse("pkg:GitHub/Madler/ZLib")
+	require.NoError(t, err)
+	assert.Equal(t, "github", p.Type)
+	assert.Equal(t, "madler", p.Namespace)
+	assert.Equal(t, "zlib", p.Name)
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, input := range []string{"", "zlib", "pkg:", "pkg:generic", "pkg:generic/@1.0", "pkg:generic/a%zz"} {
+		_, err := Parse(input)
+		assert.Error(t, err, input)
+	}
+}